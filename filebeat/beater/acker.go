@@ -14,6 +14,13 @@ type successLogger interface {
 	Published(states []file.State)
 }
 
+// privateACKer is implemented by the private data of events from prospectors
+// which don't report their state to the registrar. ACK is called once the
+// event has been acknowledged by the publisher pipeline.
+type privateACKer interface {
+	ACK()
+}
+
 func newEventACKer(out successLogger) *eventACKer {
 	return &eventACKer{out: out}
 }
@@ -25,12 +32,12 @@ func (a *eventACKer) ackEvents(data []interface{}) {
 			continue
 		}
 
-		st, ok := datum.(file.State)
-		if !ok {
-			continue
+		switch st := datum.(type) {
+		case file.State:
+			states = append(states, st)
+		case privateACKer:
+			st.ACK()
 		}
-
-		states = append(states, st)
 	}
 
 	if len(states) > 0 {
@@ -0,0 +1,62 @@
+package beater
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/elastic/beats/filebeat/harvester"
+	"github.com/elastic/beats/libbeat/common"
+	"github.com/elastic/beats/libbeat/logp"
+)
+
+// checkBatchProspectors checks that all enabled prospectors complete once
+// their files are read, which is only the case for log prospectors.
+func checkBatchProspectors(prospectors []*common.Config) error {
+	for _, prospector := range prospectors {
+		if !prospector.Enabled() {
+			continue
+		}
+
+		config := struct {
+			Type string `config:"type"`
+		}{Type: "log"}
+		if err := prospector.Unpack(&config); err != nil {
+			return err
+		}
+		if config.Type != "log" {
+			return fmt.Errorf("prospector type %v cannot be used with -batch, only log prospectors are supported", config.Type)
+		}
+	}
+	return nil
+}
+
+// reportBatch logs the results of all files read in batch mode. It returns an
+// error if reading any of the files failed, or if not all events have been
+// acknowledged by the output.
+func reportBatch(report *harvester.Report, pendingEvents int64) error {
+	files := report.Files()
+
+	var lines, bytes int64
+	failed := 0
+	for _, f := range files {
+		lines += f.Lines
+		bytes += f.Bytes
+
+		if len(f.Errors) > 0 {
+			failed++
+			logp.Err("Batch file %s: lines=%d bytes=%d errors=%d: %s",
+				f.Source, f.Lines, f.Bytes, len(f.Errors), strings.Join(f.Errors, "; "))
+			continue
+		}
+		logp.Info("Batch file %s: lines=%d bytes=%d errors=0", f.Source, f.Lines, f.Bytes)
+	}
+	logp.Info("Batch completed: files=%d lines=%d bytes=%d failed=%d", len(files), lines, bytes, failed)
+
+	if pendingEvents > 0 {
+		return fmt.Errorf("batch interrupted, %d events not acknowledged by the output", pendingEvents)
+	}
+	if failed > 0 {
+		return fmt.Errorf("batch failed for %d of %d files", failed, len(files))
+	}
+	return nil
+}
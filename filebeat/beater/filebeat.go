@@ -17,6 +17,8 @@ import (
 	cfg "github.com/elastic/beats/filebeat/config"
 	"github.com/elastic/beats/filebeat/crawler"
 	"github.com/elastic/beats/filebeat/fileset"
+	"github.com/elastic/beats/filebeat/fileset/ingest"
+	"github.com/elastic/beats/filebeat/harvester"
 	"github.com/elastic/beats/filebeat/registrar"
 
 	// Add filebeat level processors
@@ -28,8 +30,12 @@ const pipelinesWarning = "Filebeat is unable to load the Ingest Node pipelines f
 	" already loaded the Ingest Node pipelines or are using Logstash pipelines, you" +
 	" can ignore this warning."
 
+const beatPipelinesInfo = "The Elasticsearch output is not configured/enabled. The Ingest Node" +
+	" pipelines of the configured modules are executed by Filebeat."
+
 var (
-	once = flag.Bool("once", false, "Run filebeat only once until all harvesters reach EOF")
+	once  = flag.Bool("once", false, "Run filebeat only once until all harvesters reach EOF")
+	batch = flag.Bool("batch", false, "Read all files once, wait until all events are acknowledged, report the results per file and exit")
 )
 
 // Filebeat is a beater object. Contains all objects needed to run the beat
@@ -59,6 +65,10 @@ func New(b *beat.Beat, rawConfig *common.Config) (beat.Beater, error) {
 		logp.Info("Enabled modules/filesets: %s", moduleRegistry.InfoString())
 	}
 
+	if config.Ingest.Enabled && b.Config != nil && b.Config.Output.Name() != "elasticsearch" {
+		moduleRegistry.EnableBeatPipelines(config.Ingest)
+	}
+
 	moduleProspectors, err := moduleRegistry.GetProspectorConfigs()
 	if err != nil {
 		return nil, err
@@ -92,6 +102,15 @@ func New(b *beat.Beat, rawConfig *common.Config) (beat.Beater, error) {
 		return nil, errors.New("prospector configs and -once cannot be used together")
 	}
 
+	if *batch {
+		if config.ConfigProspector.Enabled() || config.ConfigModules.Enabled() {
+			return nil, errors.New("prospector configs and -batch cannot be used together")
+		}
+		if err := checkBatchProspectors(config.Prospectors); err != nil {
+			return nil, err
+		}
+	}
+
 	fb := &Filebeat{
 		done:           make(chan struct{}),
 		config:         &config,
@@ -111,7 +130,7 @@ func New(b *beat.Beat, rawConfig *common.Config) (beat.Beater, error) {
 // setup.
 func (fb *Filebeat) loadModulesPipelines(b *beat.Beat) error {
 	if b.Config.Output.Name() != "elasticsearch" {
-		logp.Warn(pipelinesWarning)
+		logPipelinesNotLoaded(fb.config.Ingest)
 		return nil
 	}
 
@@ -125,6 +144,16 @@ func (fb *Filebeat) loadModulesPipelines(b *beat.Beat) error {
 	return nil
 }
 
+// logPipelinesNotLoaded informs that the Ingest Node pipelines are not loaded
+// into Elasticsearch.
+func logPipelinesNotLoaded(ingestConfig ingest.Config) {
+	if ingestConfig.Enabled {
+		logp.Info(beatPipelinesInfo)
+	} else {
+		logp.Warn(pipelinesWarning)
+	}
+}
+
 func (fb *Filebeat) loadModulesML(b *beat.Beat) error {
 	logp.Debug("machine-learning", "Setting up ML jobs for modules")
 
@@ -144,9 +173,9 @@ func (fb *Filebeat) loadModulesML(b *beat.Beat) error {
 }
 
 // Run allows the beater to be run as a beat.
-func (fb *Filebeat) Run(b *beat.Beat) error {
-	var err error
+func (fb *Filebeat) Run(b *beat.Beat) (err error) {
 	config := fb.config
+	runOnce := *once || *batch
 
 	if !fb.moduleRegistry.Empty() {
 		err = fb.loadModulesPipelines(b)
@@ -167,7 +196,7 @@ func (fb *Filebeat) Run(b *beat.Beat) error {
 	finishedLogger := newFinishedLogger(wgEvents)
 
 	// Setup registrar to persist state
-	registrar, err := registrar.New(config.RegistryFile, config.RegistryFlush, finishedLogger)
+	registrar, err := registrar.New(config.RegistryFile, config.RegistryStorage, config.RegistryFlush, finishedLogger)
 	if err != nil {
 		logp.Err("Could not init registrar: %v", err)
 		return err
@@ -184,13 +213,20 @@ func (fb *Filebeat) Run(b *beat.Beat) error {
 		return err
 	}
 
+	// In batch mode, the results of all files are collected in a report
+	var report *harvester.Report
+	if *batch {
+		report = harvester.NewReport()
+	}
+
 	outDone := make(chan struct{}) // outDone closes down all active pipeline connections
 	crawler, err := crawler.New(
 		channel.NewOutletFactory(outDone, b.Publisher, wgEvents).Create,
 		config.Prospectors,
 		b.Info.Version,
 		fb.done,
-		*once)
+		*once,
+		report)
 	if err != nil {
 		logp.Err("Could not init crawler: %v", err)
 		return err
@@ -206,6 +242,16 @@ func (fb *Filebeat) Run(b *beat.Beat) error {
 		return fmt.Errorf("Could not start registrar: %v", err)
 	}
 
+	// The batch report is logged once the registrar has written the last
+	// state, so a failed batch can be run again from the registry.
+	if report != nil {
+		defer func() {
+			if err == nil {
+				err = reportBatch(report, wgEvents.count.Get())
+			}
+		}()
+	}
+
 	// Stopping registrar will write last state
 	defer registrar.Stop()
 
@@ -225,17 +271,17 @@ func (fb *Filebeat) Run(b *beat.Beat) error {
 	if b.Config.Output.Name() == "elasticsearch" {
 		pipelineLoaderFactory = newPipelineLoaderFactory(b.Config.Output.Config())
 	} else {
-		logp.Warn(pipelinesWarning)
+		logPipelinesNotLoaded(config.Ingest)
 	}
 
-	err = crawler.Start(registrar, config.ConfigProspector, config.ConfigModules, pipelineLoaderFactory)
+	err = crawler.Start(registrar, config.ConfigProspector, config.ConfigModules, pipelineLoaderFactory, config.Ingest)
 	if err != nil {
 		crawler.Stop()
 		return err
 	}
 
 	// If run once, add crawler completion check as alternative to done signal
-	if *once {
+	if runOnce {
 		runOnce := func() {
 			logp.Info("Running filebeat once. Waiting for completion ...")
 			crawler.WaitForCompletion()
@@ -256,7 +302,7 @@ func (fb *Filebeat) Run(b *beat.Beat) error {
 
 	timeout := fb.config.ShutdownTimeout
 	// Checks if on shutdown it should wait for all events to be published
-	waitPublished := fb.config.ShutdownTimeout > 0 || *once
+	waitPublished := fb.config.ShutdownTimeout > 0 || runOnce
 	if waitPublished {
 		// Wait for registrar to finish writing registry
 		waitEvents.Add(withLog(wgEvents.Wait,
@@ -1,11 +1,16 @@
 package channel
 
 import (
+	"github.com/elastic/beats/filebeat/fileset/ingest"
+	"github.com/elastic/beats/filebeat/harvester"
 	"github.com/elastic/beats/libbeat/beat"
 	"github.com/elastic/beats/libbeat/common"
+	"github.com/elastic/beats/libbeat/monitoring"
 	"github.com/elastic/beats/libbeat/processors"
 )
 
+var prospectorThrottled = monitoring.NewInt(harvester.Metrics, "throttled.prospector_ms")
+
 type OutletFactory struct {
 	done     <-chan struct{}
 	pipeline beat.Pipeline
@@ -38,9 +43,14 @@ type prospectorOutletConfig struct {
 	Module  string `config:"_module_name"`  // hidden setting
 	Fileset string `config:"_fileset_name"` // hidden setting
 
+	// Ingest pipeline executed in filebeat, set by filebeat modules
+	IngestPipeline *ingest.PipelineConfig `config:"_ingest_pipeline"` // hidden setting
+
 	// Output meta data settings
 	Pipeline string `config:"pipeline"` // ES Ingest pipeline name
 
+	// Throughput limit shared by all harvesters of the prospector
+	RateLimit harvester.RateLimitConfig `config:"rate_limit"`
 }
 
 // NewOutletFactory creates a new outlet factory for
@@ -78,6 +88,14 @@ func (f *OutletFactory) Create(cfg *common.Config) (Outleter, error) {
 		return nil, err
 	}
 
+	if config.IngestPipeline != nil {
+		pipeline, err := ingest.Load(config.IngestPipeline.Path, config.IngestPipeline.Config)
+		if err != nil {
+			return nil, err
+		}
+		processors.List = append(processors.List, pipeline)
+	}
+
 	setMeta := func(to common.MapStr, key, value string) {
 		if value != "" {
 			to[key] = value
@@ -113,7 +131,10 @@ func (f *OutletFactory) Create(cfg *common.Config) (Outleter, error) {
 		return nil, err
 	}
 
-	outlet := newOutlet(client, f.wgEvents)
+	var outlet Outleter = newOutlet(client, f.wgEvents)
+	if limiter := harvester.NewRateLimiter(config.RateLimit, prospectorThrottled); limiter != nil {
+		outlet = RateLimit(outlet, limiter)
+	}
 	if f.done != nil {
 		return CloseOnSignal(outlet, f.done), nil
 	}
@@ -0,0 +1,54 @@
+package channel
+
+import (
+	"github.com/elastic/beats/filebeat/harvester"
+	"github.com/elastic/beats/filebeat/util"
+	"github.com/elastic/beats/libbeat/common/atomic"
+)
+
+type rateLimitOutlet struct {
+	out     Outleter
+	limiter *harvester.RateLimiter
+	isOpen  atomic.Bool
+	done    chan struct{}
+}
+
+// RateLimit creates an outlet delaying the events to not exceed the limits of
+// the limiter. Data with state updates only is never delayed. Closing the
+// outlet unblocks the waiting events, which are then reported as not sent.
+func RateLimit(out Outleter, limiter *harvester.RateLimiter) Outleter {
+	return &rateLimitOutlet{
+		out:     out,
+		limiter: limiter,
+		isOpen:  atomic.MakeBool(true),
+		done:    make(chan struct{}),
+	}
+}
+
+func (o *rateLimitOutlet) Close() error {
+	isOpen := o.isOpen.Swap(false)
+	if isOpen {
+		close(o.done)
+	}
+	return o.out.Close()
+}
+
+func (o *rateLimitOutlet) OnEvent(d *util.Data) bool {
+	if !o.isOpen.Load() {
+		return false
+	}
+
+	if d.HasEvent() && !o.limiter.Wait(1, eventBytes(d), o.done) {
+		return false
+	}
+	return o.out.OnEvent(d)
+}
+
+// eventBytes returns the size of the message of the event, which is the line
+// read for most prospectors.
+func eventBytes(d *util.Data) int {
+	if msg, ok := d.Event.Fields["message"].(string); ok {
+		return len(msg)
+	}
+	return 0
+}
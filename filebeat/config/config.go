@@ -7,6 +7,8 @@ import (
 	"path/filepath"
 	"time"
 
+	"github.com/elastic/beats/filebeat/fileset/ingest"
+	"github.com/elastic/beats/filebeat/registrar"
 	"github.com/elastic/beats/libbeat/cfgfile"
 	"github.com/elastic/beats/libbeat/common"
 	"github.com/elastic/beats/libbeat/logp"
@@ -19,20 +21,24 @@ const (
 )
 
 type Config struct {
-	Prospectors      []*common.Config `config:"prospectors"`
-	RegistryFile     string           `config:"registry_file"`
-	RegistryFlush    time.Duration    `config:"registry_flush"`
-	ConfigDir        string           `config:"config_dir"`
-	ShutdownTimeout  time.Duration    `config:"shutdown_timeout"`
-	Modules          []*common.Config `config:"modules"`
-	ConfigProspector *common.Config   `config:"config.prospectors"`
-	ConfigModules    *common.Config   `config:"config.modules"`
+	Prospectors      []*common.Config        `config:"prospectors"`
+	RegistryFile     string                  `config:"registry_file"`
+	RegistryFlush    time.Duration           `config:"registry_flush"`
+	RegistryStorage  registrar.StorageConfig `config:"registry_storage"`
+	ConfigDir        string                  `config:"config_dir"`
+	ShutdownTimeout  time.Duration           `config:"shutdown_timeout"`
+	Modules          []*common.Config        `config:"modules"`
+	ConfigProspector *common.Config          `config:"config.prospectors"`
+	ConfigModules    *common.Config          `config:"config.modules"`
+	Ingest           ingest.Config           `config:"ingest"`
 }
 
 var (
 	DefaultConfig = Config{
 		RegistryFile:    "registry",
+		RegistryStorage: registrar.DefaultStorageConfig,
 		ShutdownTimeout: 0,
+		Ingest:          ingest.DefaultConfig,
 	}
 )
 
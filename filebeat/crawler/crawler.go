@@ -6,6 +6,8 @@ import (
 
 	"github.com/elastic/beats/filebeat/channel"
 	"github.com/elastic/beats/filebeat/fileset"
+	"github.com/elastic/beats/filebeat/fileset/ingest"
+	"github.com/elastic/beats/filebeat/harvester"
 	"github.com/elastic/beats/filebeat/input/file"
 	"github.com/elastic/beats/filebeat/prospector"
 	"github.com/elastic/beats/filebeat/registrar"
@@ -24,16 +26,21 @@ type Crawler struct {
 	modulesReloader     *cfgfile.Reloader
 	prospectorsReloader *cfgfile.Reloader
 	once                bool
+	report              *harvester.Report
 	beatVersion         string
 	beatDone            chan struct{}
 }
 
-func New(out channel.Factory, prospectorConfigs []*common.Config, beatVersion string, beatDone chan struct{}, once bool) (*Crawler, error) {
+// New creates a new crawler. If a report is given, the prospectors run in
+// batch mode: they run once, close the files at EOF and collect their results
+// in the report.
+func New(out channel.Factory, prospectorConfigs []*common.Config, beatVersion string, beatDone chan struct{}, once bool, report *harvester.Report) (*Crawler, error) {
 	return &Crawler{
 		out:               out,
 		prospectors:       map[uint64]*prospector.Prospector{},
 		prospectorConfigs: prospectorConfigs,
-		once:              once,
+		once:              once || report != nil,
+		report:            report,
 		beatVersion:       beatVersion,
 		beatDone:          beatDone,
 	}, nil
@@ -41,7 +48,8 @@ func New(out channel.Factory, prospectorConfigs []*common.Config, beatVersion st
 
 // Start starts the crawler with all prospectors
 func (c *Crawler) Start(r *registrar.Registrar, configProspectors *common.Config,
-	configModules *common.Config, pipelineLoaderFactory fileset.PipelineLoaderFactory) error {
+	configModules *common.Config, pipelineLoaderFactory fileset.PipelineLoaderFactory,
+	ingestConfig ingest.Config) error {
 
 	logp.Info("Loading Prospectors: %v", len(c.prospectorConfigs))
 
@@ -67,7 +75,7 @@ func (c *Crawler) Start(r *registrar.Registrar, configProspectors *common.Config
 
 	if configModules.Enabled() {
 		c.modulesReloader = cfgfile.NewReloader(configModules)
-		modulesFactory := fileset.NewFactory(c.out, r, c.beatVersion, pipelineLoaderFactory, c.beatDone)
+		modulesFactory := fileset.NewFactory(c.out, r, c.beatVersion, pipelineLoaderFactory, ingestConfig, c.beatDone)
 		if err := c.modulesReloader.Check(modulesFactory); err != nil {
 			return err
 		}
@@ -86,7 +94,15 @@ func (c *Crawler) startProspector(config *common.Config, states []file.State) er
 	if !config.Enabled() {
 		return nil
 	}
-	p, err := prospector.New(config, c.out, c.beatDone, states)
+
+	// In batch mode, harvesters must finish once all files are read
+	if c.report != nil {
+		if err := config.SetBool("close_eof", -1, true); err != nil {
+			return err
+		}
+	}
+
+	p, err := prospector.New(config, c.out, c.beatDone, states, c.report)
 	if err != nil {
 		return fmt.Errorf("Error in initing prospector: %s", err)
 	}
@@ -4,6 +4,7 @@ import (
 	"github.com/mitchellh/hashstructure"
 
 	"github.com/elastic/beats/filebeat/channel"
+	"github.com/elastic/beats/filebeat/fileset/ingest"
 	"github.com/elastic/beats/filebeat/prospector"
 	"github.com/elastic/beats/filebeat/registrar"
 	"github.com/elastic/beats/libbeat/cfgfile"
@@ -18,6 +19,7 @@ type Factory struct {
 	registrar             *registrar.Registrar
 	beatVersion           string
 	pipelineLoaderFactory PipelineLoaderFactory
+	ingestConfig          ingest.Config
 	beatDone              chan struct{}
 }
 
@@ -29,15 +31,17 @@ type prospectorsRunner struct {
 	pipelineLoaderFactory PipelineLoaderFactory
 }
 
-// NewFactory instantiates a new Factory
+// NewFactory instantiates a new Factory. If no pipelineLoaderFactory is given,
+// the ingest pipelines are executed in Filebeat as configured by ingestConfig.
 func NewFactory(outlet channel.Factory, registrar *registrar.Registrar, beatVersion string,
-	pipelineLoaderFactory PipelineLoaderFactory, beatDone chan struct{}) *Factory {
+	pipelineLoaderFactory PipelineLoaderFactory, ingestConfig ingest.Config, beatDone chan struct{}) *Factory {
 	return &Factory{
 		outlet:                outlet,
 		registrar:             registrar,
 		beatVersion:           beatVersion,
 		beatDone:              beatDone,
 		pipelineLoaderFactory: pipelineLoaderFactory,
+		ingestConfig:          ingestConfig,
 	}
 }
 
@@ -49,6 +53,10 @@ func (f *Factory) Create(c *common.Config) (cfgfile.Runner, error) {
 		return nil, err
 	}
 
+	if f.pipelineLoaderFactory == nil && f.ingestConfig.Enabled {
+		m.EnableBeatPipelines(f.ingestConfig)
+	}
+
 	pConfigs, err := m.GetProspectorConfigs()
 	if err != nil {
 		return nil, err
@@ -64,7 +72,7 @@ func (f *Factory) Create(c *common.Config) (cfgfile.Runner, error) {
 
 	prospectors := make([]*prospector.Prospector, len(pConfigs))
 	for i, pConfig := range pConfigs {
-		prospectors[i], err = prospector.New(pConfig, f.outlet, f.beatDone, f.registrar.GetStates())
+		prospectors[i], err = prospector.New(pConfig, f.outlet, f.beatDone, f.registrar.GetStates(), nil)
 		if err != nil {
 			logp.Err("Error creating prospector: %s", err)
 			return nil, err
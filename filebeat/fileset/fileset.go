@@ -16,7 +16,9 @@ import (
 	"strings"
 	"text/template"
 
+	"github.com/elastic/beats/filebeat/fileset/ingest"
 	"github.com/elastic/beats/libbeat/common"
+	"github.com/elastic/beats/libbeat/logp"
 	mlimporter "github.com/elastic/beats/libbeat/ml-importer"
 )
 
@@ -29,6 +31,9 @@ type Fileset struct {
 	manifest   *manifest
 	vars       map[string]interface{}
 	pipelineID string
+
+	// beatPipeline is set if the ingest pipeline is executed in Filebeat
+	beatPipeline *ingest.Config
 }
 
 // New allocates a new Fileset object with the given configuration.
@@ -246,10 +251,26 @@ func (fs *Fileset) getProspectorConfig() (*common.Config, error) {
 		}
 	}
 
-	// force our pipeline ID
-	err = cfg.SetString("pipeline", -1, fs.pipelineID)
-	if err != nil {
-		return nil, fmt.Errorf("Error setting the pipeline ID in the prospector config: %v", err)
+	var pipelineCfg *common.Config
+	if fs.beatPipeline != nil {
+		pipelineCfg, err = fs.getBeatPipelineConfig()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if pipelineCfg != nil {
+		// the pipeline is executed by Filebeat, so no pipeline ID is set
+		err = cfg.SetChild("_ingest_pipeline", -1, pipelineCfg)
+		if err != nil {
+			return nil, fmt.Errorf("Error setting the ingest pipeline in the prospector config: %v", err)
+		}
+	} else {
+		// force our pipeline ID
+		err = cfg.SetString("pipeline", -1, fs.pipelineID)
+		if err != nil {
+			return nil, fmt.Errorf("Error setting the pipeline ID in the prospector config: %v", err)
+		}
 	}
 
 	// force our the module/fileset name
@@ -267,6 +288,33 @@ func (fs *Fileset) getProspectorConfig() (*common.Config, error) {
 	return cfg, nil
 }
 
+// getBeatPipelineConfig returns the settings for executing the ingest pipeline
+// in Filebeat. If the pipeline uses processors not supported by Filebeat, a
+// warning is logged and nil is returned, so the events are sent unparsed.
+func (fs *Fileset) getBeatPipelineConfig() (*common.Config, error) {
+	path, err := applyTemplate(fs.vars, fs.manifest.IngestPipeline)
+	if err != nil {
+		return nil, fmt.Errorf("Error expanding vars on the ingest pipeline path: %v", err)
+	}
+
+	config := ingest.PipelineConfig{
+		Config: *fs.beatPipeline,
+		Path:   filepath.Join(fs.modulePath, fs.name, path),
+	}
+	if _, err := ingest.Load(config.Path, config.Config); err != nil {
+		logp.Warn("The ingest pipeline of fileset %s/%s can't be executed by Filebeat, events are sent unparsed: %v",
+			fs.mcfg.Module, fs.name, err)
+		return nil, nil
+	}
+
+	return common.NewConfigFrom(map[string]interface{}{
+		"path": config.Path,
+		"geoip": map[string]interface{}{
+			"database_file": config.GeoIP.DatabaseFile,
+		},
+	})
+}
+
 // getPipelineID returns the Ingest Node pipeline ID
 func (fs *Fileset) getPipelineID(beatVersion string) (string, error) {
 	path, err := applyTemplate(fs.vars, fs.manifest.IngestPipeline)
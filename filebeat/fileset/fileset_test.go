@@ -9,6 +9,8 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+
+	"github.com/elastic/beats/filebeat/fileset/ingest"
 )
 
 func getModuleForTesting(t *testing.T, module, fileset string) *Fileset {
@@ -157,6 +159,38 @@ func TestGetProspectorConfigNginx(t *testing.T) {
 	assert.Equal(t, "filebeat-5.2.0-nginx-access-default", pipelineID)
 }
 
+func TestGetProspectorConfigBeatPipeline(t *testing.T) {
+	fs := getModuleForTesting(t, "apache2", "access")
+	assert.NoError(t, fs.Read("5.2.0"))
+	fs.beatPipeline = &ingest.Config{}
+
+	cfg, err := fs.getProspectorConfig()
+	assert.NoError(t, err)
+
+	assert.False(t, cfg.HasField("pipeline"))
+
+	var pipelineCfg ingest.PipelineConfig
+	sub, err := cfg.Child("_ingest_pipeline", -1)
+	if assert.NoError(t, err) && assert.NoError(t, sub.Unpack(&pipelineCfg)) {
+		assert.Equal(t, filepath.Join(fs.modulePath, "access", "ingest", "default.json"), pipelineCfg.Path)
+	}
+}
+
+func TestGetProspectorConfigBeatPipelineUnsupported(t *testing.T) {
+	// the redis log pipeline uses scripts without implementation in the Beat
+	fs := getModuleForTesting(t, "redis", "log")
+	assert.NoError(t, fs.Read("5.2.0"))
+	fs.beatPipeline = &ingest.Config{}
+
+	cfg, err := fs.getProspectorConfig()
+	assert.NoError(t, err)
+
+	assert.False(t, cfg.HasField("_ingest_pipeline"))
+	pipelineID, err := cfg.String("pipeline", -1)
+	assert.NoError(t, err)
+	assert.Equal(t, "filebeat-5.2.0-redis-log-pipeline", pipelineID)
+}
+
 func TestGetProspectorConfigNginxOverrides(t *testing.T) {
 	modulesPath, err := filepath.Abs("../module")
 	assert.NoError(t, err)
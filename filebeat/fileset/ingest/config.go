@@ -0,0 +1,24 @@
+package ingest
+
+// Config contains the settings for executing ingest pipelines in the Beat.
+type Config struct {
+	// Enabled allows to execute the module pipelines in the Beat if the output
+	// is not Elasticsearch.
+	Enabled bool        `config:"enabled"`
+	GeoIP   GeoIPConfig `config:"geoip"`
+}
+
+// GeoIPConfig configures the database used by the geoip processor.
+type GeoIPConfig struct {
+	DatabaseFile string `config:"database_file"`
+}
+
+// PipelineConfig references an ingest pipeline file to be executed in the Beat.
+type PipelineConfig struct {
+	Config `config:",inline"`
+	Path   string `config:"path" validate:"required"`
+}
+
+var DefaultConfig = Config{
+	Enabled: true,
+}
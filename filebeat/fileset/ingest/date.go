@@ -0,0 +1,228 @@
+package ingest
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/elastic/beats/libbeat/common"
+)
+
+func init() {
+	registerProcessor("date", newDate)
+}
+
+// date parses a date from a field using Joda-Time formats and stores it in the
+// target field.
+type date struct {
+	field    string
+	target   string
+	formats  []dateFormat
+	location *time.Location
+}
+
+// dateFormat parses a date string into a time
+type dateFormat func(value string, loc *time.Location) (time.Time, error)
+
+func newDate(def map[string]interface{}, _ Config) (processor, error) {
+	field, err := getRequiredString(def, "field")
+	if err != nil {
+		return nil, err
+	}
+	target, err := getString(def, "target_field", timestampField)
+	if err != nil {
+		return nil, err
+	}
+	formats, err := getStringList(def, "formats")
+	if err != nil {
+		return nil, err
+	}
+	if len(formats) == 0 {
+		return nil, fmt.Errorf("[formats] required property is missing")
+	}
+	timezone, err := getString(def, "timezone", "UTC")
+	if err != nil {
+		return nil, err
+	}
+	location, err := time.LoadLocation(timezone)
+	if err != nil {
+		return nil, fmt.Errorf("unknown timezone [%s]", timezone)
+	}
+
+	d := &date{field: field, target: target, location: location}
+	for _, format := range formats {
+		parser, err := newDateFormat(format)
+		if err != nil {
+			return nil, err
+		}
+		d.formats = append(d.formats, parser)
+	}
+	return d, nil
+}
+
+func (d *date) Type() string { return "date" }
+
+func (d *date) Run(doc common.MapStr) error {
+	value, err := getStringField(doc, d.field, false)
+	if err != nil {
+		return err
+	}
+
+	for _, format := range d.formats {
+		t, err := format(*value, d.location)
+		if err != nil {
+			continue
+		}
+		// Elasticsearch dates have millisecond precision
+		_, err = doc.Put(d.target, common.Time(t.UTC().Truncate(time.Millisecond)))
+		return err
+	}
+
+	return fmt.Errorf("unable to parse date [%s]", *value)
+}
+
+func newDateFormat(format string) (dateFormat, error) {
+	switch format {
+	case "ISO8601":
+		return parseISO8601, nil
+	case "UNIX":
+		return parseUnix(time.Second), nil
+	case "UNIX_MS":
+		return parseUnix(time.Millisecond), nil
+	}
+
+	layout, hasYear, err := jodaToLayout(format)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(value string, loc *time.Location) (time.Time, error) {
+		t, err := time.ParseInLocation(layout, value, loc)
+		if err != nil {
+			return t, err
+		}
+		if !hasYear {
+			// Dates without year are assumed to be in the current year
+			t = t.AddDate(time.Now().In(loc).Year()-t.Year(), 0, 0)
+		}
+		return t, nil
+	}, nil
+}
+
+func parseISO8601(value string, loc *time.Location) (time.Time, error) {
+	layouts := []string{
+		time.RFC3339Nano,
+		"2006-01-02T15:04:05.999999999",
+		"2006-01-02T15:04:05Z0700",
+		"2006-01-02T15:04:05.999999999Z0700",
+		"2006-01-02",
+	}
+	for _, layout := range layouts {
+		if t, err := time.ParseInLocation(layout, value, loc); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("unable to parse [%s] as ISO8601", value)
+}
+
+func parseUnix(unit time.Duration) dateFormat {
+	return func(value string, _ *time.Location) (time.Time, error) {
+		if i, err := strconv.ParseInt(value, 10, 64); err == nil {
+			return time.Unix(0, i*int64(unit)), nil
+		}
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return time.Time{}, err
+		}
+		return time.Unix(0, int64(f*float64(unit))), nil
+	}
+}
+
+// jodaTokens maps Joda-Time pattern letters by number of repetitions to the
+// equivalent Go reference time layout.
+var jodaTokens = map[byte]map[int]string{
+	'y': {1: "2006", 2: "06", 4: "2006"},
+	'Y': {1: "2006", 2: "06", 4: "2006"},
+	'M': {1: "1", 2: "01", 3: "Jan", 4: "January"},
+	'd': {1: "2", 2: "02"},
+	'H': {1: "15", 2: "15"},
+	'h': {1: "3", 2: "03"},
+	'm': {1: "4", 2: "04"},
+	's': {1: "5", 2: "05"},
+	'E': {1: "Mon", 2: "Mon", 3: "Mon", 4: "Monday"},
+	'a': {1: "PM"},
+	'Z': {1: "-0700", 2: "-07:00"},
+	'z': {1: "MST", 2: "MST", 3: "MST"},
+}
+
+// jodaToLayout converts a Joda-Time pattern to a Go time layout. It also
+// reports if the pattern contains a year.
+func jodaToLayout(format string) (string, bool, error) {
+	var (
+		layout  bytes.Buffer
+		hasYear bool
+	)
+
+	for i := 0; i < len(format); {
+		c := format[i]
+
+		// quoted literal text
+		if c == '\'' {
+			end := strings.IndexByte(format[i+1:], '\'')
+			if end < 0 {
+				return "", false, fmt.Errorf("unterminated quote in date format [%s]", format)
+			}
+			layout.WriteString(format[i+1 : i+1+end])
+			i += end + 2
+			continue
+		}
+
+		if !isLetter(c) {
+			layout.WriteByte(c)
+			i++
+			continue
+		}
+
+		n := 1
+		for i+n < len(format) && format[i+n] == c {
+			n++
+		}
+		i += n
+
+		// fractions of seconds
+		if c == 'S' {
+			if layout.Len() == 0 || !strings.HasSuffix(layout.String(), ".") && !strings.HasSuffix(layout.String(), ",") {
+				return "", false, fmt.Errorf("fraction of seconds must follow a separator in date format [%s]", format)
+			}
+			layout.WriteString(strings.Repeat("0", n))
+			continue
+		}
+
+		tokens, exists := jodaTokens[c]
+		if !exists {
+			return "", false, fmt.Errorf("unsupported pattern letter [%c] in date format [%s]", c, format)
+		}
+
+		token, exists := tokens[n]
+		if !exists {
+			// use the longest form for more repetitions
+			for k, v := range tokens {
+				if k < n && len(v) > len(token) {
+					token = v
+				}
+			}
+		}
+		if c == 'y' || c == 'Y' {
+			hasYear = true
+		}
+		layout.WriteString(token)
+	}
+
+	return layout.String(), hasYear, nil
+}
+
+func isLetter(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
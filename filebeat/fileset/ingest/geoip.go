@@ -0,0 +1,145 @@
+package ingest
+
+import (
+	"fmt"
+	"net"
+	"sync"
+
+	"github.com/oschwald/maxminddb-golang"
+
+	"github.com/elastic/beats/libbeat/common"
+	"github.com/elastic/beats/libbeat/logp"
+)
+
+func init() {
+	registerProcessor("geoip", newGeoIP)
+}
+
+// geoip adds geographical information about an IP address using a MaxMind DB
+// city database. Without database the processor does nothing.
+type geoip struct {
+	field         string
+	target        string
+	ignoreMissing bool
+	reader        *maxminddb.Reader
+}
+
+// cityRecord contains the fields of a GeoLite2 City record used by the
+// processor.
+type cityRecord struct {
+	City struct {
+		Names map[string]string `maxminddb:"names"`
+	} `maxminddb:"city"`
+	Continent struct {
+		Names map[string]string `maxminddb:"names"`
+	} `maxminddb:"continent"`
+	Country struct {
+		IsoCode string `maxminddb:"iso_code"`
+	} `maxminddb:"country"`
+	Subdivisions []struct {
+		Names map[string]string `maxminddb:"names"`
+	} `maxminddb:"subdivisions"`
+	Location struct {
+		Latitude  *float64 `maxminddb:"latitude"`
+		Longitude *float64 `maxminddb:"longitude"`
+	} `maxminddb:"location"`
+}
+
+var (
+	readersMutex sync.Mutex
+	readers      = map[string]*maxminddb.Reader{}
+)
+
+// openDatabase returns the reader for the database at path. Readers are shared
+// between all pipelines using the same database.
+func openDatabase(path string) (*maxminddb.Reader, error) {
+	readersMutex.Lock()
+	defer readersMutex.Unlock()
+
+	if r, exists := readers[path]; exists {
+		return r, nil
+	}
+
+	r, err := maxminddb.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("Error loading GeoIP database %s: %v", path, err)
+	}
+	readers[path] = r
+	return r, nil
+}
+
+func newGeoIP(def map[string]interface{}, config Config) (processor, error) {
+	field, err := getRequiredString(def, "field")
+	if err != nil {
+		return nil, err
+	}
+	target, err := getString(def, "target_field", "geoip")
+	if err != nil {
+		return nil, err
+	}
+	ignoreMissing, err := getBool(def, "ignore_missing", false)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &geoip{field: field, target: target, ignoreMissing: ignoreMissing}
+
+	if config.GeoIP.DatabaseFile == "" {
+		logp.Warn("No GeoIP database configured, the geoip processor on field %s is disabled", field)
+		return p, nil
+	}
+
+	p.reader, err = openDatabase(config.GeoIP.DatabaseFile)
+	if err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+func (p *geoip) Type() string { return "geoip" }
+
+func (p *geoip) Run(doc common.MapStr) error {
+	value, err := getStringField(doc, p.field, p.ignoreMissing)
+	if err != nil || value == nil {
+		return err
+	}
+
+	if p.reader == nil {
+		return nil
+	}
+
+	ip := net.ParseIP(*value)
+	if ip == nil {
+		return fmt.Errorf("'%s' is not an IP string literal.", *value)
+	}
+
+	var record cityRecord
+	if err := p.reader.Lookup(ip, &record); err != nil {
+		return err
+	}
+
+	result := common.MapStr{}
+	if name := record.Continent.Names["en"]; name != "" {
+		result["continent_name"] = name
+	}
+	if record.Country.IsoCode != "" {
+		result["country_iso_code"] = record.Country.IsoCode
+	}
+	if len(record.Subdivisions) > 0 {
+		if name := record.Subdivisions[0].Names["en"]; name != "" {
+			result["region_name"] = name
+		}
+	}
+	if name := record.City.Names["en"]; name != "" {
+		result["city_name"] = name
+	}
+	if lat, lon := record.Location.Latitude, record.Location.Longitude; lat != nil && lon != nil {
+		result["location"] = common.MapStr{"lat": *lat, "lon": *lon}
+	}
+
+	if len(result) == 0 {
+		return nil
+	}
+	_, err = doc.Put(p.target, result)
+	return err
+}
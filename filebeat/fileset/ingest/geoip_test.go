@@ -0,0 +1,251 @@
+// +build !integration
+
+package ingest
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io/ioutil"
+	"math"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/elastic/beats/libbeat/common"
+)
+
+// Minimal MaxMind DB encoder for building test databases. See
+// http://maxmind.github.io/MaxMind-DB/ for the format.
+
+var mmdbMetadataMarker = []byte("\xab\xcd\xefMaxMind.com")
+
+// Data section field types
+const (
+	mmdbPointer = 1
+	mmdbString  = 2
+	mmdbDouble  = 3
+	mmdbUint32  = 6
+	mmdbMap     = 7
+	mmdbArray   = 11
+)
+
+func mmdbControl(typ, size int) []byte {
+	if typ > 7 {
+		return []byte{byte(size), byte(typ - 7)}
+	}
+	return []byte{byte(typ<<5 | size)}
+}
+
+func mmdbEncodeString(s string) []byte {
+	return append(mmdbControl(mmdbString, len(s)), s...)
+}
+
+func mmdbEncodeDouble(f float64) []byte {
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint64(b, math.Float64bits(f))
+	return append(mmdbControl(mmdbDouble, 8), b...)
+}
+
+func mmdbEncodeUint32(v uint32) []byte {
+	b := make([]byte, 4)
+	binary.BigEndian.PutUint32(b, v)
+	return append(mmdbControl(mmdbUint32, 4), b...)
+}
+
+func mmdbEncodePointer(p int) []byte {
+	return []byte{byte(mmdbPointer<<5 | (p>>8)&0x7), byte(p)}
+}
+
+// mmdbEncodeMap encodes a map with sorted keys. Values must already be encoded.
+func mmdbEncodeMap(m map[string][]byte) []byte {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	buf := mmdbControl(mmdbMap, len(m))
+	for _, k := range keys {
+		buf = append(buf, mmdbEncodeString(k)...)
+		buf = append(buf, m[k]...)
+	}
+	return buf
+}
+
+func mmdbEncodeArray(values ...[]byte) []byte {
+	buf := mmdbControl(mmdbArray, len(values))
+	for _, v := range values {
+		buf = append(buf, v...)
+	}
+	return buf
+}
+
+func mmdbNames(name []byte) []byte {
+	return mmdbEncodeMap(map[string][]byte{
+		"names": mmdbEncodeMap(map[string][]byte{"en": name}),
+	})
+}
+
+// buildTestDatabase creates a database containing a single network, mapped to
+// a city record.
+func buildTestDatabase(t *testing.T, network string, recordSize, ipVersion int) []byte {
+	// data section, with the city name being referenced by a pointer
+	data := mmdbEncodeString("London")
+	data = append(data, mmdbEncodeMap(map[string][]byte{
+		"city":      mmdbNames(mmdbEncodePointer(0)),
+		"continent": mmdbNames(mmdbEncodeString("Europe")),
+		"country":   mmdbEncodeMap(map[string][]byte{"iso_code": mmdbEncodeString("GB")}),
+		"location": mmdbEncodeMap(map[string][]byte{
+			"latitude":  mmdbEncodeDouble(51.5142),
+			"longitude": mmdbEncodeDouble(-0.0931),
+		}),
+		"subdivisions": mmdbEncodeArray(mmdbNames(mmdbEncodeString("England"))),
+	})...)
+	recordOffset := len(mmdbEncodeString("London"))
+
+	return buildDatabase(t, network, recordSize, ipVersion, data, recordOffset)
+}
+
+// buildDatabase creates a database mapping a single network to the record at
+// recordOffset of the data section.
+func buildDatabase(t *testing.T, network string, recordSize, ipVersion int, data []byte, recordOffset int) []byte {
+	_, ipNet, err := net.ParseCIDR(network)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ip := []byte(ipNet.IP)
+	prefix, _ := ipNet.Mask.Size()
+	if ipVersion == 6 && len(ip) == 4 {
+		ip = append(make([]byte, 12), ip...)
+		prefix += 96
+	}
+
+	// search tree with one node per prefix bit
+	nodeCount := prefix
+	var tree []byte
+	for i := 0; i < nodeCount; i++ {
+		next := uint32(i + 1)
+		if i == nodeCount-1 {
+			next = uint32(nodeCount + 16 + recordOffset)
+		}
+		records := [2]uint32{uint32(nodeCount), uint32(nodeCount)}
+		records[(ip[i/8]>>(7-uint(i%8)))&1] = next
+
+		switch recordSize {
+		case 24:
+			for _, r := range records {
+				tree = append(tree, byte(r>>16), byte(r>>8), byte(r))
+			}
+		case 28:
+			tree = append(tree,
+				byte(records[0]>>16), byte(records[0]>>8), byte(records[0]),
+				byte(records[0]>>20)&0xf0|byte(records[1]>>24)&0x0f,
+				byte(records[1]>>16), byte(records[1]>>8), byte(records[1]))
+		case 32:
+			b := make([]byte, 8)
+			binary.BigEndian.PutUint32(b, records[0])
+			binary.BigEndian.PutUint32(b[4:], records[1])
+			tree = append(tree, b...)
+		}
+	}
+
+	var buf bytes.Buffer
+	buf.Write(tree)
+	buf.Write(make([]byte, 16))
+	buf.Write(data)
+	buf.Write(mmdbMetadataMarker)
+	buf.Write(mmdbEncodeMap(map[string][]byte{
+		"node_count":    mmdbEncodeUint32(uint32(nodeCount)),
+		"record_size":   mmdbEncodeUint32(uint32(recordSize)),
+		"ip_version":    mmdbEncodeUint32(uint32(ipVersion)),
+		"database_type": mmdbEncodeString("GeoLite2-City"),
+	}))
+	return buf.Bytes()
+}
+
+// newTestGeoIP creates a geoip processor using the database.
+func newTestGeoIP(t *testing.T, database []byte) (processor, error) {
+	dir, err := ioutil.TempDir("", "geoip")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "GeoLite2-City.mmdb")
+	if err := ioutil.WriteFile(path, database, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	return newGeoIP(map[string]interface{}{"field": "ip"}, Config{GeoIP: GeoIPConfig{DatabaseFile: path}})
+}
+
+func TestGeoIP(t *testing.T) {
+	expected := common.MapStr{
+		"continent_name":   "Europe",
+		"country_iso_code": "GB",
+		"region_name":      "England",
+		"city_name":        "London",
+		"location":         common.MapStr{"lat": 51.5142, "lon": -0.0931},
+	}
+
+	for _, ipVersion := range []int{4, 6} {
+		for _, recordSize := range []int{24, 28, 32} {
+			p, err := newTestGeoIP(t, buildTestDatabase(t, "81.2.69.0/24", recordSize, ipVersion))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			doc := common.MapStr{"ip": "81.2.69.160"}
+			assert.NoError(t, p.Run(doc))
+			assert.Equal(t, expected, doc["geoip"], "record size %d, ip version %d", recordSize, ipVersion)
+
+			doc = common.MapStr{"ip": "81.2.70.1"}
+			assert.NoError(t, p.Run(doc))
+			assert.Equal(t, common.MapStr{"ip": "81.2.70.1"}, doc)
+
+			assert.Error(t, p.Run(common.MapStr{"ip": "not an ip"}))
+		}
+	}
+}
+
+func TestGeoIPInvalidDatabase(t *testing.T) {
+	_, err := newTestGeoIP(t, []byte("not a database"))
+	assert.Error(t, err)
+}
+
+func TestGeoIPCorruptDatabase(t *testing.T) {
+	databases := map[string][]byte{
+		// the record is a pointer to itself
+		"pointer loop": buildDatabase(t, "81.2.69.0/24", 24, 4, mmdbEncodePointer(0), 0),
+		// the string is longer than the data section
+		"oversized string": buildDatabase(t, "81.2.69.0/24", 24, 4, mmdbEncodeMap(map[string][]byte{
+			"country": mmdbEncodeMap(map[string][]byte{
+				"iso_code": mmdbControl(mmdbString, 28),
+			}),
+		}), 0),
+	}
+
+	for name, database := range databases {
+		p, err := newTestGeoIP(t, database)
+		if !assert.NoError(t, err, name) {
+			continue
+		}
+		assert.Error(t, p.Run(common.MapStr{"ip": "81.2.69.160"}), name)
+	}
+}
+
+func TestGeoIPWithoutDatabase(t *testing.T) {
+	p, err := newGeoIP(map[string]interface{}{"field": "ip"}, Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	doc := common.MapStr{"ip": "81.2.69.160"}
+	assert.NoError(t, p.Run(doc))
+	assert.Equal(t, common.MapStr{"ip": "81.2.69.160"}, doc)
+}
@@ -0,0 +1,313 @@
+package ingest
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/elastic/beats/libbeat/common"
+)
+
+func init() {
+	registerProcessor("grok", newGrok)
+}
+
+// grokReference matches %{NAME}, %{NAME:field} and %{NAME:field:type}
+var grokReference = regexp.MustCompile(`%{(\w+)(?::([\w.@\[\]-]+))?(?::(int|float))?}`)
+
+// grokNamedGroup matches the Oniguruma named groups (?<field>...) and (?'field'...)
+var grokNamedGroup = regexp.MustCompile(`\(\?(?:<([\w.@-]+)>|'([\w.@-]+)')`)
+
+// grok extracts fields from a string using one of several grok expressions.
+// The first matching expression is used.
+type grok struct {
+	field         string
+	patterns      []*grokPattern
+	ignoreMissing bool
+}
+
+// grokPattern is a grok expression compiled to a regular expression
+type grokPattern struct {
+	expression string
+	regexp     *regexp.Regexp
+	captures   []grokCapture // captures by group index
+}
+
+type grokCapture struct {
+	field string
+	typ   string
+}
+
+func newGrok(def map[string]interface{}, _ Config) (processor, error) {
+	field, err := getRequiredString(def, "field")
+	if err != nil {
+		return nil, err
+	}
+	expressions, err := getStringList(def, "patterns")
+	if err != nil {
+		return nil, err
+	}
+	if len(expressions) == 0 {
+		return nil, fmt.Errorf("[patterns] List of patterns must not be empty")
+	}
+	definitions, err := getStringMap(def, "pattern_definitions")
+	if err != nil {
+		return nil, err
+	}
+	ignoreMissing, err := getBool(def, "ignore_missing", false)
+	if err != nil {
+		return nil, err
+	}
+
+	g := &grok{field: field, ignoreMissing: ignoreMissing}
+	for _, expression := range expressions {
+		pattern, err := compileGrok(expression, definitions)
+		if err != nil {
+			return nil, err
+		}
+		g.patterns = append(g.patterns, pattern)
+	}
+	return g, nil
+}
+
+func (g *grok) Type() string { return "grok" }
+
+func (g *grok) Run(doc common.MapStr) error {
+	value, err := getStringField(doc, g.field, g.ignoreMissing)
+	if err != nil || value == nil {
+		return err
+	}
+
+	for _, pattern := range g.patterns {
+		fields, matched := pattern.match(*value)
+		if !matched {
+			continue
+		}
+		for name, v := range fields {
+			if _, err := doc.Put(name, v); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	return fmt.Errorf("Provided Grok expressions do not match field value: [%s]", *value)
+}
+
+// match returns the captured fields if the pattern matches the value.
+func (p *grokPattern) match(value string) (map[string]interface{}, bool) {
+	indices := p.regexp.FindStringSubmatchIndex(value)
+	if indices == nil {
+		return nil, false
+	}
+
+	fields := map[string]interface{}{}
+	for i, capture := range p.captures {
+		start, end := indices[2*i], indices[2*i+1]
+		if capture.field == "" || start < 0 {
+			continue
+		}
+
+		s := value[start:end]
+		switch capture.typ {
+		case "int":
+			if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+				fields[capture.field] = n
+				continue
+			}
+		case "float":
+			if f, err := strconv.ParseFloat(s, 64); err == nil {
+				fields[capture.field] = f
+				continue
+			}
+		}
+		fields[capture.field] = s
+	}
+	return fields, true
+}
+
+// compileGrok expands all pattern references of the grok expression and
+// compiles the result. Custom definitions take precedence over the built-in
+// patterns.
+func compileGrok(expression string, definitions map[string]string) (*grokPattern, error) {
+	var captures []grokCapture
+	expanded, err := expandGrok(expression, definitions, &captures, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	re, err := regexp.Compile(expanded)
+	if err != nil {
+		return nil, fmt.Errorf("invalid grok expression [%s]: %v", expression, err)
+	}
+
+	// Map the named groups created for captures to group indices. Unnamed
+	// groups of the patterns don't capture any field.
+	byName := map[string]grokCapture{}
+	for i, c := range captures {
+		byName[groupName(i)] = c
+	}
+	indexed := make([]grokCapture, len(re.SubexpNames()))
+	for i, name := range re.SubexpNames() {
+		indexed[i] = byName[name]
+	}
+
+	return &grokPattern{expression: expression, regexp: re, captures: indexed}, nil
+}
+
+// maxGrokDepth limits the nesting of pattern references to detect cycles
+const maxGrokDepth = 50
+
+func expandGrok(expression string, definitions map[string]string, captures *[]grokCapture, depth int) (string, error) {
+	if depth > maxGrokDepth {
+		return "", fmt.Errorf("circular reference in grok pattern [%s]", expression)
+	}
+
+	if depth == 0 {
+		// (?m) makes the dot match newlines in the Oniguruma Ruby syntax, with
+		// ^ and $ always matching at line boundaries.
+		expression = strings.Replace(expression, "(?m)", "(?ms)", -1)
+	}
+
+	expression = grokNamedGroup.ReplaceAllStringFunc(expression, func(group string) string {
+		parts := grokNamedGroup.FindStringSubmatch(group)
+		field := parts[1] + parts[2]
+		*captures = append(*captures, grokCapture{field: field})
+		return fmt.Sprintf("(?P<%s>", groupName(len(*captures)-1))
+	})
+
+	var err error
+	expanded := grokReference.ReplaceAllStringFunc(expression, func(ref string) string {
+		if err != nil {
+			return ""
+		}
+
+		parts := grokReference.FindStringSubmatch(ref)
+		name, field, typ := parts[1], parts[2], parts[3]
+
+		definition, exists := definitions[name]
+		if !exists {
+			definition, exists = grokPatterns[name]
+		}
+		if !exists {
+			err = fmt.Errorf("Unable to find pattern [%s] in Grok's pattern dictionary", name)
+			return ""
+		}
+
+		var sub string
+		sub, err = expandGrok(definition, definitions, captures, depth+1)
+		if err != nil {
+			return ""
+		}
+
+		if field == "" {
+			return "(?:" + sub + ")"
+		}
+
+		*captures = append(*captures, grokCapture{field: field, typ: typ})
+		return fmt.Sprintf("(?P<%s>%s)", groupName(len(*captures)-1), sub)
+	})
+	return expanded, err
+}
+
+func groupName(i int) string {
+	return "grok" + strconv.Itoa(i)
+}
+
+// grokPatterns contains the built-in patterns, adapted from the Logstash grok
+// patterns to the regular expression syntax supported by Go.
+var grokPatterns = map[string]string{
+	"USERNAME":       `[a-zA-Z0-9._-]+`,
+	"USER":           `%{USERNAME}`,
+	"EMAILLOCALPART": `[a-zA-Z][a-zA-Z0-9_.+-=:]+`,
+	"EMAILADDRESS":   `%{EMAILLOCALPART}@%{HOSTNAME}`,
+	"INT":            `[+-]?[0-9]+`,
+	"BASE10NUM":      `[+-]?(?:[0-9]+(?:\.[0-9]+)?|\.[0-9]+)`,
+	"NUMBER":         `%{BASE10NUM}`,
+	"BASE16NUM":      `[+-]?(?:0x)?[0-9A-Fa-f]+`,
+	"BASE16FLOAT":    `\b[+-]?(?:0x)?(?:[0-9A-Fa-f]+(?:\.[0-9A-Fa-f]*)?|\.[0-9A-Fa-f]+)\b`,
+	"POSINT":         `\b[1-9][0-9]*\b`,
+	"NONNEGINT":      `\b[0-9]+\b`,
+	"WORD":           `\b\w+\b`,
+	"NOTSPACE":       `\S+`,
+	"SPACE":          `\s*`,
+	"DATA":           `.*?`,
+	"GREEDYDATA":     `.*`,
+	"QUOTEDSTRING":   `"(?:\\.|[^\\"]+)+"|""|'(?:\\.|[^\\']+)+'|''`,
+	"UUID":           `[A-Fa-f0-9]{8}-(?:[A-Fa-f0-9]{4}-){3}[A-Fa-f0-9]{12}`,
+
+	// Networking
+	"MAC":        `%{CISCOMAC}|%{WINDOWSMAC}|%{COMMONMAC}`,
+	"CISCOMAC":   `(?:[A-Fa-f0-9]{4}\.){2}[A-Fa-f0-9]{4}`,
+	"WINDOWSMAC": `(?:[A-Fa-f0-9]{2}-){5}[A-Fa-f0-9]{2}`,
+	"COMMONMAC":  `(?:[A-Fa-f0-9]{2}:){5}[A-Fa-f0-9]{2}`,
+	"IPV6": `((([0-9A-Fa-f]{1,4}:){7}([0-9A-Fa-f]{1,4}|:))|(([0-9A-Fa-f]{1,4}:){6}(:[0-9A-Fa-f]{1,4}|((25[0-5]|2[0-4]\d|1\d\d|[1-9]?\d)(\.(25[0-5]|2[0-4]\d|1\d\d|[1-9]?\d)){3})|:))|` +
+		`(([0-9A-Fa-f]{1,4}:){5}(((:[0-9A-Fa-f]{1,4}){1,2})|:((25[0-5]|2[0-4]\d|1\d\d|[1-9]?\d)(\.(25[0-5]|2[0-4]\d|1\d\d|[1-9]?\d)){3})|:))|` +
+		`(([0-9A-Fa-f]{1,4}:){4}(((:[0-9A-Fa-f]{1,4}){1,3})|((:[0-9A-Fa-f]{1,4})?:((25[0-5]|2[0-4]\d|1\d\d|[1-9]?\d)(\.(25[0-5]|2[0-4]\d|1\d\d|[1-9]?\d)){3}))|:))|` +
+		`(([0-9A-Fa-f]{1,4}:){3}(((:[0-9A-Fa-f]{1,4}){1,4})|((:[0-9A-Fa-f]{1,4}){0,2}:((25[0-5]|2[0-4]\d|1\d\d|[1-9]?\d)(\.(25[0-5]|2[0-4]\d|1\d\d|[1-9]?\d)){3}))|:))|` +
+		`(([0-9A-Fa-f]{1,4}:){2}(((:[0-9A-Fa-f]{1,4}){1,5})|((:[0-9A-Fa-f]{1,4}){0,3}:((25[0-5]|2[0-4]\d|1\d\d|[1-9]?\d)(\.(25[0-5]|2[0-4]\d|1\d\d|[1-9]?\d)){3}))|:))|` +
+		`(([0-9A-Fa-f]{1,4}:){1}(((:[0-9A-Fa-f]{1,4}){1,6})|((:[0-9A-Fa-f]{1,4}){0,4}:((25[0-5]|2[0-4]\d|1\d\d|[1-9]?\d)(\.(25[0-5]|2[0-4]\d|1\d\d|[1-9]?\d)){3}))|:))|` +
+		`(:(((:[0-9A-Fa-f]{1,4}){1,7})|((:[0-9A-Fa-f]{1,4}){0,5}:((25[0-5]|2[0-4]\d|1\d\d|[1-9]?\d)(\.(25[0-5]|2[0-4]\d|1\d\d|[1-9]?\d)){3}))|:)))(%.+)?`,
+	"IPV4":     `(?:(?:25[0-5]|2[0-4][0-9]|[01]?[0-9]?[0-9])\.){3}(?:25[0-5]|2[0-4][0-9]|[01]?[0-9]?[0-9])`,
+	"IP":       `%{IPV6}|%{IPV4}`,
+	"HOSTNAME": `\b(?:[0-9A-Za-z][0-9A-Za-z-]{0,62})(?:\.(?:[0-9A-Za-z][0-9A-Za-z-]{0,62}))*(?:\.?|\b)`,
+	"IPORHOST": `%{IP}|%{HOSTNAME}`,
+	"HOSTPORT": `%{IPORHOST}:%{POSINT}`,
+
+	// Paths
+	"PATH":         `%{UNIXPATH}|%{WINPATH}`,
+	"UNIXPATH":     `(?:/[\w_%!$@:.,+~-]*)+`,
+	"TTY":          `/dev/(?:pts|tty(?:[pq])?)(?:\w+)?/?(?:[0-9]+)`,
+	"WINPATH":      `(?:[A-Za-z]+:|\\)(?:\\[^\\?*]*)+`,
+	"URIPROTO":     `[A-Za-z]+(?:\+[A-Za-z+]+)?`,
+	"URIHOST":      `%{IPORHOST}(?::%{POSINT})?`,
+	"URIPATH":      `(?:/[A-Za-z0-9$.+!*'(){},~:;=@#%&_\-]*)+`,
+	"URIPARAM":     `\?[A-Za-z0-9$.+!*'|(){},~@#%&/=:;_?\-\[\]<>]*`,
+	"URIPATHPARAM": `%{URIPATH}(?:%{URIPARAM})?`,
+	"URI":          `%{URIPROTO}://(?:%{USER}(?::[^@]*)?@)?(?:%{URIHOST})?(?:%{URIPATHPARAM})?`,
+
+	// Months and days
+	"MONTH":     `\b(?:[Jj]an(?:uary|uar)?|[Ff]eb(?:ruary|ruar)?|[Mm](?:a|ä)?r(?:ch|z)?|[Aa]pr(?:il)?|[Mm]a(?:y|i)?|[Jj]un(?:e|i)?|[Jj]ul(?:y)?|[Aa]ug(?:ust)?|[Ss]ep(?:tember)?|[Oo](?:c|k)?t(?:ober)?|[Nn]ov(?:ember)?|[Dd]e(?:c|z)(?:ember)?)\b`,
+	"MONTHNUM":  `0?[1-9]|1[0-2]`,
+	"MONTHNUM2": `0[1-9]|1[0-2]`,
+	"MONTHDAY":  `(?:0[1-9])|(?:[12][0-9])|(?:3[01])|[1-9]`,
+	"DAY":       `\b(?:Mon(?:day)?|Tue(?:sday)?|Wed(?:nesday)?|Thu(?:rsday)?|Fri(?:day)?|Sat(?:urday)?|Sun(?:day)?)\b`,
+
+	// Years, hours, minutes and seconds
+	"YEAR":   `(?:\d\d){1,2}`,
+	"HOUR":   `2[0123]|[01]?[0-9]`,
+	"MINUTE": `[0-5][0-9]`,
+	"SECOND": `(?:[0-5]?[0-9]|60)(?:[:.,][0-9]+)?`,
+	"TIME":   `%{HOUR}:%{MINUTE}(?::%{SECOND})`,
+
+	// Datestamps
+	"DATE_US":           `%{MONTHNUM}[/-]%{MONTHDAY}[/-]%{YEAR}`,
+	"DATE_EU":           `%{MONTHDAY}[./-]%{MONTHNUM}[./-]%{YEAR}`,
+	"ISO8601_TIMEZONE":  `Z|[+-]%{HOUR}(?::?%{MINUTE})`,
+	"ISO8601_SECOND":    `(?:%{SECOND}|60)`,
+	"TIMESTAMP_ISO8601": `%{YEAR}-%{MONTHNUM}-%{MONTHDAY}[T ]%{HOUR}:?%{MINUTE}(?::?%{SECOND})?%{ISO8601_TIMEZONE}?`,
+	"DATE":              `%{DATE_US}|%{DATE_EU}`,
+	"DATESTAMP":         `%{DATE}[- ]%{TIME}`,
+	"TZ":                `[A-Z]{3}`,
+	"DATESTAMP_RFC822":  `%{DAY} %{MONTH} %{MONTHDAY} %{YEAR} %{TIME} %{TZ}`,
+	"DATESTAMP_RFC2822": `%{DAY}, %{MONTHDAY} %{MONTH} %{YEAR} %{TIME} %{ISO8601_TIMEZONE}`,
+	"DATESTAMP_OTHER":   `%{DAY} %{MONTH} %{MONTHDAY} %{TIME} %{TZ} %{YEAR}`,
+	"HTTPDATE":          `%{MONTHDAY}/%{MONTH}/%{YEAR}:%{TIME} %{INT}`,
+
+	// Syslog
+	"SYSLOGTIMESTAMP": `%{MONTH} +%{MONTHDAY} %{TIME}`,
+	"PROG":            `[\x21-\x5a\x5c\x5e-\x7e]+`,
+	"SYSLOGPROG":      `%{PROG:program}(?:\[%{POSINT:pid}\])?`,
+	"SYSLOGHOST":      `%{IPORHOST}`,
+	"SYSLOGFACILITY":  `<%{NONNEGINT:facility}.%{NONNEGINT:priority}>`,
+
+	// Java
+	"JAVACLASS":      `(?:[a-zA-Z$_][a-zA-Z$_0-9]*\.)*[a-zA-Z$_][a-zA-Z$_0-9]*`,
+	"JAVAFILE":       `(?:[A-Za-z0-9_. -]+)`,
+	"JAVAMETHOD":     `(?:(<init>)|[a-zA-Z$_][a-zA-Z$_0-9]*)`,
+	"JAVALOGMESSAGE": `(.*)`,
+
+	// Log levels
+	"LOGLEVEL": `[Aa]lert|ALERT|[Tt]race|TRACE|[Dd]ebug|DEBUG|[Nn]otice|NOTICE|[Ii]nfo|INFO|[Ww]arn?(?:ing)?|WARN?(?:ING)?|[Ee]rr?(?:or)?|ERR?(?:OR)?|[Cc]rit?(?:ical)?|CRIT?(?:ICAL)?|[Ff]atal|FATAL|[Ss]evere|SEVERE|EMERG(?:ENCY)?|[Ee]merg(?:ency)?`,
+}
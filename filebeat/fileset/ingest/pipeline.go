@@ -0,0 +1,249 @@
+/*
+Package ingest executes Elasticsearch Ingest Node pipelines inside the Beat.
+
+Only a subset of the Ingest Node processors is supported. Pipelines using other
+processors can not be loaded. The processors operate on the event fields, with
+the event timestamp being available as `@timestamp` field.
+*/
+package ingest
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/elastic/beats/libbeat/beat"
+	"github.com/elastic/beats/libbeat/common"
+)
+
+const timestampField = "@timestamp"
+
+// Pipeline is a compiled ingest pipeline. It implements the processor interface
+// of the publisher pipeline.
+type Pipeline struct {
+	id         string
+	processors []processor
+	onFailure  []processor
+}
+
+// processor is a single ingest processor
+type processor interface {
+	// Type returns the name of the processor as used in the pipeline definition.
+	Type() string
+	// Run modifies the document. An error fails the processor.
+	Run(doc common.MapStr) error
+}
+
+// constructor creates a processor from its pipeline definition
+type constructor func(def map[string]interface{}, config Config) (processor, error)
+
+var constructors = map[string]constructor{}
+
+func registerProcessor(name string, c constructor) {
+	constructors[name] = c
+}
+
+// failure records the processor which failed, to be made available to the
+// on_failure processors.
+type failure struct {
+	processor processor
+	tag       string
+	err       error
+}
+
+func (f *failure) Error() string {
+	return f.err.Error()
+}
+
+// Load reads the ingest pipeline definition from the JSON file at path and
+// compiles it.
+func Load(path string, config Config) (*Pipeline, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("Error reading pipeline file %s: %v", path, err)
+	}
+	defer f.Close()
+
+	var content map[string]interface{}
+	if err := json.NewDecoder(f).Decode(&content); err != nil {
+		return nil, fmt.Errorf("Error JSON decoding the pipeline file: %s: %v", path, err)
+	}
+
+	return New(path, content, config)
+}
+
+// New compiles the pipeline definition. The id is only used for logging.
+func New(id string, content map[string]interface{}, config Config) (*Pipeline, error) {
+	processors, err := newProcessors(content["processors"], config)
+	if err != nil {
+		return nil, err
+	}
+
+	onFailure, err := newProcessors(content["on_failure"], config)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Pipeline{
+		id:         id,
+		processors: processors,
+		onFailure:  onFailure,
+	}, nil
+}
+
+// newProcessors compiles a list of processor definitions
+func newProcessors(defs interface{}, config Config) ([]processor, error) {
+	if defs == nil {
+		return nil, nil
+	}
+
+	list, ok := defs.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("processors must be a list")
+	}
+
+	var processors []processor
+	for _, def := range list {
+		entry, ok := def.(map[string]interface{})
+		if !ok || len(entry) != 1 {
+			return nil, fmt.Errorf("each processor needs to have exactly one type")
+		}
+
+		for name, body := range entry {
+			settings, ok := body.(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("invalid settings for processor %s", name)
+			}
+
+			p, err := newProcessor(name, settings, config)
+			if err != nil {
+				return nil, err
+			}
+			processors = append(processors, p)
+		}
+	}
+	return processors, nil
+}
+
+func newProcessor(name string, settings map[string]interface{}, config Config) (processor, error) {
+	c, exists := constructors[name]
+	if !exists {
+		return nil, fmt.Errorf("processor %s is not supported", name)
+	}
+
+	p, err := c(settings, config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create processor %s: %v", name, err)
+	}
+
+	onFailure, err := newProcessors(settings["on_failure"], config)
+	if err != nil {
+		return nil, err
+	}
+
+	ignoreFailure, err := getBool(settings, "ignore_failure", false)
+	if err != nil {
+		return nil, err
+	}
+
+	tag, err := getString(settings, "tag", "")
+	if err != nil {
+		return nil, err
+	}
+
+	if len(onFailure) == 0 && !ignoreFailure && tag == "" {
+		return p, nil
+	}
+	return &compoundProcessor{
+		processor:     p,
+		onFailure:     onFailure,
+		ignoreFailure: ignoreFailure,
+		tag:           tag,
+	}, nil
+}
+
+// compoundProcessor wraps a processor with its failure handling settings
+type compoundProcessor struct {
+	processor
+	onFailure     []processor
+	ignoreFailure bool
+	tag           string
+}
+
+func (p *compoundProcessor) Run(doc common.MapStr) error {
+	err := p.processor.Run(doc)
+	if err == nil || p.ignoreFailure {
+		return nil
+	}
+
+	f := &failure{processor: p.processor, tag: p.tag, err: err}
+	if len(p.onFailure) == 0 {
+		return f
+	}
+	return runOnFailure(p.onFailure, doc, f)
+}
+
+// Run executes the pipeline on the event. If a processor fails, the remaining
+// processors are skipped and the on_failure processors of the pipeline are run.
+func (p *Pipeline) Run(event *beat.Event) (*beat.Event, error) {
+	doc := event.Fields
+	if doc == nil {
+		doc = common.MapStr{}
+	}
+	doc[timestampField] = common.Time(event.Timestamp)
+
+	if err := runProcessors(p.processors, doc); err != nil {
+		f, ok := err.(*failure)
+		if !ok || len(p.onFailure) == 0 {
+			delete(doc, timestampField)
+			return event, fmt.Errorf("pipeline %s failed: %v", p.id, err)
+		}
+		if err := runOnFailure(p.onFailure, doc, f); err != nil {
+			delete(doc, timestampField)
+			return event, fmt.Errorf("pipeline %s failed: %v", p.id, err)
+		}
+	}
+
+	if ts, ok := doc[timestampField].(common.Time); ok {
+		event.Timestamp = time.Time(ts)
+	}
+	delete(doc, timestampField)
+	event.Fields = doc
+
+	return event, nil
+}
+
+func (p *Pipeline) String() string {
+	types := make([]string, len(p.processors))
+	for i, proc := range p.processors {
+		types[i] = proc.Type()
+	}
+	return fmt.Sprintf("ingest_pipeline=[id=%s, processors=%s]", p.id, strings.Join(types, ","))
+}
+
+func runProcessors(processors []processor, doc common.MapStr) error {
+	for _, p := range processors {
+		if err := p.Run(doc); err != nil {
+			if _, ok := err.(*failure); ok {
+				return err
+			}
+			return &failure{processor: p, err: err}
+		}
+	}
+	return nil
+}
+
+// runOnFailure runs the on_failure processors with the failure details being
+// available as `_ingest.on_failure_*` fields.
+func runOnFailure(processors []processor, doc common.MapStr, f *failure) error {
+	doc["_ingest"] = common.MapStr{
+		"on_failure_message":        f.err.Error(),
+		"on_failure_processor_type": f.processor.Type(),
+		"on_failure_processor_tag":  f.tag,
+	}
+	defer delete(doc, "_ingest")
+
+	return runProcessors(processors, doc)
+}
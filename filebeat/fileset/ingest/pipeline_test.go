@@ -0,0 +1,275 @@
+// +build !integration
+
+package ingest
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/elastic/beats/libbeat/beat"
+	"github.com/elastic/beats/libbeat/common"
+)
+
+func newTestPipeline(t *testing.T, content string) *Pipeline {
+	var def map[string]interface{}
+	if err := json.Unmarshal([]byte(content), &def); err != nil {
+		t.Fatal(err)
+	}
+	p, err := New("test", def, Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func TestPipelineRun(t *testing.T) {
+	p := newTestPipeline(t, `{
+		"processors": [
+			{"grok": {
+				"field": "message",
+				"patterns": ["%{IPORHOST:client} \\[%{HTTPDATE:time}\\] %{WORD:method} %{NUMBER:bytes:int}"]
+			}},
+			{"remove": {"field": "message"}},
+			{"rename": {"field": "@timestamp", "target_field": "read_timestamp"}},
+			{"date": {"field": "time", "target_field": "@timestamp", "formats": ["dd/MMM/YYYY:H:m:s Z"]}},
+			{"remove": {"field": "time"}}
+		]
+	}`)
+
+	readTime := time.Date(2017, 1, 1, 0, 0, 0, 0, time.UTC)
+	event, err := p.Run(&beat.Event{
+		Timestamp: readTime,
+		Fields: common.MapStr{
+			"message": "10.0.0.1 [26/Dec/2016:16:22:13 +0100] GET 499",
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, time.Date(2016, 12, 26, 15, 22, 13, 0, time.UTC), event.Timestamp.UTC())
+	assert.Equal(t, common.MapStr{
+		"client":         "10.0.0.1",
+		"method":         "GET",
+		"bytes":          int64(499),
+		"read_timestamp": common.Time(readTime),
+	}, event.Fields)
+}
+
+func TestPipelineOnFailure(t *testing.T) {
+	p := newTestPipeline(t, `{
+		"processors": [
+			{"grok": {"field": "message", "patterns": ["%{NUMBER:value}"]}},
+			{"set": {"field": "reached", "value": true}}
+		],
+		"on_failure": [
+			{"set": {"field": "error.message", "value": "{{ _ingest.on_failure_message }}"}}
+		]
+	}`)
+
+	event, err := p.Run(&beat.Event{Fields: common.MapStr{"message": "no number"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, common.MapStr{
+		"message": "no number",
+		"error": common.MapStr{
+			"message": "Provided Grok expressions do not match field value: [no number]",
+		},
+	}, event.Fields)
+}
+
+func TestPipelineFailureWithoutHandler(t *testing.T) {
+	p := newTestPipeline(t, `{"processors": [{"rename": {"field": "a", "target_field": "b"}}]}`)
+
+	_, err := p.Run(&beat.Event{Fields: common.MapStr{}})
+	assert.Error(t, err)
+}
+
+func TestProcessorFailureHandling(t *testing.T) {
+	p := newTestPipeline(t, `{
+		"processors": [
+			{"convert": {"field": "a", "type": "integer", "ignore_failure": true}},
+			{"convert": {
+				"field": "b",
+				"type": "integer",
+				"tag": "convert_b",
+				"on_failure": [{"set": {"field": "failed", "value": "{{_ingest.on_failure_processor_tag}}"}}]
+			}}
+		]
+	}`)
+
+	event, err := p.Run(&beat.Event{Fields: common.MapStr{"a": "x", "b": "y"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, common.MapStr{"a": "x", "b": "y", "failed": "convert_b"}, event.Fields)
+}
+
+func TestUnsupportedProcessor(t *testing.T) {
+	var def map[string]interface{}
+	json.Unmarshal([]byte(`{"processors": [{"script": {"lang": "painless", "inline": "ctx.a = 1"}}]}`), &def)
+
+	_, err := New("test", def, Config{})
+	assert.Error(t, err)
+}
+
+func TestConvert(t *testing.T) {
+	tests := []struct {
+		typ      string
+		value    interface{}
+		expected interface{}
+	}{
+		{"integer", "42", int64(42)},
+		{"long", "-1", int64(-1)},
+		{"float", "1.5", 1.5},
+		{"boolean", "TRUE", true},
+		{"string", 42, "42"},
+		{"auto", "12", int64(12)},
+		{"auto", "1.2", 1.2},
+		{"auto", "false", false},
+		{"auto", "abc", "abc"},
+	}
+
+	for _, test := range tests {
+		p, err := newConvert(map[string]interface{}{"field": "f", "type": test.typ}, Config{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		doc := common.MapStr{"f": test.value}
+		if assert.NoError(t, p.Run(doc), test.typ) {
+			assert.Equal(t, test.expected, doc["f"], test.typ)
+		}
+	}
+}
+
+func TestSplit(t *testing.T) {
+	p, err := newSplit(map[string]interface{}{"field": "f", "separator": `,\s*`}, Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc := common.MapStr{"f": "a, b,c"}
+	assert.NoError(t, p.Run(doc))
+	assert.Equal(t, []interface{}{"a", "b", "c"}, doc["f"])
+}
+
+func TestGrokTypes(t *testing.T) {
+	p, err := newGrok(map[string]interface{}{
+		"field":    "message",
+		"patterns": []interface{}{"%{NUMBER:a:int} %{NUMBER:b:float} %{MYWORD:c}"},
+		"pattern_definitions": map[string]interface{}{
+			"MYWORD": "%{WORD}",
+		},
+	}, Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	doc := common.MapStr{"message": "1 2.5 abc"}
+	assert.NoError(t, p.Run(doc))
+	assert.Equal(t, int64(1), doc["a"])
+	assert.Equal(t, 2.5, doc["b"])
+	assert.Equal(t, "abc", doc["c"])
+}
+
+func TestDateFormats(t *testing.T) {
+	year := time.Now().Year()
+
+	tests := []struct {
+		format, timezone, value string
+		expected                time.Time
+	}{
+		{"dd/MMM/YYYY:H:m:s Z", "", "07/Dec/2016:11:05:07 +0100", time.Date(2016, 12, 7, 10, 5, 7, 0, time.UTC)},
+		{"YYYY-MM-dd HH:mm:ss,SSS", "", "2017-03-28 10:15:01,123", time.Date(2017, 3, 28, 10, 15, 1, 123000000, time.UTC)},
+		{"MMM  d HH:mm:ss", "", "Feb  4 09:04:01", time.Date(year, 2, 4, 9, 4, 1, 0, time.UTC)},
+		{"MMM dd HH:mm:ss", "Europe/Berlin", "Jun 01 12:00:00", time.Date(year, 6, 1, 10, 0, 0, 0, time.UTC)},
+		{"EEE MMM dd H:m:s YYYY", "", "Mon Dec 26 16:22:08 2016", time.Date(2016, 12, 26, 16, 22, 8, 0, time.UTC)},
+		{"yyyy-MM-dd'T'HH:mm:ss", "", "2016-11-02T10:00:00", time.Date(2016, 11, 2, 10, 0, 0, 0, time.UTC)},
+		{"ISO8601", "", "2016-12-26T16:22:08.123Z", time.Date(2016, 12, 26, 16, 22, 8, 123000000, time.UTC)},
+		{"UNIX", "", "1482769328.5", time.Date(2016, 12, 26, 16, 22, 8, 500000000, time.UTC)},
+		{"UNIX_MS", "", "1482769328123", time.Date(2016, 12, 26, 16, 22, 8, 123000000, time.UTC)},
+	}
+
+	for _, test := range tests {
+		def := map[string]interface{}{
+			"field":   "f",
+			"formats": []interface{}{"YYYY", test.format},
+		}
+		if test.timezone != "" {
+			def["timezone"] = test.timezone
+		}
+		p, err := newDate(def, Config{})
+		if err != nil {
+			t.Fatal(test.format, err)
+		}
+
+		doc := common.MapStr{"f": test.value}
+		if assert.NoError(t, p.Run(doc), test.format) {
+			assert.Equal(t, test.expected, time.Time(doc[timestampField].(common.Time)), test.format)
+		}
+	}
+}
+
+func TestDateFailure(t *testing.T) {
+	p, err := newDate(map[string]interface{}{"field": "f", "formats": "YYYY-MM-dd"}, Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Error(t, p.Run(common.MapStr{"f": "not a date"}))
+}
+
+func TestUserAgent(t *testing.T) {
+	tests := []struct {
+		agent    string
+		expected map[string]string
+	}{
+		{
+			"Mozilla/5.0 (Macintosh; Intel Mac OS X 10.12; rv:49.0) Gecko/20100101 Firefox/49.0",
+			map[string]string{
+				"name": "Firefox", "major": "49", "minor": "0",
+				"os": "Mac OS X 10.12", "os_name": "Mac OS X", "os_major": "10", "os_minor": "12",
+				"device": "Other",
+			},
+		},
+		{
+			"Mozilla/5.0 (Windows NT 6.1; rv:15.0) Gecko/20120716 Firefox/15.0a2",
+			map[string]string{
+				"name": "Firefox Alpha", "major": "15", "minor": "0", "patch": "a2",
+				"os": "Windows 7", "os_name": "Windows 7",
+				"device": "Other",
+			},
+		},
+		{
+			"Mozilla/5.0 (compatible; Facebot 1.0; https://developers.facebook.com/docs/sharing/webmasters/crawler)",
+			map[string]string{
+				"name": "Facebot", "major": "1", "minor": "0",
+				"os": "Other", "os_name": "Other",
+				"device": "Spider",
+			},
+		},
+		{
+			"Amazon CloudFront",
+			map[string]string{"name": "Other", "os": "Other", "os_name": "Other", "device": "Other"},
+		},
+	}
+
+	p, err := newUserAgent(map[string]interface{}{"field": "agent"}, Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, test := range tests {
+		doc := common.MapStr{"agent": test.agent}
+		assert.NoError(t, p.Run(doc))
+
+		expected := common.MapStr{}
+		for k, v := range test.expected {
+			expected[k] = v
+		}
+		assert.Equal(t, expected, doc["user_agent"], test.agent)
+	}
+}
@@ -0,0 +1,400 @@
+package ingest
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/elastic/beats/libbeat/common"
+)
+
+func init() {
+	registerProcessor("rename", newRename)
+	registerProcessor("remove", newRemove)
+	registerProcessor("convert", newConvert)
+	registerProcessor("set", newSet)
+	registerProcessor("split", newSplit)
+}
+
+// rename moves a field to a new name
+type rename struct {
+	field         string
+	target        string
+	ignoreMissing bool
+}
+
+func newRename(def map[string]interface{}, _ Config) (processor, error) {
+	field, err := getRequiredString(def, "field")
+	if err != nil {
+		return nil, err
+	}
+	target, err := getRequiredString(def, "target_field")
+	if err != nil {
+		return nil, err
+	}
+	ignoreMissing, err := getBool(def, "ignore_missing", false)
+	if err != nil {
+		return nil, err
+	}
+	return &rename{field: field, target: target, ignoreMissing: ignoreMissing}, nil
+}
+
+func (p *rename) Type() string { return "rename" }
+
+func (p *rename) Run(doc common.MapStr) error {
+	value, err := doc.GetValue(p.field)
+	if err != nil {
+		if p.ignoreMissing {
+			return nil
+		}
+		return fmt.Errorf("field [%s] doesn't exist", p.field)
+	}
+
+	if exists, _ := doc.HasKey(p.target); exists {
+		return fmt.Errorf("field [%s] already exists", p.target)
+	}
+
+	if err := doc.Delete(p.field); err != nil {
+		return err
+	}
+	_, err = doc.Put(p.target, value)
+	return err
+}
+
+// remove deletes one or more fields
+type remove struct {
+	fields        []string
+	ignoreMissing bool
+}
+
+func newRemove(def map[string]interface{}, _ Config) (processor, error) {
+	fields, err := getStringList(def, "field")
+	if err != nil {
+		return nil, err
+	}
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("[field] required property is missing")
+	}
+	ignoreMissing, err := getBool(def, "ignore_missing", false)
+	if err != nil {
+		return nil, err
+	}
+	return &remove{fields: fields, ignoreMissing: ignoreMissing}, nil
+}
+
+func (p *remove) Type() string { return "remove" }
+
+func (p *remove) Run(doc common.MapStr) error {
+	for _, field := range p.fields {
+		if err := doc.Delete(field); err != nil && !p.ignoreMissing {
+			return fmt.Errorf("field [%s] not present as part of path [%s]", field, field)
+		}
+	}
+	return nil
+}
+
+// convert converts the type of a field
+type convert struct {
+	field         string
+	target        string
+	typ           string
+	ignoreMissing bool
+}
+
+func newConvert(def map[string]interface{}, _ Config) (processor, error) {
+	field, err := getRequiredString(def, "field")
+	if err != nil {
+		return nil, err
+	}
+	target, err := getString(def, "target_field", field)
+	if err != nil {
+		return nil, err
+	}
+	typ, err := getRequiredString(def, "type")
+	if err != nil {
+		return nil, err
+	}
+	switch typ {
+	case "integer", "long", "float", "double", "boolean", "string", "auto":
+	default:
+		return nil, fmt.Errorf("type [%s] not supported", typ)
+	}
+	ignoreMissing, err := getBool(def, "ignore_missing", false)
+	if err != nil {
+		return nil, err
+	}
+	return &convert{field: field, target: target, typ: typ, ignoreMissing: ignoreMissing}, nil
+}
+
+func (p *convert) Type() string { return "convert" }
+
+func (p *convert) Run(doc common.MapStr) error {
+	value, err := doc.GetValue(p.field)
+	if err != nil || value == nil {
+		if p.ignoreMissing {
+			return nil
+		}
+		return fmt.Errorf("field [%s] not present as part of path [%s]", p.field, p.field)
+	}
+
+	var converted interface{}
+	if list, ok := value.([]interface{}); ok {
+		values := make([]interface{}, len(list))
+		for i, v := range list {
+			if values[i], err = p.convert(v); err != nil {
+				return err
+			}
+		}
+		converted = values
+	} else if converted, err = p.convert(value); err != nil {
+		return err
+	}
+
+	_, err = doc.Put(p.target, converted)
+	return err
+}
+
+func (p *convert) convert(value interface{}) (interface{}, error) {
+	s := fmt.Sprint(value)
+
+	switch p.typ {
+	case "integer", "long":
+		i, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("unable to convert [%s] to integer", s)
+		}
+		return i, nil
+	case "float", "double":
+		f, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return nil, fmt.Errorf("unable to convert [%s] to float", s)
+		}
+		return f, nil
+	case "boolean":
+		switch strings.ToLower(s) {
+		case "true":
+			return true, nil
+		case "false":
+			return false, nil
+		}
+		return nil, fmt.Errorf("[%s] is not a boolean value, cannot convert to boolean", s)
+	case "auto":
+		if _, ok := value.(string); !ok {
+			return value, nil
+		}
+		if i, err := strconv.ParseInt(s, 10, 64); err == nil {
+			return i, nil
+		}
+		if f, err := strconv.ParseFloat(s, 64); err == nil {
+			return f, nil
+		}
+		if b, err := strconv.ParseBool(s); err == nil && (s == "true" || s == "false") {
+			return b, nil
+		}
+		return s, nil
+	default:
+		return s, nil
+	}
+}
+
+// set assigns a value to a field. String values can reference other fields
+// using {{field}} templates.
+type set struct {
+	field    string
+	value    interface{}
+	override bool
+}
+
+var templateRegexp = regexp.MustCompile(`{{\s*([^}\s]+)\s*}}`)
+
+func newSet(def map[string]interface{}, _ Config) (processor, error) {
+	field, err := getRequiredString(def, "field")
+	if err != nil {
+		return nil, err
+	}
+	value, exists := def["value"]
+	if !exists {
+		return nil, fmt.Errorf("[value] required property is missing")
+	}
+	override, err := getBool(def, "override", true)
+	if err != nil {
+		return nil, err
+	}
+	return &set{field: field, value: value, override: override}, nil
+}
+
+func (p *set) Type() string { return "set" }
+
+func (p *set) Run(doc common.MapStr) error {
+	if !p.override {
+		if exists, _ := doc.HasKey(p.field); exists {
+			return nil
+		}
+	}
+
+	value := p.value
+	if s, ok := value.(string); ok {
+		value = templateRegexp.ReplaceAllStringFunc(s, func(match string) string {
+			key := templateRegexp.FindStringSubmatch(match)[1]
+			v, err := doc.GetValue(key)
+			if err != nil || v == nil {
+				return ""
+			}
+			return fmt.Sprint(v)
+		})
+	}
+
+	_, err := doc.Put(p.field, value)
+	return err
+}
+
+// split splits a string field into a list using a regular expression separator
+type split struct {
+	field         string
+	target        string
+	separator     *regexp.Regexp
+	ignoreMissing bool
+}
+
+func newSplit(def map[string]interface{}, _ Config) (processor, error) {
+	field, err := getRequiredString(def, "field")
+	if err != nil {
+		return nil, err
+	}
+	target, err := getString(def, "target_field", field)
+	if err != nil {
+		return nil, err
+	}
+	separator, err := getRequiredString(def, "separator")
+	if err != nil {
+		return nil, err
+	}
+	re, err := regexp.Compile(separator)
+	if err != nil {
+		return nil, err
+	}
+	ignoreMissing, err := getBool(def, "ignore_missing", false)
+	if err != nil {
+		return nil, err
+	}
+	return &split{field: field, target: target, separator: re, ignoreMissing: ignoreMissing}, nil
+}
+
+func (p *split) Type() string { return "split" }
+
+func (p *split) Run(doc common.MapStr) error {
+	value, err := getStringField(doc, p.field, p.ignoreMissing)
+	if err != nil || value == nil {
+		return err
+	}
+
+	parts := p.separator.Split(*value, -1)
+	list := make([]interface{}, len(parts))
+	for i, part := range parts {
+		list[i] = part
+	}
+	_, err = doc.Put(p.target, list)
+	return err
+}
+
+// getStringField returns the string value of the field. If the field is missing
+// and ignoreMissing is set, nil is returned without error.
+func getStringField(doc common.MapStr, field string, ignoreMissing bool) (*string, error) {
+	value, err := doc.GetValue(field)
+	if err != nil || value == nil {
+		if ignoreMissing {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("field [%s] not present as part of path [%s]", field, field)
+	}
+
+	s, ok := value.(string)
+	if !ok {
+		return nil, fmt.Errorf("field [%s] of type [%T] cannot be cast to [java.lang.String]", field, value)
+	}
+	return &s, nil
+}
+
+// Helpers for reading processor definitions
+
+func getString(def map[string]interface{}, key, defaultValue string) (string, error) {
+	value, exists := def[key]
+	if !exists {
+		return defaultValue, nil
+	}
+	s, ok := value.(string)
+	if !ok {
+		return "", fmt.Errorf("[%s] property isn't a string, but of type [%T]", key, value)
+	}
+	return s, nil
+}
+
+func getRequiredString(def map[string]interface{}, key string) (string, error) {
+	s, err := getString(def, key, "")
+	if err != nil {
+		return "", err
+	}
+	if s == "" {
+		return "", fmt.Errorf("[%s] required property is missing", key)
+	}
+	return s, nil
+}
+
+func getBool(def map[string]interface{}, key string, defaultValue bool) (bool, error) {
+	value, exists := def[key]
+	if !exists {
+		return defaultValue, nil
+	}
+	b, ok := value.(bool)
+	if !ok {
+		return false, fmt.Errorf("[%s] property isn't a boolean, but of type [%T]", key, value)
+	}
+	return b, nil
+}
+
+// getStringList reads a property which is either a single string or a list of strings
+func getStringList(def map[string]interface{}, key string) ([]string, error) {
+	value, exists := def[key]
+	if !exists {
+		return nil, nil
+	}
+
+	switch v := value.(type) {
+	case string:
+		return []string{v}, nil
+	case []interface{}:
+		list := make([]string, len(v))
+		for i, item := range v {
+			s, ok := item.(string)
+			if !ok {
+				return nil, fmt.Errorf("[%s] property must only contain strings", key)
+			}
+			list[i] = s
+		}
+		return list, nil
+	}
+	return nil, fmt.Errorf("[%s] property isn't a string or list, but of type [%T]", key, value)
+}
+
+func getStringMap(def map[string]interface{}, key string) (map[string]string, error) {
+	value, exists := def[key]
+	if !exists {
+		return nil, nil
+	}
+
+	m, ok := value.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("[%s] property isn't a map, but of type [%T]", key, value)
+	}
+
+	result := make(map[string]string, len(m))
+	for k, v := range m {
+		s, ok := v.(string)
+		if !ok {
+			return nil, fmt.Errorf("[%s] property must only contain strings", key)
+		}
+		result[k] = s
+	}
+	return result, nil
+}
@@ -0,0 +1,195 @@
+package ingest
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/elastic/beats/libbeat/common"
+)
+
+func init() {
+	registerProcessor("user_agent", newUserAgent)
+}
+
+// userAgent extracts browser, operating system and device details from a user
+// agent string. It is based on a reduced set of the uap-core rules used by the
+// Ingest Node plugin, covering the most common browsers, operating systems and
+// crawlers.
+type userAgent struct {
+	field         string
+	target        string
+	properties    map[string]bool
+	ignoreMissing bool
+}
+
+var userAgentProperties = []string{
+	"name", "major", "minor", "patch", "build", "os", "os_name", "os_major", "os_minor", "device",
+}
+
+func newUserAgent(def map[string]interface{}, _ Config) (processor, error) {
+	field, err := getRequiredString(def, "field")
+	if err != nil {
+		return nil, err
+	}
+	target, err := getString(def, "target_field", "user_agent")
+	if err != nil {
+		return nil, err
+	}
+	ignoreMissing, err := getBool(def, "ignore_missing", false)
+	if err != nil {
+		return nil, err
+	}
+	properties, err := getStringList(def, "properties")
+	if err != nil {
+		return nil, err
+	}
+	if len(properties) == 0 {
+		properties = userAgentProperties
+	}
+
+	p := &userAgent{
+		field:         field,
+		target:        target,
+		properties:    map[string]bool{},
+		ignoreMissing: ignoreMissing,
+	}
+	for _, property := range properties {
+		p.properties[property] = true
+	}
+	return p, nil
+}
+
+func (p *userAgent) Type() string { return "user_agent" }
+
+func (p *userAgent) Run(doc common.MapStr) error {
+	value, err := getStringField(doc, p.field, p.ignoreMissing)
+	if err != nil || value == nil {
+		return err
+	}
+
+	details := parseUserAgent(*value)
+
+	result := common.MapStr{}
+	for key, v := range details {
+		if p.properties[key] && v != "" {
+			result[key] = v
+		}
+	}
+	_, err = doc.Put(p.target, result)
+	return err
+}
+
+// uaRule matches a user agent string. The name and version templates are
+// expanded using the submatches of the expression.
+type uaRule struct {
+	re                  *regexp.Regexp
+	name                string
+	major, minor, patch string
+}
+
+func newUARule(expr, name, major, minor, patch string) uaRule {
+	return uaRule{
+		re:    regexp.MustCompile(expr),
+		name:  name,
+		major: major,
+		minor: minor,
+		patch: patch,
+	}
+}
+
+func (r *uaRule) match(s string) (name, major, minor, patch string, ok bool) {
+	m := r.re.FindStringSubmatchIndex(s)
+	if m == nil {
+		return "", "", "", "", false
+	}
+	expand := func(template string) string {
+		return string(r.re.ExpandString(nil, template, s, m))
+	}
+	return expand(r.name), expand(r.major), expand(r.minor), expand(r.patch), true
+}
+
+var agentRules = []uaRule{
+	newUARule(`(Facebot|facebookexternalhit|Googlebot|bingbot|YandexBot|Baiduspider|DuckDuckBot)[/ ](\d+)\.(\d+)`, "$1", "$2", "$3", ""),
+	newUARule(`(Edge)/(\d+)\.(\d+)`, "Edge", "$2", "$3", ""),
+	newUARule(`(Firefox)/(\d+)\.(\d+)(a\d+[a-z]*)`, "Firefox Alpha", "$2", "$3", "$4"),
+	newUARule(`(Firefox)/(\d+)\.(\d+)(b\d+[a-z]*)`, "Firefox Beta", "$2", "$3", "$4"),
+	newUARule(`(Firefox)/(\d+)\.(\d+)(?:\.(\d+))?`, "Firefox", "$2", "$3", "$4"),
+	newUARule(`(OPR)/(\d+)\.(\d+)(?:\.(\d+))?`, "Opera", "$2", "$3", "$4"),
+	newUARule(`(CriOS)/(\d+)\.(\d+)(?:\.(\d+))?`, "Chrome Mobile iOS", "$2", "$3", "$4"),
+	newUARule(`(Chrome)/(\d+)\.(\d+)(?:\.(\d+))?.*Mobile`, "Chrome Mobile", "$2", "$3", "$4"),
+	newUARule(`(Chrome)/(\d+)\.(\d+)(?:\.(\d+))?`, "Chrome", "$2", "$3", "$4"),
+	newUARule(`Version/(\d+)\.(\d+)(?:\.(\d+))?.*Mobile.*Safari/`, "Mobile Safari", "$1", "$2", "$3"),
+	newUARule(`Version/(\d+)\.(\d+)(?:\.(\d+))?.*Safari/`, "Safari", "$1", "$2", "$3"),
+	newUARule(`MSIE (\d+)\.(\d+)`, "IE", "$1", "$2", ""),
+	newUARule(`Trident/7\.0.*rv:(\d+)\.(\d+)`, "IE", "$1", "$2", ""),
+	newUARule(`(curl|Wget|python-requests|Go-http-client)/(\d+)\.(\d+)(?:\.(\d+))?`, "$1", "$2", "$3", "$4"),
+}
+
+var osRules = []uaRule{
+	newUARule(`Windows NT 10\.0`, "Windows 10", "", "", ""),
+	newUARule(`Windows NT 6\.3`, "Windows 8.1", "", "", ""),
+	newUARule(`Windows NT 6\.2`, "Windows 8", "", "", ""),
+	newUARule(`Windows NT 6\.1`, "Windows 7", "", "", ""),
+	newUARule(`Windows NT 6\.0`, "Windows Vista", "", "", ""),
+	newUARule(`Windows NT 5\.[12]`, "Windows XP", "", "", ""),
+	newUARule(`(?:iPhone|iPad|iPod).*OS (\d+)_(\d+)(?:_(\d+))?`, "iOS", "$1", "$2", "$3"),
+	newUARule(`Mac OS X (\d+)[_.](\d+)(?:[_.](\d+))?`, "Mac OS X", "$1", "$2", "$3"),
+	newUARule(`Android[ /](\d+)(?:\.(\d+))?(?:\.(\d+))?`, "Android", "$1", "$2", "$3"),
+	newUARule(`Ubuntu`, "Ubuntu", "", "", ""),
+	newUARule(`Linux`, "Linux", "", "", ""),
+}
+
+var deviceRules = []uaRule{
+	newUARule(`(?i)bot|spider|crawl|slurp`, "Spider", "", "", ""),
+	newUARule(`iPhone`, "iPhone", "", "", ""),
+	newUARule(`iPad`, "iPad", "", "", ""),
+}
+
+// parseUserAgent returns the user agent details. Unknown values are reported
+// as "Other".
+func parseUserAgent(s string) map[string]string {
+	details := map[string]string{
+		"name":    "Other",
+		"os":      "Other",
+		"os_name": "Other",
+		"device":  "Other",
+	}
+
+	for _, rule := range agentRules {
+		if name, major, minor, patch, ok := rule.match(s); ok {
+			details["name"] = name
+			details["major"] = major
+			details["minor"] = minor
+			details["patch"] = patch
+			break
+		}
+	}
+
+	for _, rule := range osRules {
+		if name, major, minor, patch, ok := rule.match(s); ok {
+			details["os_name"] = name
+			details["os_major"] = major
+			details["os_minor"] = minor
+			details["os"] = name
+			if major != "" {
+				details["os"] = fmt.Sprintf("%s %s", name, major)
+				if minor != "" {
+					details["os"] += "." + minor
+					if patch != "" {
+						details["os"] += "." + patch
+					}
+				}
+			}
+			break
+		}
+	}
+
+	for _, rule := range deviceRules {
+		if name, _, _, _, ok := rule.match(s); ok {
+			details["device"] = name
+			break
+		}
+	}
+
+	return details
+}
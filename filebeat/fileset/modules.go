@@ -10,6 +10,7 @@ import (
 
 	"github.com/pkg/errors"
 
+	"github.com/elastic/beats/filebeat/fileset/ingest"
 	"github.com/elastic/beats/libbeat/common"
 	"github.com/elastic/beats/libbeat/logp"
 	mlimporter "github.com/elastic/beats/libbeat/ml-importer"
@@ -232,6 +233,16 @@ func appendWithoutDuplicates(moduleConfigs []*ModuleConfig, modules []string) ([
 	return moduleConfigs, nil
 }
 
+// EnableBeatPipelines configures all filesets to execute their ingest pipelines
+// in Filebeat. It must be called before GetProspectorConfigs.
+func (reg *ModuleRegistry) EnableBeatPipelines(config ingest.Config) {
+	for _, filesets := range reg.registry {
+		for _, fileset := range filesets {
+			fileset.beatPipeline = &config
+		}
+	}
+}
+
 func (reg *ModuleRegistry) GetProspectorConfigs() ([]*common.Config, error) {
 	result := []*common.Config{}
 	for module, filesets := range reg.registry {
@@ -0,0 +1,215 @@
+// +build !integration
+
+package fileset
+
+import (
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/elastic/beats/filebeat/fileset/ingest"
+	"github.com/elastic/beats/filebeat/harvester/encoding"
+	"github.com/elastic/beats/filebeat/harvester/reader"
+	"github.com/elastic/beats/libbeat/beat"
+	"github.com/elastic/beats/libbeat/common"
+)
+
+// TestBeatPipelines runs the module pipelines in the Beat and compares the
+// results to the expected documents of the module tests.
+func TestBeatPipelines(t *testing.T) {
+	tests := []struct {
+		module, fileset, logfile string
+		// noYear is set if the log timestamps don't contain the year
+		noYear bool
+	}{
+		{"apache2", "access", "test.log", false},
+		{"apache2", "error", "test.log", false},
+		{"system", "auth", "test.log", true},
+		{"system", "syslog", "darwin-syslog-sample.log", true},
+		{"icinga", "main", "test.log", false},
+		{"icinga", "debug", "test.log", false},
+		{"icinga", "startup", "test.log", false},
+		{"kafka", "log", "controller.log", false},
+		{"kafka", "log", "server.log", false},
+		{"postgresql", "log", "postgresql-9.6-debian-with-slowlog.log", false},
+		{"nginx", "access", "test.log", false},
+	}
+
+	for _, test := range tests {
+		name := test.module + "/" + test.fileset + "/" + test.logfile
+
+		fs := getModuleForTesting(t, test.module, test.fileset)
+		if err := fs.Read("6.0.0"); err != nil {
+			t.Fatal(name, err)
+		}
+
+		_, content, err := fs.GetPipeline()
+		if err != nil {
+			t.Fatal(name, err)
+		}
+		pipeline, err := ingest.New(name, content, ingest.Config{})
+		if err != nil {
+			t.Fatal(name, err)
+		}
+
+		logfile := filepath.Join(fs.modulePath, fs.name, "test", test.logfile)
+		expected := readExpectedEvents(t, logfile+"-expected.json")
+
+		events := readTestEvents(t, fs, logfile)
+		if !assert.Len(t, events, len(expected), name) {
+			continue
+		}
+
+		for _, event := range events {
+			offset := event.Fields["offset"].(int64)
+			exp, found := expected[offset]
+			if !assert.True(t, found, "%s: no expected event at offset %d", name, offset) {
+				continue
+			}
+
+			readTimestamp := event.Timestamp
+			event, err := pipeline.Run(event)
+			assert.NoError(t, err, name)
+
+			// events without timestamp in the log keep the read timestamp
+			if !event.Timestamp.Equal(readTimestamp) {
+				expTimestamp, err := time.Parse(time.RFC3339Nano, exp["@timestamp"].(string))
+				if err != nil {
+					t.Fatal(name, err)
+				}
+				timestamp := event.Timestamp.UTC()
+				if test.noYear {
+					timestamp = timestamp.AddDate(expTimestamp.Year()-timestamp.Year(), 0, 0)
+				}
+				assert.Equal(t, expTimestamp, timestamp, "%s: timestamp at offset %d", name, offset)
+			}
+
+			// geoip results depend on the database, which is not available in tests
+			actual := normalize(t, event.Fields[test.module])
+			expectedFields := exp[test.module].(map[string]interface{})
+			removeGeoIP(expectedFields)
+
+			assert.Equal(t, expectedFields, actual, "%s: fields at offset %d", name, offset)
+			if message, exists := exp["message"]; exists {
+				assert.Equal(t, message, event.Fields["message"], "%s: message at offset %d", name, offset)
+			}
+		}
+	}
+}
+
+// readTestEvents reads the log file using the multiline settings of the
+// fileset. The event offsets match the ones of the expected documents.
+func readTestEvents(t *testing.T, fs *Fileset, logfile string) []*beat.Event {
+	cfg, err := fs.getProspectorConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := os.Open(logfile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	codec, err := encoding.Plain(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	encReader, err := reader.NewEncode(f, codec, 16*1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var r reader.Reader = reader.NewStripNewline(encReader)
+
+	if cfg.HasField("multiline") {
+		multilineCfg := reader.MultilineConfig{}
+		sub, err := cfg.Child("multiline", -1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := sub.Unpack(&multilineCfg); err != nil {
+			t.Fatal(err)
+		}
+		r, err = reader.NewMultiline(r, "\n", 10*1024*1024, &multilineCfg)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var (
+		events []*beat.Event
+		offset int64
+	)
+	for {
+		message, err := r.Next()
+		if message.Bytes > 0 {
+			offset += int64(message.Bytes)
+			events = append(events, &beat.Event{
+				Timestamp: time.Now(),
+				Fields: common.MapStr{
+					"message": string(message.Content),
+					"offset":  offset,
+				},
+			})
+		}
+		if err == io.EOF {
+			return events
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// readExpectedEvents returns the expected documents by offset
+func readExpectedEvents(t *testing.T, path string) map[int64]map[string]interface{} {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var docs []struct {
+		Source map[string]interface{} `json:"_source"`
+	}
+	if err := json.Unmarshal(content, &docs); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[int64]map[string]interface{}{}
+	for _, doc := range docs {
+		expected[int64(doc.Source["offset"].(float64))] = doc.Source
+	}
+	return expected
+}
+
+// normalize converts the event fields to the types resulting from JSON decoding
+func normalize(t *testing.T, fields interface{}) interface{} {
+	data, err := json.Marshal(fields)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var result interface{}
+	if err := json.Unmarshal(data, &result); err != nil {
+		t.Fatal(err)
+	}
+	return result
+}
+
+func removeGeoIP(fields map[string]interface{}) {
+	for key, value := range fields {
+		if key == "geoip" {
+			delete(fields, key)
+			continue
+		}
+		if m, ok := value.(map[string]interface{}); ok {
+			removeGeoIP(m)
+		}
+	}
+}
@@ -2,8 +2,13 @@ package harvester
 
 import (
 	uuid "github.com/satori/go.uuid"
+
+	"github.com/elastic/beats/libbeat/monitoring"
 )
 
+// Metrics is the registry of the harvester metrics
+var Metrics = monitoring.Default.NewRegistry("filebeat.harvester")
+
 // Harvester contains all methods which must be supported by each harvester
 // so the registry can be used by the prospector.
 type Harvester interface {
@@ -0,0 +1,121 @@
+package harvester
+
+import (
+	"sync"
+	"time"
+
+	"github.com/elastic/beats/libbeat/monitoring"
+)
+
+// RateLimitConfig defines the maximum throughput of events. A limit of 0
+// disables it.
+type RateLimitConfig struct {
+	EventsPerSecond float64 `config:"events_per_second" validate:"min=0"`
+	BytesPerSecond  float64 `config:"bytes_per_second" validate:"min=0"`
+}
+
+// Enabled returns true if any limit is configured
+func (c *RateLimitConfig) Enabled() bool {
+	return c.EventsPerSecond > 0 || c.BytesPerSecond > 0
+}
+
+// RateLimiter limits the throughput of events with token buckets. The buckets
+// allow bursts of up to one second of throughput. It can be shared between
+// harvesters, which are then served in the order they request to send.
+type RateLimiter struct {
+	mutex  sync.Mutex
+	events *bucket
+	bytes  *bucket
+
+	// time spent waiting in milliseconds
+	throttled *monitoring.Int
+}
+
+// bucket is a token bucket. Tokens can be borrowed, so events larger than the
+// capacity are delayed instead of being blocked forever.
+type bucket struct {
+	rate   float64
+	tokens float64
+	last   time.Time
+}
+
+// NewRateLimiter creates a new limiter. It returns nil if no limit is
+// configured. The time spent waiting is added to the throttled metric.
+func NewRateLimiter(config RateLimitConfig, throttled *monitoring.Int) *RateLimiter {
+	if !config.Enabled() {
+		return nil
+	}
+
+	now := time.Now()
+	return &RateLimiter{
+		events:    newBucket(config.EventsPerSecond, now),
+		bytes:     newBucket(config.BytesPerSecond, now),
+		throttled: throttled,
+	}
+}
+
+func newBucket(rate float64, now time.Time) *bucket {
+	if rate <= 0 {
+		return nil
+	}
+	return &bucket{rate: rate, tokens: rate, last: now}
+}
+
+// take removes n tokens and returns the time to wait until the tokens are
+// available.
+func (b *bucket) take(n float64, now time.Time) time.Duration {
+	if b == nil {
+		return 0
+	}
+
+	b.tokens += now.Sub(b.last).Seconds() * b.rate
+	if b.tokens > b.rate {
+		b.tokens = b.rate
+	}
+	b.last = now
+
+	b.tokens -= n
+	if b.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-b.tokens / b.rate * float64(time.Second))
+}
+
+// reserve takes the tokens of the events and returns the time to wait
+// before sending them.
+func (l *RateLimiter) reserve(events, bytes int, now time.Time) time.Duration {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	wait := l.events.take(float64(events), now)
+	if w := l.bytes.take(float64(bytes), now); w > wait {
+		wait = w
+	}
+	return wait
+}
+
+// Wait blocks until the events can be sent. It returns false if done is
+// closed while waiting.
+func (l *RateLimiter) Wait(events, bytes int, done <-chan struct{}) bool {
+	wait := l.reserve(events, bytes, time.Now())
+	if wait <= 0 {
+		return true
+	}
+
+	start := time.Now()
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+
+	defer func() {
+		if l.throttled != nil {
+			l.throttled.Add(int64(time.Since(start) / time.Millisecond))
+		}
+	}()
+
+	select {
+	case <-timer.C:
+		return true
+	case <-done:
+		return false
+	}
+}
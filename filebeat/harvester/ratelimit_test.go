@@ -0,0 +1,66 @@
+// +build !integration
+
+package harvester
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/elastic/beats/libbeat/monitoring"
+)
+
+func TestRateLimiterDisabled(t *testing.T) {
+	assert.Nil(t, NewRateLimiter(RateLimitConfig{}, nil))
+}
+
+func TestBucket(t *testing.T) {
+	now := time.Now()
+	b := newBucket(10, now)
+
+	// Burst of one second
+	for i := 0; i < 10; i++ {
+		assert.Equal(t, time.Duration(0), b.take(1, now))
+	}
+	assert.Equal(t, 100*time.Millisecond, b.take(1, now))
+
+	// Tokens are borrowed
+	assert.Equal(t, 200*time.Millisecond, b.take(1, now))
+
+	// Refilled over time, up to the capacity
+	now = now.Add(10 * time.Second)
+	assert.Equal(t, time.Duration(0), b.take(10, now))
+	assert.Equal(t, 100*time.Millisecond, b.take(1, now))
+
+	// Events larger than the capacity are delayed
+	now = now.Add(10 * time.Second)
+	assert.Equal(t, 2*time.Second, b.take(30, now))
+}
+
+func TestRateLimiterReserve(t *testing.T) {
+	l := NewRateLimiter(RateLimitConfig{EventsPerSecond: 100, BytesPerSecond: 10}, nil)
+	now := time.Now()
+
+	// The bytes limit is exceeded first
+	assert.Equal(t, time.Duration(0), l.reserve(1, 10, now))
+	assert.Equal(t, 500*time.Millisecond, l.reserve(1, 5, now))
+}
+
+func TestRateLimiterWait(t *testing.T) {
+	throttled := monitoring.NewInt(monitoring.NewRegistry(), "throttled")
+	l := NewRateLimiter(RateLimitConfig{EventsPerSecond: 20}, throttled)
+	done := make(chan struct{})
+
+	start := time.Now()
+	for i := 0; i < 22; i++ {
+		assert.True(t, l.Wait(1, 0, done))
+	}
+	assert.True(t, time.Since(start) >= 90*time.Millisecond)
+	assert.True(t, throttled.Get() > 0)
+
+	// Waiting is interrupted once done is closed
+	l.reserve(100, 0, time.Now())
+	close(done)
+	assert.False(t, l.Wait(1, 0, done))
+}
@@ -0,0 +1,124 @@
+package reader
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+
+	"github.com/elastic/beats/libbeat/common"
+	"github.com/elastic/beats/libbeat/logp"
+)
+
+// utf8BOM is removed from the header line, as the header is read before the
+// harvester strips it from the first line of the file.
+var utf8BOM = []byte("\xef\xbb\xbf")
+
+// CSV reader decodes the content of the messages as a CSV record. The values
+// are named by the configured columns, or by the header line of the file.
+type CSV struct {
+	reader Reader
+	cfg    *CSVConfig
+	header []string
+}
+
+// NewCSV creates a new reader decoding CSV records.
+func NewCSV(r Reader, cfg *CSVConfig) *CSV {
+	return &CSV{reader: r, cfg: cfg}
+}
+
+// SetHeader sets the header line of the file. It must be called if the file
+// is not read from the beginning, as the header line is not read in this case.
+func (r *CSV) SetHeader(line []byte) error {
+	header, err := r.parse(bytes.TrimPrefix(line, utf8BOM))
+	if err != nil {
+		return err
+	}
+	r.header = header
+	return nil
+}
+
+// Next decodes the CSV record of the next message. If header is enabled, the
+// first message is used as header and returned without content, so it's not
+// published.
+func (r *CSV) Next() (Message, error) {
+	message, err := r.reader.Next()
+	if err != nil {
+		return message, err
+	}
+
+	if r.cfg.Header && r.header == nil {
+		if err := r.SetHeader(message.Content); err != nil {
+			logp.Err("Error decoding CSV header: %v", err)
+		}
+		if r.header == nil {
+			r.header = []string{}
+		}
+		message.Content = nil
+		message.Fields = nil
+		return message, nil
+	}
+
+	fields := r.decode(message.Content)
+	if fields == nil {
+		return message, nil
+	}
+
+	if r.cfg.KeysUnderRoot {
+		message.AddFields(fields)
+	} else {
+		message.AddFields(common.MapStr{"csv": fields})
+	}
+	return message, nil
+}
+
+// decode returns the values of the record by column name. Values of columns
+// without name are named by their position, starting at column1.
+func (r *CSV) decode(text []byte) common.MapStr {
+	values, err := r.parse(text)
+	if err != nil {
+		logp.Err("Error decoding CSV: %v", err)
+		if r.cfg.AddErrorKey {
+			return common.MapStr{"error": createCSVError(fmt.Sprintf("Error decoding CSV: %v", err))}
+		}
+		return nil
+	}
+
+	names := r.cfg.Columns
+	if len(names) == 0 {
+		names = r.header
+	}
+
+	fields := common.MapStr{}
+	for i, value := range values {
+		name := ""
+		if i < len(names) {
+			name = names[i]
+		}
+		if name == "" {
+			name = fmt.Sprintf("column%d", i+1)
+		}
+		fields[name] = value
+	}
+
+	if r.cfg.AddErrorKey && len(names) > 0 && len(values) != len(names) {
+		fields["error"] = createCSVError(fmt.Sprintf("Record has %d values, expected %d", len(values), len(names)))
+	}
+	return fields
+}
+
+func (r *CSV) parse(text []byte) ([]string, error) {
+	if len(text) == 0 {
+		return nil, fmt.Errorf("empty record")
+	}
+
+	reader := csv.NewReader(bytes.NewReader(text))
+	reader.Comma = r.cfg.separator()
+	reader.LazyQuotes = r.cfg.LazyQuotes
+	reader.TrimLeadingSpace = r.cfg.TrimLeadingSpace
+	reader.FieldsPerRecord = -1
+	return reader.Read()
+}
+
+func createCSVError(message string) common.MapStr {
+	return common.MapStr{"message": message, "type": "csv"}
+}
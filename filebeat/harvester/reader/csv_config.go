@@ -0,0 +1,40 @@
+package reader
+
+import (
+	"fmt"
+	"unicode/utf8"
+)
+
+type CSVConfig struct {
+	Separator        string   `config:"separator"`
+	LazyQuotes       bool     `config:"lazy_quotes"`
+	TrimLeadingSpace bool     `config:"trim_leading_space"`
+	Header           bool     `config:"header"`
+	Columns          []string `config:"columns"`
+	KeysUnderRoot    bool     `config:"keys_under_root"`
+	AddErrorKey      bool     `config:"add_error_key"`
+}
+
+func (c *CSVConfig) Validate() error {
+	if c.Separator == "" {
+		c.Separator = ","
+	}
+
+	r, size := utf8.DecodeRuneInString(c.Separator)
+	if size != len(c.Separator) || r == utf8.RuneError {
+		return fmt.Errorf("CSV separator must be a single character: %q", c.Separator)
+	}
+	if r == '"' || r == '\r' || r == '\n' {
+		return fmt.Errorf("Invalid CSV separator: %q", c.Separator)
+	}
+	return nil
+}
+
+// separator returns the separator as rune
+func (c *CSVConfig) separator() rune {
+	if c.Separator == "" {
+		return ','
+	}
+	r, _ := utf8.DecodeRuneInString(c.Separator)
+	return r
+}
@@ -0,0 +1,137 @@
+// +build !integration
+
+package reader
+
+import (
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/elastic/beats/libbeat/common"
+)
+
+// sliceReader returns a message for each of its lines
+type sliceReader struct {
+	lines []string
+}
+
+func (r *sliceReader) Next() (Message, error) {
+	if len(r.lines) == 0 {
+		return Message{}, io.EOF
+	}
+	line := r.lines[0]
+	r.lines = r.lines[1:]
+	return Message{Content: []byte(line), Bytes: len(line) + 1}, nil
+}
+
+func readAll(t *testing.T, r Reader) []Message {
+	var messages []Message
+	for {
+		message, err := r.Next()
+		if err == io.EOF {
+			return messages
+		}
+		if !assert.NoError(t, err) {
+			return messages
+		}
+		messages = append(messages, message)
+	}
+}
+
+func TestCSVConfigValidate(t *testing.T) {
+	config := CSVConfig{}
+	assert.NoError(t, config.Validate())
+	assert.Equal(t, ",", config.Separator)
+
+	for _, separator := range []string{";", "\t", "|", "§"} {
+		config = CSVConfig{Separator: separator}
+		assert.NoError(t, config.Validate(), separator)
+	}
+
+	for _, separator := range []string{";;", "\"", "\n"} {
+		config = CSVConfig{Separator: separator}
+		assert.Error(t, config.Validate(), separator)
+	}
+}
+
+func TestCSVColumns(t *testing.T) {
+	config := &CSVConfig{
+		Separator: ";",
+		Columns:   []string{"name", "", "count"},
+	}
+	r := NewCSV(&sliceReader{lines: []string{
+		`alice;"quoted; value";3`,
+		`bob;x;4;extra`,
+	}}, config)
+
+	messages := readAll(t, r)
+	if assert.Len(t, messages, 2) {
+		assert.Equal(t, `alice;"quoted; value";3`, string(messages[0].Content))
+		assert.Equal(t, common.MapStr{"csv": common.MapStr{
+			"name":    "alice",
+			"column2": "quoted; value",
+			"count":   "3",
+		}}, messages[0].Fields)
+		assert.Equal(t, common.MapStr{"csv": common.MapStr{
+			"name":    "bob",
+			"column2": "x",
+			"count":   "4",
+			"column4": "extra",
+		}}, messages[1].Fields)
+	}
+}
+
+func TestCSVHeader(t *testing.T) {
+	config := &CSVConfig{Header: true, KeysUnderRoot: true, TrimLeadingSpace: true}
+	r := NewCSV(&sliceReader{lines: []string{
+		"\xef\xbb\xbfid, level",
+		"1, info",
+	}}, config)
+
+	messages := readAll(t, r)
+	if assert.Len(t, messages, 2) {
+		// The header is not published, but still counts for the offset
+		assert.True(t, messages[0].IsEmpty())
+		assert.NotZero(t, messages[0].Bytes)
+		assert.Equal(t, common.MapStr{"id": "1", "level": "info"}, messages[1].Fields)
+	}
+}
+
+func TestCSVSetHeader(t *testing.T) {
+	config := &CSVConfig{Header: true}
+	r := NewCSV(&sliceReader{lines: []string{"1,info"}}, config)
+	assert.NoError(t, r.SetHeader([]byte("id,level")))
+
+	messages := readAll(t, r)
+	if assert.Len(t, messages, 1) {
+		assert.Equal(t, common.MapStr{"csv": common.MapStr{"id": "1", "level": "info"}}, messages[0].Fields)
+	}
+}
+
+func TestCSVErrors(t *testing.T) {
+	config := &CSVConfig{Columns: []string{"a", "b"}, AddErrorKey: true}
+	r := NewCSV(&sliceReader{lines: []string{
+		`1,"unterminated`,
+		`1`,
+	}}, config)
+
+	messages := readAll(t, r)
+	if assert.Len(t, messages, 2) {
+		fields := messages[0].Fields["csv"].(common.MapStr)
+		assert.Contains(t, fields, "error")
+		assert.NotContains(t, fields, "a")
+
+		fields = messages[1].Fields["csv"].(common.MapStr)
+		assert.Equal(t, "1", fields["a"])
+		assert.Equal(t, common.MapStr{"message": "Record has 1 values, expected 2", "type": "csv"}, fields["error"])
+	}
+
+	config.LazyQuotes = true
+	config.AddErrorKey = false
+	r = NewCSV(&sliceReader{lines: []string{`1,a "quoted" b`}}, config)
+	messages = readAll(t, r)
+	if assert.Len(t, messages, 1) {
+		assert.Equal(t, common.MapStr{"csv": common.MapStr{"a": "1", "b": `a "quoted" b`}}, messages[0].Fields)
+	}
+}
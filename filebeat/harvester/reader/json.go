@@ -25,33 +25,39 @@ func NewJSON(r Reader, cfg *JSONConfig) *JSON {
 // decodeJSON unmarshals the text parameter into a MapStr and
 // returns the new text column if one was requested.
 func (r *JSON) decodeJSON(text []byte) ([]byte, common.MapStr) {
+	return DecodeJSON(text, r.cfg)
+}
+
+// DecodeJSON unmarshals the text parameter into a MapStr using the given
+// config and returns the new text column if one was requested.
+func DecodeJSON(text []byte, cfg *JSONConfig) ([]byte, common.MapStr) {
 	var jsonFields map[string]interface{}
 
 	err := unmarshal(text, &jsonFields)
 	if err != nil || jsonFields == nil {
 		logp.Err("Error decoding JSON: %v", err)
-		if r.cfg.AddErrorKey {
+		if cfg.AddErrorKey {
 			jsonFields = common.MapStr{"error": createJSONError(fmt.Sprintf("Error decoding JSON: %v", err))}
 		}
 		return text, jsonFields
 	}
 
-	if len(r.cfg.MessageKey) == 0 {
+	if len(cfg.MessageKey) == 0 {
 		return []byte(""), jsonFields
 	}
 
-	textValue, ok := jsonFields[r.cfg.MessageKey]
+	textValue, ok := jsonFields[cfg.MessageKey]
 	if !ok {
-		if r.cfg.AddErrorKey {
-			jsonFields["error"] = createJSONError(fmt.Sprintf("Key '%s' not found", r.cfg.MessageKey))
+		if cfg.AddErrorKey {
+			jsonFields["error"] = createJSONError(fmt.Sprintf("Key '%s' not found", cfg.MessageKey))
 		}
 		return []byte(""), jsonFields
 	}
 
 	textString, ok := textValue.(string)
 	if !ok {
-		if r.cfg.AddErrorKey {
-			jsonFields["error"] = createJSONError(fmt.Sprintf("Value of key '%s' is not a string", r.cfg.MessageKey))
+		if cfg.AddErrorKey {
+			jsonFields["error"] = createJSONError(fmt.Sprintf("Value of key '%s' is not a string", cfg.MessageKey))
 		}
 		return []byte(""), jsonFields
 	}
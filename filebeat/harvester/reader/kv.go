@@ -0,0 +1,170 @@
+package reader
+
+import (
+	"bytes"
+	"strings"
+
+	"github.com/elastic/beats/libbeat/common"
+)
+
+// KeyValue reader decodes the content of the messages as key=value pairs,
+// like logfmt formatted logs. Values can be quoted with double or single
+// quotes to contain the separators.
+type KeyValue struct {
+	reader  Reader
+	cfg     *KeyValueConfig
+	include map[string]struct{}
+	exclude map[string]struct{}
+}
+
+// NewKeyValue creates a new reader decoding key=value pairs.
+func NewKeyValue(r Reader, cfg *KeyValueConfig) *KeyValue {
+	return &KeyValue{
+		reader:  r,
+		cfg:     cfg,
+		include: toSet(cfg.IncludeKeys),
+		exclude: toSet(cfg.ExcludeKeys),
+	}
+}
+
+func toSet(keys []string) map[string]struct{} {
+	if len(keys) == 0 {
+		return nil
+	}
+	set := make(map[string]struct{}, len(keys))
+	for _, key := range keys {
+		set[key] = struct{}{}
+	}
+	return set
+}
+
+// Next decodes the key=value pairs of the next message.
+func (r *KeyValue) Next() (Message, error) {
+	message, err := r.reader.Next()
+	if err != nil {
+		return message, err
+	}
+
+	fields := r.decode(string(message.Content))
+	if len(fields) == 0 {
+		return message, nil
+	}
+
+	if r.cfg.KeysUnderRoot {
+		message.AddFields(fields)
+	} else {
+		message.AddFields(common.MapStr{"kv": fields})
+	}
+	return message, nil
+}
+
+// decode returns the pairs of the text. Fields without value separator or
+// with an empty key are ignored. If a key is repeated, the last value is used.
+func (r *KeyValue) decode(text string) common.MapStr {
+	fields := common.MapStr{}
+	for _, field := range r.split(text) {
+		idx := strings.Index(field, r.cfg.ValueSplit)
+		if idx < 0 {
+			continue
+		}
+
+		key := field[:idx]
+		if r.cfg.TrimKey != "" {
+			key = strings.Trim(key, r.cfg.TrimKey)
+		}
+		if key == "" || !r.accept(key) {
+			continue
+		}
+
+		value := field[idx+len(r.cfg.ValueSplit):]
+		if r.cfg.TrimValue != "" {
+			value = strings.Trim(value, r.cfg.TrimValue)
+		}
+		fields[key] = unquote(value)
+	}
+	return fields
+}
+
+func (r *KeyValue) accept(key string) bool {
+	if r.include != nil {
+		if _, ok := r.include[key]; !ok {
+			return false
+		}
+	}
+	_, excluded := r.exclude[key]
+	return !excluded
+}
+
+// split splits the text at the field separators which are not part of a
+// quoted value. A quote only starts a quoted value at the beginning of a value,
+// optionally after blanks, so quotes within unquoted values are kept as is.
+func (r *KeyValue) split(text string) []string {
+	fieldSplit := r.cfg.FieldSplit
+
+	var fields []string
+	start := 0
+	var quote byte
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+
+		if quote != 0 {
+			switch c {
+			case '\\':
+				i++
+			case quote:
+				quote = 0
+			}
+			continue
+		}
+
+		if (c == '"' || c == '\'') && r.valueStart(text[start:i]) {
+			quote = c
+			continue
+		}
+
+		if strings.HasPrefix(text[i:], fieldSplit) {
+			if i > start {
+				fields = append(fields, text[start:i])
+			}
+			i += len(fieldSplit) - 1
+			start = i + 1
+		}
+	}
+	if start < len(text) {
+		fields = append(fields, text[start:])
+	}
+	return fields
+}
+
+// valueStart checks if the field read so far ends with the value separator,
+// optionally followed by blanks.
+func (r *KeyValue) valueStart(field string) bool {
+	return strings.HasSuffix(field, r.cfg.ValueSplit) ||
+		strings.HasSuffix(strings.TrimRight(field, " \t"), r.cfg.ValueSplit)
+}
+
+// unquote removes the quotes around a value and the escaping backslashes
+// within it.
+func unquote(value string) string {
+	if len(value) < 2 {
+		return value
+	}
+	quote := value[0]
+	if (quote != '"' && quote != '\'') || value[len(value)-1] != quote {
+		return value
+	}
+
+	value = value[1 : len(value)-1]
+	if strings.IndexByte(value, '\\') < 0 {
+		return value
+	}
+
+	var buf bytes.Buffer
+	for i := 0; i < len(value); i++ {
+		if value[i] == '\\' && i+1 < len(value) && (value[i+1] == quote || value[i+1] == '\\') {
+			i++
+		}
+		buf.WriteByte(value[i])
+	}
+	return buf.String()
+}
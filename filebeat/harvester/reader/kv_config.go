@@ -0,0 +1,27 @@
+package reader
+
+import "fmt"
+
+type KeyValueConfig struct {
+	FieldSplit    string   `config:"field_split"`
+	ValueSplit    string   `config:"value_split"`
+	TrimKey       string   `config:"trim_key"`
+	TrimValue     string   `config:"trim_value"`
+	IncludeKeys   []string `config:"include_keys"`
+	ExcludeKeys   []string `config:"exclude_keys"`
+	KeysUnderRoot bool     `config:"keys_under_root"`
+}
+
+func (c *KeyValueConfig) Validate() error {
+	if c.FieldSplit == "" {
+		c.FieldSplit = " "
+	}
+	if c.ValueSplit == "" {
+		c.ValueSplit = "="
+	}
+
+	if c.FieldSplit == c.ValueSplit {
+		return fmt.Errorf("field_split and value_split must be different: %q", c.FieldSplit)
+	}
+	return nil
+}
@@ -0,0 +1,91 @@
+// +build !integration
+
+package reader
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/elastic/beats/libbeat/common"
+)
+
+func TestKeyValueConfigValidate(t *testing.T) {
+	config := KeyValueConfig{}
+	assert.NoError(t, config.Validate())
+	assert.Equal(t, " ", config.FieldSplit)
+	assert.Equal(t, "=", config.ValueSplit)
+
+	config = KeyValueConfig{FieldSplit: ":", ValueSplit: ":"}
+	assert.Error(t, config.Validate())
+}
+
+func TestKeyValueDecode(t *testing.T) {
+	tests := []struct {
+		name   string
+		config KeyValueConfig
+		input  string
+		output common.MapStr
+	}{
+		{
+			name:   "logfmt",
+			input:  `level=info msg="request done" path=/ duration=1.5ms`,
+			output: common.MapStr{"level": "info", "msg": "request done", "path": "/", "duration": "1.5ms"},
+		},
+		{
+			name:   "quotes and escapes",
+			input:  `a='single quoted' b="escaped \" quote" c=it's d="" e`,
+			output: common.MapStr{"a": "single quoted", "b": `escaped " quote`, "c": "it's", "d": ""},
+		},
+		{
+			name:   "repeated separators and empty keys",
+			input:  `  a=1   =2 b==3 `,
+			output: common.MapStr{"a": "1", "b": "=3"},
+		},
+		{
+			name:   "custom separators and trimming",
+			config: KeyValueConfig{FieldSplit: ",", ValueSplit: ":", TrimKey: " ", TrimValue: " []"},
+			input:  `user: alice, roles: [admin], note: "a, b"`,
+			output: common.MapStr{"user": "alice", "roles": "admin", "note": "a, b"},
+		},
+		{
+			name:   "include keys",
+			config: KeyValueConfig{IncludeKeys: []string{"a", "c"}},
+			input:  `a=1 b=2 c=3`,
+			output: common.MapStr{"a": "1", "c": "3"},
+		},
+		{
+			name:   "exclude keys",
+			config: KeyValueConfig{ExcludeKeys: []string{"b"}},
+			input:  `a=1 b=2 c=3`,
+			output: common.MapStr{"a": "1", "c": "3"},
+		},
+	}
+
+	for _, test := range tests {
+		config := test.config
+		assert.NoError(t, config.Validate())
+		r := NewKeyValue(nil, &config)
+		assert.Equal(t, test.output, r.decode(test.input), test.name)
+	}
+}
+
+func TestKeyValueNext(t *testing.T) {
+	config := &KeyValueConfig{}
+	assert.NoError(t, config.Validate())
+	r := NewKeyValue(&sliceReader{lines: []string{"a=1", "no pairs"}}, config)
+
+	messages := readAll(t, r)
+	if assert.Len(t, messages, 2) {
+		assert.Equal(t, common.MapStr{"kv": common.MapStr{"a": "1"}}, messages[0].Fields)
+		assert.Nil(t, messages[1].Fields)
+		assert.Equal(t, "no pairs", string(messages[1].Content))
+	}
+
+	config.KeysUnderRoot = true
+	r = NewKeyValue(&sliceReader{lines: []string{"a=1"}}, config)
+	messages = readAll(t, r)
+	if assert.Len(t, messages, 1) {
+		assert.Equal(t, common.MapStr{"a": "1"}, messages[0].Fields)
+	}
+}
@@ -3,6 +3,8 @@ package reader
 import (
 	"errors"
 	"fmt"
+	"regexp"
+	"sort"
 	"time"
 
 	"github.com/elastic/beats/libbeat/common/match"
@@ -11,8 +13,9 @@ import (
 
 // MultiLine reader combining multiple line events into one multi-line event.
 //
-// Lines to be combined are matched by some configurable predicate using
-// regular expression.
+// Lines to be combined are matched by some configurable policy: a predicate
+// using regular expression (pattern), a fixed number of lines (count) or
+// lines continued while they match a regular expression (while_pattern).
 //
 // The maximum number of bytes and lines to be returned is fully configurable.
 // Even if limits are reached subsequent lines are matched, until event is
@@ -21,17 +24,22 @@ import (
 // Errors will force the multiline reader to return the currently active
 // multiline event first and finally return the actual error on next call to Next.
 type Multiline struct {
-	reader       Reader
-	pred         matcher
-	flushMatcher *match.Matcher
-	maxBytes     int // bytes stored in content
-	maxLines     int
-	separator    []byte
-	last         []byte
-	numLines     int
-	err          error // last seen error
-	state        func(*Multiline) (Message, error)
-	message      Message
+	lineBuffer
+	reader Reader
+	policy policy
+	err    error // last seen error
+	state  func(*Multiline) (Message, error)
+}
+
+// lineBuffer collects the lines of a single multiline event
+type lineBuffer struct {
+	maxBytes  int // bytes stored in content
+	maxLines  int
+	separator []byte
+	last      []byte
+	numLines  int // number of lines stored in content
+	lineCount int // number of lines read, including lines dropped because of limits
+	message   Message
 }
 
 const (
@@ -46,6 +54,17 @@ const (
 // to find start and end of multiline events in stream of line events.
 type matcher func(last, current []byte) bool
 
+// policy decides which lines are combined into one multiline event.
+type policy interface {
+	// continues reports whether line is part of the event with the given last
+	// line and number of lines read so far.
+	continues(last, line []byte, lineCount int) bool
+
+	// complete reports whether the event is finished after line was added
+	// as the lineCount-th line.
+	complete(line []byte, lineCount int) bool
+}
+
 var (
 	sigMultilineTimeout = errors.New("multline timeout")
 )
@@ -57,28 +76,12 @@ func NewMultiline(
 	separator string,
 	maxBytes int,
 	config *MultilineConfig,
-) (*Multiline, error) {
-	types := map[string]func(match.Matcher) (matcher, error){
-		"before": beforeMatcher,
-		"after":  afterMatcher,
-	}
-
-	matcherType, ok := types[config.Match]
-	if !ok {
-		return nil, fmt.Errorf("unknown matcher type: %s", config.Match)
-	}
-
-	matcher, err := matcherType(*config.Pattern)
+) (Reader, error) {
+	policy, err := newPolicy(config)
 	if err != nil {
 		return nil, err
 	}
 
-	flushMatcher := config.FlushPattern
-
-	if config.Negate {
-		matcher = negatedMatcher(matcher)
-	}
-
 	maxLines := defaultMaxLines
 	if config.MaxLines != nil {
 		maxLines = *config.MaxLines
@@ -92,23 +95,49 @@ func NewMultiline(
 		}
 	}
 
+	buffer := lineBuffer{
+		maxBytes:  maxBytes,
+		maxLines:  maxLines,
+		separator: []byte(separator),
+		message:   Message{},
+	}
+
+	if config.KeyPattern != "" {
+		return newKeyedMultiline(reader, buffer, policy, config.KeyPattern, timeout)
+	}
+
 	if timeout > 0 {
 		reader = NewTimeout(reader, sigMultilineTimeout, timeout)
 	}
 
 	mlr := &Multiline{
-		reader:       reader,
-		pred:         matcher,
-		flushMatcher: flushMatcher,
-		state:        (*Multiline).readFirst,
-		maxBytes:     maxBytes,
-		maxLines:     maxLines,
-		separator:    []byte(separator),
-		message:      Message{},
+		lineBuffer: buffer,
+		reader:     reader,
+		policy:     policy,
+		state:      (*Multiline).readFirst,
 	}
 	return mlr, nil
 }
 
+func newPolicy(config *MultilineConfig) (policy, error) {
+	switch config.Type {
+	case "", patternMode:
+		return newPatternPolicy(config)
+	case countMode:
+		if config.LinesCount <= 0 {
+			return nil, fmt.Errorf("count_lines must be > 0 in %s mode", countMode)
+		}
+		return countPolicy(config.LinesCount), nil
+	case whilePatternMode:
+		if config.Pattern == nil {
+			return nil, fmt.Errorf("pattern required in %s mode", whilePatternMode)
+		}
+		return &whilePatternPolicy{pattern: *config.Pattern, negate: config.Negate}, nil
+	default:
+		return nil, fmt.Errorf("unknown multiline type: %s", config.Type)
+	}
+}
+
 // Next returns next multi-line event.
 func (mlr *Multiline) Next() (Message, error) {
 	return mlr.state(mlr)
@@ -136,6 +165,9 @@ func (mlr *Multiline) readFirst() (Message, error) {
 		// Start new multiline event
 		mlr.clear()
 		mlr.load(message)
+		if mlr.policy.complete(message.Content, mlr.lineCount) {
+			return mlr.finalize(), nil
+		}
 		mlr.setState((*Multiline).readNext)
 		return mlr.readNext()
 	}
@@ -177,7 +209,7 @@ func (mlr *Multiline) readNext() (Message, error) {
 
 			// handle error with some content being returned by reader and
 			// line matching multiline criteria or no multiline started yet
-			if mlr.message.Bytes == 0 || mlr.pred(mlr.last, message.Content) {
+			if mlr.message.Bytes == 0 || mlr.policy.continues(mlr.last, message.Content, mlr.lineCount) {
 				mlr.addLine(message)
 
 				// return multiline and error on next read
@@ -195,32 +227,38 @@ func (mlr *Multiline) readNext() (Message, error) {
 			return msg, nil
 		}
 
-		// handle case when endPattern is reached
-		if mlr.flushMatcher != nil {
-			endPatternReached := (mlr.flushMatcher.Match(message.Content))
-
-			if endPatternReached == true {
-				// return collected multiline event and
-				// empty buffer for new multiline event
-				mlr.addLine(message)
-				msg := mlr.finalize()
-				mlr.resetState()
-				return msg, nil
-			}
-		}
-
-		// if predicate does not match current multiline -> return multiline event
-		if mlr.message.Bytes > 0 && !mlr.pred(mlr.last, message.Content) {
+		// if policy does not match current multiline -> return multiline event
+		if mlr.message.Bytes > 0 && !mlr.policy.continues(mlr.last, message.Content, mlr.lineCount) {
 			msg := mlr.finalize()
 			mlr.load(message)
+			if mlr.policy.complete(message.Content, mlr.lineCount) {
+				// loaded line is an event on its own, return it on next call
+				mlr.setState((*Multiline).readComplete)
+			}
 			return msg, nil
 		}
 
 		// add line to current multiline event
 		mlr.addLine(message)
+
+		// handle case when end of event is reached
+		if mlr.policy.complete(message.Content, mlr.lineCount) {
+			// return collected multiline event and
+			// empty buffer for new multiline event
+			msg := mlr.finalize()
+			mlr.resetState()
+			return msg, nil
+		}
 	}
 }
 
+// readComplete returns the already completed multiline event
+func (mlr *Multiline) readComplete() (Message, error) {
+	msg := mlr.finalize()
+	mlr.resetState()
+	return msg, nil
+}
+
 // readFailed returns empty message and error and resets line reader
 func (mlr *Multiline) readFailed() (Message, error) {
 	err := mlr.err
@@ -231,7 +269,7 @@ func (mlr *Multiline) readFailed() (Message, error) {
 
 // load loads the reader with the given message. It is recommend to either
 // run clear or finalize before.
-func (mlr *Multiline) load(m Message) {
+func (mlr *lineBuffer) load(m Message) {
 	mlr.addLine(m)
 	// Timestamp of first message is taken as overall timestamp
 	mlr.message.Ts = m.Ts
@@ -239,15 +277,15 @@ func (mlr *Multiline) load(m Message) {
 }
 
 // clearBuffer resets the reader buffer variables
-func (mlr *Multiline) clear() {
+func (mlr *lineBuffer) clear() {
 	mlr.message = Message{}
 	mlr.last = nil
 	mlr.numLines = 0
-	mlr.err = nil
+	mlr.lineCount = 0
 }
 
 // finalize writes the existing content into the returned message and resets all reader variables.
-func (mlr *Multiline) finalize() Message {
+func (mlr *lineBuffer) finalize() Message {
 	// Copy message from existing content
 	msg := mlr.message
 	mlr.clear()
@@ -257,10 +295,11 @@ func (mlr *Multiline) finalize() Message {
 // addLine adds the read content to the message
 // The content is only added if maxBytes and maxLines is not exceed. In case one of the
 // two is exceeded, addLine keeps processing but does not add it to the content.
-func (mlr *Multiline) addLine(m Message) {
+func (mlr *lineBuffer) addLine(m Message) {
 	if m.Bytes <= 0 {
 		return
 	}
+	mlr.lineCount++
 
 	sz := len(mlr.message.Content)
 	addSeparator := len(mlr.message.Content) > 0 && len(mlr.separator) > 0
@@ -301,6 +340,238 @@ func (mlr *Multiline) setState(next func(mlr *Multiline) (Message, error)) {
 	mlr.state = next
 }
 
+// keyedMultiline combines the lines of interleaved writers into multiline
+// events. Every line is assigned to a key, like a thread or request ID,
+// extracted by a regular expression. The lines of each key are combined using
+// the configured policy. Lines without key belong to the key of the previous
+// line. Events of keys not receiving new lines within the timeout are flushed.
+type keyedMultiline struct {
+	reader  Reader
+	buffer  lineBuffer // template for new per key buffers
+	policy  policy
+	key     *regexp.Regexp
+	timeout time.Duration
+	groups  map[string]*keyedGroup
+	lastKey string
+	ready   []Message // finished events not yet returned
+	err     error     // error to be returned after all events are flushed
+}
+
+type keyedGroup struct {
+	lineBuffer
+	updated time.Time
+}
+
+func newKeyedMultiline(
+	reader Reader,
+	buffer lineBuffer,
+	policy policy,
+	pattern string,
+	timeout time.Duration,
+) (*keyedMultiline, error) {
+	key, err := compileKeyPattern(pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	if timeout > 0 {
+		reader = NewTimeout(reader, sigMultilineTimeout, timeout)
+	}
+
+	return &keyedMultiline{
+		reader:  reader,
+		buffer:  buffer,
+		policy:  policy,
+		key:     key,
+		timeout: timeout,
+		groups:  map[string]*keyedGroup{},
+	}, nil
+}
+
+// Next returns the next finished multi-line event of any key.
+func (k *keyedMultiline) Next() (Message, error) {
+	for {
+		if len(k.ready) > 0 {
+			msg := k.ready[0]
+			k.ready = k.ready[1:]
+			return msg, nil
+		}
+
+		if k.err != nil {
+			err := k.err
+			k.err = nil
+			return Message{}, err
+		}
+
+		message, err := k.reader.Next()
+		if err == sigMultilineTimeout {
+			k.flush(k.expired)
+			continue
+		}
+		if err != nil {
+			// return all collected events first and the error on the next call
+			k.addLine(message)
+			k.flush(func(*keyedGroup) bool { return true })
+			k.err = err
+			continue
+		}
+
+		k.addLine(message)
+		k.flush(k.expired)
+	}
+}
+
+func (k *keyedMultiline) addLine(m Message) {
+	if m.Bytes == 0 {
+		return
+	}
+
+	key, found := k.keyOf(m.Content)
+	if !found {
+		key = k.lastKey
+	}
+	k.lastKey = key
+
+	g := k.groups[key]
+	if g == nil {
+		g = &keyedGroup{lineBuffer: k.buffer}
+		k.groups[key] = g
+	}
+	g.updated = time.Now()
+
+	if g.message.Bytes > 0 && !k.policy.continues(g.last, m.Content, g.lineCount) {
+		k.ready = append(k.ready, g.finalize())
+	}
+
+	if g.message.Bytes == 0 {
+		g.load(m)
+	} else {
+		g.addLine(m)
+	}
+
+	if k.policy.complete(m.Content, g.lineCount) {
+		k.ready = append(k.ready, g.finalize())
+		delete(k.groups, key)
+	}
+}
+
+func (k *keyedMultiline) keyOf(line []byte) (string, bool) {
+	matches := k.key.FindSubmatch(line)
+	if matches == nil {
+		return "", false
+	}
+	return string(matches[len(matches)-1]), true
+}
+
+func (k *keyedMultiline) expired(g *keyedGroup) bool {
+	return k.timeout > 0 && time.Since(g.updated) >= k.timeout
+}
+
+// flush finishes the events of all keys selected by the given function. The
+// events are returned in the order of their first line.
+func (k *keyedMultiline) flush(selected func(*keyedGroup) bool) {
+	var flushed []Message
+	for key, g := range k.groups {
+		if selected(g) {
+			if g.message.Bytes > 0 {
+				flushed = append(flushed, g.finalize())
+			}
+			delete(k.groups, key)
+		}
+	}
+
+	if len(flushed) == 0 {
+		return
+	}
+
+	logp.Debug("multiline", "%d keyed multiline events flushed.", len(flushed))
+	sort.Slice(flushed, func(i, j int) bool {
+		return flushed[i].Ts.Before(flushed[j].Ts)
+	})
+	k.ready = append(k.ready, flushed...)
+}
+
+// policies
+
+// patternPolicy combines lines using a predicate comparing the last and the
+// current line. An optional flush pattern finishes the event.
+type patternPolicy struct {
+	pred         matcher
+	flushMatcher *match.Matcher
+}
+
+func newPatternPolicy(config *MultilineConfig) (*patternPolicy, error) {
+	types := map[string]func(match.Matcher) (matcher, error){
+		"before": beforeMatcher,
+		"after":  afterMatcher,
+	}
+
+	matcherType, ok := types[config.Match]
+	if !ok {
+		return nil, fmt.Errorf("unknown matcher type: %s", config.Match)
+	}
+
+	if config.Pattern == nil {
+		return nil, fmt.Errorf("pattern required in %s mode", patternMode)
+	}
+
+	matcher, err := matcherType(*config.Pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	if config.Negate {
+		matcher = negatedMatcher(matcher)
+	}
+
+	return &patternPolicy{pred: matcher, flushMatcher: config.FlushPattern}, nil
+}
+
+func (p *patternPolicy) continues(last, line []byte, _ int) bool {
+	return p.flushes(line) || p.pred(last, line)
+}
+
+func (p *patternPolicy) complete(line []byte, lineCount int) bool {
+	// the flush pattern only finishes events it is not starting
+	return lineCount > 1 && p.flushes(line)
+}
+
+func (p *patternPolicy) flushes(line []byte) bool {
+	return p.flushMatcher != nil && p.flushMatcher.Match(line)
+}
+
+// countPolicy combines a fixed number of lines into one event.
+type countPolicy int
+
+func (n countPolicy) continues(_, _ []byte, lineCount int) bool {
+	return lineCount < int(n)
+}
+
+func (n countPolicy) complete(_ []byte, lineCount int) bool {
+	return lineCount >= int(n)
+}
+
+// whilePatternPolicy combines lines while they match the pattern, like lines
+// ending with a continuation character. A line matching the pattern is
+// continued by the next line, the first line not matching it finishes the
+// event.
+type whilePatternPolicy struct {
+	pattern match.Matcher
+	negate  bool
+}
+
+func (p *whilePatternPolicy) continues(last, _ []byte, _ int) bool {
+	return p.matches(last)
+}
+
+func (p *whilePatternPolicy) complete(line []byte, _ int) bool {
+	return !p.matches(line)
+}
+
+func (p *whilePatternPolicy) matches(line []byte) bool {
+	return p.pattern.Match(line) != p.negate
+}
+
 // matchers
 
 func afterMatcher(pat match.Matcher) (matcher, error) {
@@ -2,23 +2,70 @@ package reader
 
 import (
 	"fmt"
+	"regexp"
 	"time"
 
 	"github.com/elastic/beats/libbeat/common/match"
 )
 
+// Supported multiline types
+const (
+	patternMode      = "pattern"
+	countMode        = "count"
+	whilePatternMode = "while_pattern"
+)
+
 type MultilineConfig struct {
+	Type         string         `config:"type"`
 	Negate       bool           `config:"negate"`
-	Match        string         `config:"match" validate:"required"`
+	Match        string         `config:"match"`
 	MaxLines     *int           `config:"max_lines"`
-	Pattern      *match.Matcher `config:"pattern" validate:"required"`
+	Pattern      *match.Matcher `config:"pattern"`
 	Timeout      *time.Duration `config:"timeout" validate:"positive"`
 	FlushPattern *match.Matcher `config:"flush_pattern"`
+	LinesCount   int            `config:"count_lines" validate:"min=0"`
+	KeyPattern   string         `config:"key_pattern"`
 }
 
 func (c *MultilineConfig) Validate() error {
-	if c.Match != "after" && c.Match != "before" {
-		return fmt.Errorf("unknown matcher type: %s", c.Match)
+	switch c.Type {
+	case "", patternMode:
+		if c.Match != "after" && c.Match != "before" {
+			return fmt.Errorf("unknown matcher type: %s", c.Match)
+		}
+		if c.Pattern == nil {
+			return fmt.Errorf("multiline.pattern is required in %s mode", patternMode)
+		}
+	case countMode:
+		if c.LinesCount <= 0 {
+			return fmt.Errorf("multiline.count_lines must be > 0 in %s mode", countMode)
+		}
+	case whilePatternMode:
+		if c.Pattern == nil {
+			return fmt.Errorf("multiline.pattern is required in %s mode", whilePatternMode)
+		}
+	default:
+		return fmt.Errorf("unknown multiline type: %s", c.Type)
+	}
+
+	if c.KeyPattern != "" {
+		if _, err := compileKeyPattern(c.KeyPattern); err != nil {
+			return err
+		}
 	}
 	return nil
 }
+
+// compileKeyPattern compiles the regular expression used to extract the key of
+// a line. The first capturing group is used as key if the expression has one,
+// the complete match otherwise.
+func compileKeyPattern(pattern string) (*regexp.Regexp, error) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid multiline.key_pattern: %v", err)
+	}
+	if re.NumSubexp() > 1 {
+		return nil, fmt.Errorf("multiline.key_pattern must have at most one capturing group")
+	}
+	return re, nil
+}
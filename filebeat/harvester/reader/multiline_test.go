@@ -6,6 +6,7 @@ import (
 	"bytes"
 	"errors"
 	"os"
+	"sort"
 	"strings"
 	"testing"
 	"time"
@@ -131,6 +132,135 @@ func TestMultilineBeforeNegateOKWithEmptyLine(t *testing.T) {
 	)
 }
 
+func TestMultilineCountOK(t *testing.T) {
+	testMultilineOK(t,
+		MultilineConfig{
+			Type:       "count",
+			LinesCount: 3,
+		},
+		3,
+		"line1\nline1.1\nline1.2\n",
+		"line2\nline2.1\nline2.2\n",
+		"line3\n",
+	)
+}
+
+func TestMultilineCountMaxLines(t *testing.T) {
+	maxLines := 2
+	_, buf := createLineBuffer("line1\n", "line1.1\n", "line1.2\n", "line2\n")
+	reader := createMultilineTestReader(t, buf, MultilineConfig{
+		Type:       "count",
+		LinesCount: 3,
+		MaxLines:   &maxLines,
+	})
+
+	// lines over max_lines are dropped but still counted
+	message, err := reader.Next()
+	assert.NoError(t, err)
+	assert.Equal(t, "line1\nline1.1", string(message.Content))
+
+	message, err = reader.Next()
+	assert.NoError(t, err)
+	assert.Equal(t, "line2", string(message.Content))
+}
+
+func TestMultilineWhilePatternOK(t *testing.T) {
+	pattern := match.MustCompile(`\\$`) // line ends with \
+
+	testMultilineOK(t,
+		MultilineConfig{
+			Type:    "while_pattern",
+			Pattern: &pattern,
+		},
+		4,
+		"a \\\nb\n",
+		"line2 \\\nline2.1 \\\nline2.2\n",
+		"line3\n",
+		"line4\n",
+	)
+}
+
+func TestMultilineWhilePatternNegateOK(t *testing.T) {
+	pattern := match.MustCompile(`;$`) // line doesn't end with ';'
+
+	testMultilineOK(t,
+		MultilineConfig{
+			Type:    "while_pattern",
+			Pattern: &pattern,
+			Negate:  true,
+		},
+		3,
+		"SELECT *\nFROM orders\nWHERE id = 1;\n",
+		"COMMIT;\n",
+		"SELECT 1\nFROM dual;\n",
+	)
+}
+
+func TestMultilineKeyed(t *testing.T) {
+	pattern := match.MustCompile(`^\[\w+\] start`) // new events start with 'start'
+	_, buf := createLineBuffer(
+		"[t1] start 1\n",
+		"[t2] start 2\n",
+		"[t1] continue 1\n",
+		"  stack of 1\n",
+		"[t2] continue 2\n",
+		"[t1] start 3\n",
+	)
+	reader := createMultilineTestReader(t, buf, MultilineConfig{
+		Pattern:    &pattern,
+		Negate:     true,
+		Match:      "after",
+		KeyPattern: `^\[(\w+)\]`,
+	})
+
+	var events []string
+	for {
+		message, err := reader.Next()
+		if err != nil {
+			break
+		}
+		events = append(events, string(message.Content))
+	}
+
+	sort.Strings(events)
+	assert.Equal(t, []string{
+		"[t1] start 1\n[t1] continue 1\n  stack of 1",
+		"[t1] start 3",
+		"[t2] start 2\n[t2] continue 2",
+	}, events)
+}
+
+func TestMultilineKeyedTimeout(t *testing.T) {
+	lines := make(chan Message, 2)
+	timeout := 50 * time.Millisecond
+	reader, err := NewMultiline(chanReader(lines), "\n", 1<<20, &MultilineConfig{
+		Type:       "count",
+		LinesCount: 2,
+		KeyPattern: `^\w+`,
+		Timeout:    &timeout,
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	lines <- Message{Ts: time.Now(), Content: []byte("a 1"), Bytes: 4}
+	lines <- Message{Ts: time.Now(), Content: []byte("b 1"), Bytes: 4}
+
+	// key 'a' is flushed on timeout although the event is not complete
+	message, err := reader.Next()
+	assert.NoError(t, err)
+	assert.Equal(t, "a 1", string(message.Content))
+
+	message, err = reader.Next()
+	assert.NoError(t, err)
+	assert.Equal(t, "b 1", string(message.Content))
+}
+
+// chanReader returns the messages sent to the channel
+type chanReader chan Message
+
+func (c chanReader) Next() (Message, error) { return <-c, nil }
+
 func testMultilineOK(t *testing.T, cfg MultilineConfig, events int, expected ...string) {
 	_, buf := createLineBuffer(expected...)
 	reader := createMultilineTestReader(t, buf, cfg)
@@ -0,0 +1,93 @@
+package harvester
+
+import (
+	"sort"
+	"sync"
+)
+
+// Report collects the results of the harvesters per file. It's used to
+// summarize batch runs. All methods can be called on a nil Report, in which
+// case nothing is collected.
+type Report struct {
+	mutex sync.Mutex
+	files map[string]*FileReport
+}
+
+// FileReport contains the results of a single file
+type FileReport struct {
+	Source string
+	Lines  int64
+	Bytes  int64
+	Errors []string
+}
+
+// NewReport creates a new empty report
+func NewReport() *Report {
+	return &Report{files: map[string]*FileReport{}}
+}
+
+func (r *Report) get(source string) *FileReport {
+	f, ok := r.files[source]
+	if !ok {
+		f = &FileReport{Source: source}
+		r.files[source] = f
+	}
+	return f
+}
+
+// Add adds a file to the report, also if nothing is read from it
+func (r *Report) Add(source string) {
+	if r == nil {
+		return
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.get(source)
+}
+
+// Published adds the published lines and the bytes read to the file results
+func (r *Report) Published(source string, lines, bytes int) {
+	if r == nil {
+		return
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	f := r.get(source)
+	f.Lines += int64(lines)
+	f.Bytes += int64(bytes)
+}
+
+// Error records an error of the file
+func (r *Report) Error(source string, err error) {
+	if r == nil {
+		return
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	f := r.get(source)
+	f.Errors = append(f.Errors, err.Error())
+}
+
+// Files returns the results of all files, sorted by source
+func (r *Report) Files() []FileReport {
+	if r == nil {
+		return nil
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	files := make([]FileReport, 0, len(r.files))
+	for _, f := range r.files {
+		report := *f
+		report.Errors = append([]string(nil), f.Errors...)
+		files = append(files, report)
+	}
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].Source < files[j].Source
+	})
+	return files
+}
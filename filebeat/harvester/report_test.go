@@ -0,0 +1,33 @@
+// +build !integration
+
+package harvester
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReport(t *testing.T) {
+	r := NewReport()
+	r.Add("/var/log/b.log")
+	r.Published("/var/log/a.log", 1, 10)
+	r.Published("/var/log/a.log", 0, 5)
+	r.Published("/var/log/a.log", 1, 12)
+	r.Error("/var/log/c.log", errors.New("permission denied"))
+
+	assert.Equal(t, []FileReport{
+		{Source: "/var/log/a.log", Lines: 2, Bytes: 27},
+		{Source: "/var/log/b.log"},
+		{Source: "/var/log/c.log", Errors: []string{"permission denied"}},
+	}, r.Files())
+}
+
+func TestReportNil(t *testing.T) {
+	var r *Report
+	r.Add("/var/log/a.log")
+	r.Published("/var/log/a.log", 1, 10)
+	r.Error("/var/log/a.log", errors.New("error"))
+	assert.Nil(t, r.Files())
+}
@@ -8,6 +8,8 @@ package include
 
 import (
 	// This list is automatically generated by `make imports`
+	_ "github.com/elastic/beats/filebeat/prospector/http_endpoint"
+	_ "github.com/elastic/beats/filebeat/prospector/kafka"
 	_ "github.com/elastic/beats/filebeat/prospector/log"
 	_ "github.com/elastic/beats/filebeat/prospector/redis"
 	_ "github.com/elastic/beats/filebeat/prospector/stdin"
@@ -9,8 +9,9 @@ import (
 )
 
 type StateOS struct {
-	Inode  uint64 `json:"inode,"`
-	Device uint64 `json:"device,"`
+	Inode       uint64 `json:"inode,"`
+	Device      uint64 `json:"device,"`
+	Fingerprint string `json:"fingerprint,omitempty"`
 }
 
 // GetOSState returns the FileStateOS for non windows systems
@@ -28,10 +29,13 @@ func GetOSState(info os.FileInfo) StateOS {
 
 // IsSame file checks if the files are identical
 func (fs StateOS) IsSame(state StateOS) bool {
-	return fs.Inode == state.Inode && fs.Device == state.Device
+	return fs.Inode == state.Inode && fs.Device == state.Device && fs.Fingerprint == state.Fingerprint
 }
 
 func (fs StateOS) String() string {
+	if fs.Fingerprint != "" {
+		return fmt.Sprintf("%d-%d-%s", fs.Inode, fs.Device, fs.Fingerprint)
+	}
 	return fmt.Sprintf("%d-%d", fs.Inode, fs.Device)
 }
 
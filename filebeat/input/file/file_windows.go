@@ -8,9 +8,10 @@ import (
 )
 
 type StateOS struct {
-	IdxHi uint64 `json:"idxhi,"`
-	IdxLo uint64 `json:"idxlo,"`
-	Vol   uint64 `json:"vol,"`
+	IdxHi       uint64 `json:"idxhi,"`
+	IdxLo       uint64 `json:"idxlo,"`
+	Vol         uint64 `json:"vol,"`
+	Fingerprint string `json:"fingerprint,omitempty"`
 }
 
 // GetOSState returns the platform specific StateOS
@@ -39,10 +40,14 @@ func GetOSState(info os.FileInfo) StateOS {
 
 // IsSame file checks if the files are identical
 func (fs StateOS) IsSame(state StateOS) bool {
-	return fs.IdxHi == state.IdxHi && fs.IdxLo == state.IdxLo && fs.Vol == state.Vol
+	return fs.IdxHi == state.IdxHi && fs.IdxLo == state.IdxLo && fs.Vol == state.Vol &&
+		fs.Fingerprint == state.Fingerprint
 }
 
 func (fs StateOS) String() string {
+	if fs.Fingerprint != "" {
+		return fmt.Sprintf("%d-%d-%d-%s", fs.IdxHi, fs.IdxLo, fs.Vol, fs.Fingerprint)
+	}
 	return fmt.Sprintf("%d-%d-%d", fs.IdxHi, fs.IdxLo, fs.Vol)
 }
 
@@ -0,0 +1,40 @@
+package file
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"os"
+)
+
+// ErrFileTooSmall is returned if a file doesn't contain enough data to
+// compute its fingerprint yet
+var ErrFileTooSmall = errors.New("file is smaller than the fingerprint")
+
+// Fingerprint returns the SHA-256 hash of length bytes of the file, starting
+// at offset. Unlike the inode, the fingerprint is not reused by new files
+// after a file is removed.
+func Fingerprint(path string, info os.FileInfo, offset, length int64) (string, error) {
+	if info.Size() < offset+length {
+		return "", ErrFileTooSmall
+	}
+
+	f, err := ReadOpen(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	hash := sha256.New()
+	n, err := io.Copy(hash, io.NewSectionReader(f, offset, length))
+	if err != nil {
+		return "", err
+	}
+	if n < length {
+		// The file was truncated in the meantime
+		return "", ErrFileTooSmall
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
@@ -0,0 +1,46 @@
+// +build !integration
+
+package file
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFingerprint(t *testing.T) {
+	f, err := ioutil.TempFile("", "fingerprint")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(f.Name())
+	defer f.Close()
+
+	f.WriteString("header\nline 1\n")
+	info, err := f.Stat()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = Fingerprint(f.Name(), info, 0, 1024)
+	assert.Equal(t, ErrFileTooSmall, err)
+
+	// sha256 of "line"
+	fingerprint, err := Fingerprint(f.Name(), info, 7, 4)
+	if assert.NoError(t, err) {
+		assert.Equal(t, "38a9c1e721584da284b90ceea4ed7eb8db15ac4ea86c2c75ec925d5f8799fcf0", fingerprint)
+	}
+}
+
+func TestStateOSFingerprint(t *testing.T) {
+	a := StateOS{Fingerprint: "abc"}
+	b := StateOS{Fingerprint: "def"}
+
+	assert.True(t, a.IsSame(StateOS{Fingerprint: "abc"}))
+	assert.False(t, a.IsSame(b))
+	assert.False(t, a.IsSame(StateOS{}))
+	assert.NotEqual(t, a.String(), b.String())
+	assert.NotEqual(t, a.String(), StateOS{}.String())
+}
@@ -106,6 +106,12 @@ func (s *States) findPrevious(newState State) (int, State) {
 // Cleanup cleans up the state array. All states which are older then `older` are removed
 // The number of states that were cleaned up is returned
 func (s *States) Cleanup() int {
+	return s.CleanupWith(nil)
+}
+
+// CleanupWith cleans up the state array like Cleanup. If fn is not nil, it is
+// called for every state removed.
+func (s *States) CleanupWith(fn func(State)) int {
 	s.Lock()
 	defer s.Unlock()
 
@@ -121,6 +127,9 @@ func (s *States) Cleanup() int {
 		if state.TTL == 0 || expired {
 			if state.Finished {
 				logp.Debug("state", "State removed for %v because of older: %v", state.Source, state.TTL)
+				if fn != nil {
+					fn(state)
+				}
 				continue // drop state
 			} else {
 				logp.Err("State for %s should have been dropped, but couldn't as state is not finished.", state.Source)
@@ -0,0 +1,56 @@
+package http_endpoint
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/elastic/beats/filebeat/harvester"
+	"github.com/elastic/beats/libbeat/outputs"
+)
+
+var defaultConfig = config{
+	ForwarderConfig: harvester.ForwarderConfig{
+		Type: "http_endpoint",
+	},
+	Host:           "localhost:8080",
+	URL:            "/",
+	Prefix:         "json",
+	MaxBodySize:    10 * 1024 * 1024,
+	ResponseCode:   http.StatusOK,
+	ResponseBody:   `{"message": "success"}`,
+	PublishTimeout: 5 * time.Second,
+}
+
+type config struct {
+	harvester.ForwarderConfig `config:",inline"`
+	Host                      string             `config:"host" validate:"required"`
+	URL                       string             `config:"url" validate:"required"`
+	Prefix                    string             `config:"prefix" validate:"required"`
+	MaxBodySize               int64              `config:"max_body_size" validate:"min=1"`
+	ResponseCode              int                `config:"response_code" validate:"min=200,max=299"`
+	ResponseBody              string             `config:"response_body"`
+	PublishTimeout            time.Duration      `config:"publish_timeout" validate:"min=1"`
+	BasicAuth                 bool               `config:"basic_auth"`
+	Username                  string             `config:"username"`
+	Password                  string             `config:"password"`
+	SecretHeader              string             `config:"secret.header"`
+	SecretValue               string             `config:"secret.value"`
+	TLS                       *outputs.TLSConfig `config:"ssl"`
+}
+
+func (c *config) Validate() error {
+	if c.BasicAuth && (c.Username == "" || c.Password == "") {
+		return errors.New("username and password are required when basic_auth is enabled")
+	}
+
+	if (c.SecretHeader == "") != (c.SecretValue == "") {
+		return errors.New("both secret.header and secret.value must be set")
+	}
+
+	if c.TLS.IsEnabled() && c.TLS.Certificate.Certificate == "" {
+		return errors.New("ssl.certificate is required when ssl is enabled")
+	}
+
+	return nil
+}
@@ -0,0 +1,180 @@
+package http_endpoint
+
+import (
+	"bytes"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"time"
+
+	"github.com/elastic/beats/libbeat/beat"
+	"github.com/elastic/beats/libbeat/common"
+	"github.com/elastic/beats/libbeat/common/jsontransform"
+	"github.com/elastic/beats/libbeat/logp"
+)
+
+var (
+	errBusy   = errors.New("events not accepted before the publish timeout")
+	errClosed = errors.New("prospector closed")
+)
+
+// publishFunc sends the events to the outlet. It returns once all events have
+// been accepted, or with errBusy or errClosed if they could not be accepted.
+type publishFunc func(events []beat.Event) error
+
+// handler serves the POST requests of the endpoint. Each request contains a
+// single JSON object, an array of objects or newline delimited objects.
+type handler struct {
+	config  *config
+	publish publishFunc
+}
+
+func (h *handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		w.Header().Set("Allow", "POST")
+		sendError(w, http.StatusMethodNotAllowed, "only POST requests are supported")
+		return
+	}
+
+	if !h.authorized(r) {
+		if h.config.BasicAuth {
+			w.Header().Set("WWW-Authenticate", `Basic realm="filebeat"`)
+		}
+		sendError(w, http.StatusUnauthorized, "incorrect credentials")
+		return
+	}
+
+	if !validContentType(r.Header.Get("Content-Type")) {
+		sendError(w, http.StatusUnsupportedMediaType, "content type must be application/json or application/x-ndjson")
+		return
+	}
+
+	body, err := ioutil.ReadAll(io.LimitReader(r.Body, h.config.MaxBodySize+1))
+	if err != nil {
+		sendError(w, http.StatusBadRequest, fmt.Sprintf("error reading request body: %v", err))
+		return
+	}
+	if int64(len(body)) > h.config.MaxBodySize {
+		sendError(w, http.StatusRequestEntityTooLarge, fmt.Sprintf("request body exceeds %d bytes", h.config.MaxBodySize))
+		return
+	}
+
+	objects, err := decodeObjects(body)
+	if err != nil {
+		sendError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	now := time.Now()
+	events := make([]beat.Event, 0, len(objects))
+	for _, obj := range objects {
+		events = append(events, beat.Event{
+			Timestamp: now,
+			Fields:    common.MapStr{h.config.Prefix: obj},
+		})
+	}
+
+	switch err := h.publish(events); err {
+	case nil:
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(h.config.ResponseCode)
+		io.WriteString(w, h.config.ResponseBody)
+	case errBusy, errClosed:
+		logp.Debug("http_endpoint", "Rejecting request: %v", err)
+		w.Header().Set("Retry-After", "1")
+		sendError(w, http.StatusServiceUnavailable, err.Error())
+	default:
+		sendError(w, http.StatusInternalServerError, err.Error())
+	}
+}
+
+// authorized checks the basic auth credentials and the secret header if they
+// are configured.
+func (h *handler) authorized(r *http.Request) bool {
+	if h.config.BasicAuth {
+		username, password, ok := r.BasicAuth()
+		if !ok || !secureEqual(username, h.config.Username) || !secureEqual(password, h.config.Password) {
+			return false
+		}
+	}
+
+	if h.config.SecretHeader != "" {
+		if !secureEqual(r.Header.Get(h.config.SecretHeader), h.config.SecretValue) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func secureEqual(a, b string) bool {
+	return subtle.ConstantTimeCompare([]byte(a), []byte(b)) == 1
+}
+
+func validContentType(contentType string) bool {
+	if contentType == "" {
+		return false
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	return mediaType == "application/json" || mediaType == "application/x-ndjson"
+}
+
+// decodeObjects decodes all JSON values of the body. Each value must be an
+// object or an array of objects.
+func decodeObjects(body []byte) ([]common.MapStr, error) {
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+
+	var objects []common.MapStr
+	for {
+		var value interface{}
+		err := dec.Decode(&value)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("malformed JSON body: %v", err)
+		}
+
+		switch v := value.(type) {
+		case map[string]interface{}:
+			objects = append(objects, toMapStr(v))
+		case []interface{}:
+			for _, item := range v {
+				obj, ok := item.(map[string]interface{})
+				if !ok {
+					return nil, errors.New("JSON arrays must only contain objects")
+				}
+				objects = append(objects, toMapStr(obj))
+			}
+		default:
+			return nil, errors.New("JSON body must contain objects")
+		}
+	}
+
+	if len(objects) == 0 {
+		return nil, errors.New("request body is empty")
+	}
+	return objects, nil
+}
+
+// toMapStr converts the decoded object, with numbers converted to int64 where
+// possible, as done by the log prospector JSON decoding.
+func toMapStr(obj map[string]interface{}) common.MapStr {
+	jsontransform.TransformNumbers(obj)
+	return common.MapStr(obj)
+}
+
+func sendError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(common.MapStr{"message": message})
+}
@@ -0,0 +1,178 @@
+// +build !integration
+
+package http_endpoint
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/elastic/beats/filebeat/harvester"
+	"github.com/elastic/beats/filebeat/util"
+	"github.com/elastic/beats/libbeat/beat"
+	"github.com/elastic/beats/libbeat/common"
+)
+
+func newTestHandler(config config) (*handler, *[]beat.Event) {
+	var published []beat.Event
+	return &handler{
+		config: &config,
+		publish: func(events []beat.Event) error {
+			published = append(published, events...)
+			return nil
+		},
+	}, &published
+}
+
+func doRequest(h http.Handler, method, body string, headers map[string]string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, "/", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	return w
+}
+
+func TestHandlerPayloads(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		expected []common.MapStr
+	}{
+		{
+			"object",
+			`{"a": 1, "b": {"c": 1.5}}`,
+			[]common.MapStr{{"a": int64(1), "b": map[string]interface{}{"c": 1.5}}},
+		},
+		{
+			"array",
+			`[{"a": "x"}, {"a": "y"}]`,
+			[]common.MapStr{{"a": "x"}, {"a": "y"}},
+		},
+		{
+			"ndjson",
+			"{\"a\": \"x\"}\n{\"a\": \"y\"}\n",
+			[]common.MapStr{{"a": "x"}, {"a": "y"}},
+		},
+	}
+
+	for _, test := range tests {
+		h, published := newTestHandler(defaultConfig)
+		w := doRequest(h, "POST", test.body, nil)
+		assert.Equal(t, http.StatusOK, w.Code, test.name)
+		assert.Equal(t, `{"message": "success"}`, w.Body.String(), test.name)
+
+		if assert.Len(t, *published, len(test.expected), test.name) {
+			for i, event := range *published {
+				assert.Equal(t, common.MapStr{"json": test.expected[i]}, event.Fields, test.name)
+			}
+		}
+	}
+}
+
+func TestHandlerErrors(t *testing.T) {
+	config := defaultConfig
+	config.MaxBodySize = 20
+
+	tests := []struct {
+		name    string
+		method  string
+		body    string
+		headers map[string]string
+		status  int
+	}{
+		{"get", "GET", "", nil, http.StatusMethodNotAllowed},
+		{"content type", "POST", `{}`, map[string]string{"Content-Type": "text/plain"}, http.StatusUnsupportedMediaType},
+		{"malformed", "POST", `{"a": `, nil, http.StatusBadRequest},
+		{"not an object", "POST", `"text"`, nil, http.StatusBadRequest},
+		{"array of values", "POST", `[1, 2]`, nil, http.StatusBadRequest},
+		{"empty", "POST", ``, nil, http.StatusBadRequest},
+		{"too large", "POST", `{"a": "01234567890123456789"}`, nil, http.StatusRequestEntityTooLarge},
+	}
+
+	for _, test := range tests {
+		h, published := newTestHandler(config)
+		w := doRequest(h, test.method, test.body, test.headers)
+		assert.Equal(t, test.status, w.Code, test.name)
+		assert.Empty(t, *published, test.name)
+	}
+}
+
+func TestHandlerAuth(t *testing.T) {
+	config := defaultConfig
+	config.BasicAuth = true
+	config.Username = "user"
+	config.Password = "secret"
+	config.SecretHeader = "X-Secret"
+	config.SecretValue = "token"
+	config.ResponseCode = http.StatusAccepted
+
+	h, published := newTestHandler(config)
+
+	req := httptest.NewRequest("POST", "/", strings.NewReader(`{"a": 1}`))
+	req.Header.Set("Content-Type", "application/x-ndjson; charset=utf-8")
+	req.Header.Set("X-Secret", "token")
+	req.SetBasicAuth("user", "secret")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusAccepted, w.Code)
+	assert.Len(t, *published, 1)
+
+	w = doRequest(h, "POST", `{"a": 1}`, map[string]string{"X-Secret": "token"})
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.NotEmpty(t, w.Header().Get("WWW-Authenticate"))
+
+	req.Header.Set("X-Secret", "wrong")
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Len(t, *published, 1)
+}
+
+type blockingOutlet struct {
+	called chan struct{}
+	block  chan struct{}
+	events []beat.Event
+}
+
+func (o *blockingOutlet) OnEvent(data *util.Data) bool {
+	o.called <- struct{}{}
+	<-o.block
+	o.events = append(o.events, data.Event)
+	return true
+}
+
+func TestPublisherBackPressure(t *testing.T) {
+	outlet := &blockingOutlet{called: make(chan struct{}, 1), block: make(chan struct{})}
+	p := newPublisher(harvester.NewForwarder(outlet), 50*time.Millisecond)
+	go p.run()
+	defer p.stop()
+
+	h := &handler{config: &defaultConfig, publish: p.publish}
+
+	// The first request blocks the publisher until the outlet accepts the event
+	first := make(chan int)
+	go func() {
+		first <- doRequest(h, "POST", `{"a": 1}`, nil).Code
+	}()
+
+	// Wait for the first request to be handed over to the outlet
+	select {
+	case <-outlet.called:
+	case code := <-first:
+		t.Fatalf("request completed before the outlet accepted the event: %d", code)
+	}
+
+	w := doRequest(h, "POST", `{"a": 2}`, nil)
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+
+	close(outlet.block)
+	assert.Equal(t, http.StatusOK, <-first)
+	assert.Len(t, outlet.events, 1)
+}
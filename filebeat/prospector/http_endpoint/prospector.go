@@ -0,0 +1,206 @@
+package http_endpoint
+
+import (
+	"crypto/tls"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/elastic/beats/filebeat/channel"
+	"github.com/elastic/beats/filebeat/harvester"
+	"github.com/elastic/beats/filebeat/prospector"
+	"github.com/elastic/beats/filebeat/util"
+	"github.com/elastic/beats/libbeat/beat"
+	"github.com/elastic/beats/libbeat/common"
+	"github.com/elastic/beats/libbeat/common/cfgwarn"
+	"github.com/elastic/beats/libbeat/logp"
+	"github.com/elastic/beats/libbeat/outputs"
+	"github.com/elastic/beats/libbeat/outputs/transport"
+)
+
+func init() {
+	err := prospector.Register("http_endpoint", NewProspector)
+	if err != nil {
+		panic(err)
+	}
+}
+
+// Prospector receives JSON events pushed over HTTP
+type Prospector struct {
+	config    config
+	outlet    channel.Outleter
+	publisher *publisher
+	server    *http.Server
+	started   bool
+	wg        sync.WaitGroup
+}
+
+// NewProspector creates a new http_endpoint prospector
+func NewProspector(cfg *common.Config, outletFactory channel.Factory, context prospector.Context) (prospector.Prospectorer, error) {
+	cfgwarn.Experimental("HTTP endpoint prospector type is used")
+
+	config := defaultConfig
+	if err := cfg.Unpack(&config); err != nil {
+		return nil, err
+	}
+
+	tlsConfig, err := outputs.LoadTLSConfig(config.TLS)
+	if err != nil {
+		return nil, err
+	}
+
+	out, err := outletFactory(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &Prospector{
+		config:    config,
+		outlet:    out,
+		publisher: newPublisher(harvester.NewForwarder(out), config.PublishTimeout),
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle(config.URL, &handler{config: &p.config, publish: p.publisher.publish})
+	p.server = &http.Server{
+		Addr:      config.Host,
+		Handler:   mux,
+		TLSConfig: buildServerTLSConfig(tlsConfig),
+	}
+
+	return p, nil
+}
+
+// buildServerTLSConfig creates the TLS config of the server. If certificate
+// authorities are configured, clients must present a certificate signed by
+// one of them.
+func buildServerTLSConfig(config *transport.TLSConfig) *tls.Config {
+	if config == nil {
+		return nil
+	}
+
+	tlsConfig := config.BuildModuleConfig("")
+	if config.RootCAs != nil {
+		tlsConfig.ClientCAs = config.RootCAs
+		tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	return tlsConfig
+}
+
+// Run starts the HTTP server. It's only effective the first time it's called.
+func (p *Prospector) Run() {
+	if p.started {
+		return
+	}
+	p.started = true
+
+	listener, err := net.Listen("tcp", p.config.Host)
+	if err != nil {
+		logp.Err("Error starting http_endpoint prospector on %v: %v", p.config.Host, err)
+		return
+	}
+	if p.server.TLSConfig != nil {
+		listener = tls.NewListener(listener, p.server.TLSConfig)
+	}
+
+	logp.Info("Started http_endpoint prospector listening on %v%v", p.config.Host, p.config.URL)
+
+	p.wg.Add(2)
+	go func() {
+		defer p.wg.Done()
+		p.publisher.run()
+	}()
+	go func() {
+		defer p.wg.Done()
+		err := p.server.Serve(listener)
+		if err != nil && err != http.ErrServerClosed {
+			logp.Err("Error serving http_endpoint requests: %v", err)
+		}
+	}()
+}
+
+// Stop stops the HTTP server. Pending requests are answered with 503.
+func (p *Prospector) Stop() {
+	logp.Info("Stopping http_endpoint prospector")
+	p.publisher.stop()
+	p.outlet.Close()
+	p.server.Close()
+	p.wg.Wait()
+}
+
+// Wait stops the prospector, as the endpoint never completes
+func (p *Prospector) Wait() {
+	p.Stop()
+}
+
+// publisher forwards the events of the requests to the outlet. Requests are
+// handed over one by one, so if the outlet blocks, the requests waiting for
+// longer than the publish timeout fail with errBusy. This way clients get
+// back pressure from the publisher pipeline.
+type publisher struct {
+	forwarder *harvester.Forwarder
+	timeout   time.Duration
+	requests  chan *publishRequest
+	done      chan struct{}
+	stopOnce  sync.Once
+}
+
+type publishRequest struct {
+	events []beat.Event
+	result chan error
+}
+
+func newPublisher(forwarder *harvester.Forwarder, timeout time.Duration) *publisher {
+	return &publisher{
+		forwarder: forwarder,
+		timeout:   timeout,
+		requests:  make(chan *publishRequest),
+		done:      make(chan struct{}),
+	}
+}
+
+func (p *publisher) run() {
+	for {
+		select {
+		case <-p.done:
+			return
+		case req := <-p.requests:
+			req.result <- p.send(req.events)
+		}
+	}
+}
+
+func (p *publisher) send(events []beat.Event) error {
+	for _, event := range events {
+		data := util.NewData()
+		data.Event = event
+		if err := p.forwarder.Send(data); err != nil {
+			return errClosed
+		}
+	}
+	return nil
+}
+
+func (p *publisher) publish(events []beat.Event) error {
+	req := &publishRequest{
+		events: events,
+		result: make(chan error, 1),
+	}
+
+	timer := time.NewTimer(p.timeout)
+	defer timer.Stop()
+
+	select {
+	case p.requests <- req:
+	case <-timer.C:
+		return errBusy
+	case <-p.done:
+		return errClosed
+	}
+	return <-req.result
+}
+
+func (p *publisher) stop() {
+	p.stopOnce.Do(func() { close(p.done) })
+}
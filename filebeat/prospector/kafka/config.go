@@ -0,0 +1,159 @@
+package kafka
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Shopify/sarama"
+
+	"github.com/elastic/beats/filebeat/harvester"
+	"github.com/elastic/beats/filebeat/harvester/reader"
+	"github.com/elastic/beats/libbeat/common/kafka"
+	"github.com/elastic/beats/libbeat/outputs"
+)
+
+var defaultConfig = config{
+	ForwarderConfig: harvester.ForwarderConfig{
+		Type: "kafka",
+	},
+	ClientID:          "filebeat",
+	Version:           "0.10.0",
+	InitialOffset:     "oldest",
+	Assignment:        "range",
+	Timeout:           30 * time.Second,
+	SessionTimeout:    30 * time.Second,
+	HeartbeatInterval: 3 * time.Second,
+	CommitInterval:    1 * time.Second,
+	ConnectBackoff:    10 * time.Second,
+	ChanBufferSize:    256,
+}
+
+type config struct {
+	harvester.ForwarderConfig `config:",inline"`
+	Hosts                     []string           `config:"hosts" validate:"required"`
+	Topics                    []string           `config:"topics" validate:"required"`
+	GroupID                   string             `config:"group_id" validate:"required"`
+	ClientID                  string             `config:"client_id"`
+	Version                   string             `config:"version"`
+	InitialOffset             string             `config:"initial_offset"`
+	Assignment                string             `config:"partition_assignment"`
+	Timeout                   time.Duration      `config:"timeout" validate:"min=1"`
+	KeepAlive                 time.Duration      `config:"keep_alive" validate:"min=0"`
+	SessionTimeout            time.Duration      `config:"session_timeout" validate:"min=1"`
+	HeartbeatInterval         time.Duration      `config:"heartbeat_interval" validate:"min=1"`
+	CommitInterval            time.Duration      `config:"commit_interval" validate:"min=1"`
+	ConnectBackoff            time.Duration      `config:"connect_backoff" validate:"min=1"`
+	ChanBufferSize            int                `config:"channel_buffer_size" validate:"min=1"`
+	JSON                      *reader.JSONConfig `config:"json"`
+	TLS                       *outputs.TLSConfig `config:"ssl"`
+	Username                  string             `config:"username"`
+	Password                  string             `config:"password"`
+}
+
+// recordVersions are the versions supported by the prospector in addition to
+// the versions of the kafka output. The kafka output maps 0.11 to the 0.10.2
+// protocol, while the record headers require the protocol of 0.11 or newer.
+var recordVersions = map[string]sarama.KafkaVersion{
+	"0.11.0.0": sarama.V0_11_0_0,
+	"0.11.0":   sarama.V0_11_0_0,
+	"0.11":     sarama.V0_11_0_0,
+
+	"1.0.0.0": sarama.V1_0_0_0,
+	"1.0.0":   sarama.V1_0_0_0,
+	"1.0":     sarama.V1_0_0_0,
+}
+
+// kafkaVersion returns the sarama version of the configured kafka version.
+func (c *config) kafkaVersion() (sarama.KafkaVersion, error) {
+	if version, ok := recordVersions[c.Version]; ok {
+		return version, nil
+	}
+
+	v := kafka.Version(c.Version)
+	if err := v.Validate(); err != nil {
+		return sarama.KafkaVersion{}, err
+	}
+	version, _ := v.Get()
+	return version, nil
+}
+
+var initialOffsets = map[string]int64{
+	"oldest": sarama.OffsetOldest,
+	"newest": sarama.OffsetNewest,
+}
+
+func (c *config) Validate() error {
+	if len(c.Hosts) == 0 {
+		return errors.New("no hosts configured")
+	}
+
+	if len(c.Topics) == 0 {
+		return errors.New("no topics configured")
+	}
+
+	version, err := c.kafkaVersion()
+	if err != nil {
+		return err
+	}
+	if !version.IsAtLeast(sarama.V0_9_0_0) {
+		return fmt.Errorf("kafka version '%v' does not support consumer groups, 0.9 or newer is required", c.Version)
+	}
+
+	if _, ok := initialOffsets[strings.ToLower(c.InitialOffset)]; !ok {
+		return fmt.Errorf("initial offset '%v' unknown, must be oldest or newest", c.InitialOffset)
+	}
+
+	if _, ok := balancers[strings.ToLower(c.Assignment)]; !ok {
+		return fmt.Errorf("partition assignment '%v' unknown, must be range or roundrobin", c.Assignment)
+	}
+
+	if c.HeartbeatInterval >= c.SessionTimeout {
+		return errors.New("heartbeat_interval must be lower than session_timeout")
+	}
+
+	if c.Username != "" && c.Password == "" {
+		return errors.New("password must be set when username is configured")
+	}
+
+	return nil
+}
+
+// saramaConfig creates the sarama client configuration.
+func (c *config) saramaConfig() (*sarama.Config, error) {
+	k := sarama.NewConfig()
+
+	k.Net.DialTimeout = c.Timeout
+	k.Net.ReadTimeout = c.Timeout
+	k.Net.WriteTimeout = c.Timeout
+	k.Net.KeepAlive = c.KeepAlive
+
+	tls, err := outputs.LoadTLSConfig(c.TLS)
+	if err != nil {
+		return nil, err
+	}
+	if tls != nil {
+		k.Net.TLS.Enable = true
+		k.Net.TLS.Config = tls.BuildModuleConfig("")
+	}
+
+	if c.Username != "" {
+		k.Net.SASL.Enable = true
+		k.Net.SASL.User = c.Username
+		k.Net.SASL.Password = c.Password
+	}
+
+	k.ClientID = c.ClientID
+	k.ChannelBufferSize = c.ChanBufferSize
+	k.Consumer.Return.Errors = true
+	k.Version, err = c.kafkaVersion()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := k.Validate(); err != nil {
+		return nil, err
+	}
+	return k, nil
+}
@@ -0,0 +1,37 @@
+// +build !integration
+
+package kafka
+
+import (
+	"testing"
+
+	"github.com/Shopify/sarama"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigKafkaVersion(t *testing.T) {
+	tests := []struct {
+		version  string
+		expected sarama.KafkaVersion
+	}{
+		{"0.10.0", sarama.V0_10_0_1},
+		{"0.10.2.1", sarama.V0_10_2_0},
+		{"0.11", sarama.V0_11_0_0},
+		{"0.11.0.0", sarama.V0_11_0_0},
+		{"1.0", sarama.V1_0_0_0},
+	}
+
+	for _, test := range tests {
+		c := defaultConfig
+		c.Version = test.version
+		version, err := c.kafkaVersion()
+		if assert.NoError(t, err, test.version) {
+			assert.Equal(t, test.expected, version, test.version)
+		}
+	}
+
+	c := defaultConfig
+	c.Version = "0.12"
+	_, err := c.kafkaVersion()
+	assert.Error(t, err)
+}
@@ -0,0 +1,11 @@
+// Package kafka contains a prospector and harvester to consume topics from
+// Kafka as a member of a consumer group.
+//
+// The group membership is implemented on top of the group requests of the
+// sarama client. Each assigned partition is consumed by its own harvester.
+// The offsets of the messages are only committed once the publisher pipeline
+// has acknowledged the events, so messages are delivered at least once.
+//
+// The topic, partition, offset, key and record headers of the messages are
+// added to the event metadata. Record headers require Kafka 0.11 or newer.
+package kafka
@@ -0,0 +1,394 @@
+package kafka
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/Shopify/sarama"
+
+	"github.com/elastic/beats/libbeat/logp"
+)
+
+// assignment maps topics to the partitions of a group member
+type assignment map[string][]int32
+
+// balancer computes the partition assignments of all members of a group. The
+// members are given with the topics they are subscribed to.
+type balancer func(members map[string][]string, partitions map[string][]int32) map[string]assignment
+
+var balancers = map[string]balancer{
+	"range":      balanceRange,
+	"roundrobin": balanceRoundRobin,
+}
+
+// balanceRange assigns consecutive ranges of partitions of each topic to the
+// members subscribed to the topic.
+func balanceRange(members map[string][]string, partitions map[string][]int32) map[string]assignment {
+	plan := map[string]assignment{}
+
+	for topic, consumers := range subscribers(members) {
+		topicPartitions := partitions[topic]
+		n := len(topicPartitions) / len(consumers)
+		extra := len(topicPartitions) % len(consumers)
+
+		start := 0
+		for i, member := range consumers {
+			count := n
+			if i < extra {
+				count++
+			}
+			if count > 0 {
+				addAssignment(plan, member, topic, topicPartitions[start:start+count]...)
+			}
+			start += count
+		}
+	}
+	return plan
+}
+
+// balanceRoundRobin assigns all partitions of all topics one by one to the
+// members subscribed to the partition topic.
+func balanceRoundRobin(members map[string][]string, partitions map[string][]int32) map[string]assignment {
+	plan := map[string]assignment{}
+
+	subscribed := subscribers(members)
+	topics := make([]string, 0, len(subscribed))
+	for topic := range subscribed {
+		topics = append(topics, topic)
+	}
+	sort.Strings(topics)
+
+	memberIDs := sortedKeys(members)
+	next := 0
+	for _, topic := range topics {
+		for _, partition := range partitions[topic] {
+			// find the next member subscribed to the topic
+			for !contains(members[memberIDs[next%len(memberIDs)]], topic) {
+				next++
+			}
+			addAssignment(plan, memberIDs[next%len(memberIDs)], topic, partition)
+			next++
+		}
+	}
+	return plan
+}
+
+// subscribers returns the sorted member IDs subscribed to each topic
+func subscribers(members map[string][]string) map[string][]string {
+	result := map[string][]string{}
+	for _, member := range sortedKeys(members) {
+		for _, topic := range members[member] {
+			result[topic] = append(result[topic], member)
+		}
+	}
+	return result
+}
+
+func addAssignment(plan map[string]assignment, member, topic string, partitions ...int32) {
+	if plan[member] == nil {
+		plan[member] = assignment{}
+	}
+	plan[member][topic] = append(plan[member][topic], partitions...)
+}
+
+func sortedKeys(m map[string][]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+// groupMember implements the Kafka group membership protocol, as the vendored
+// sarama client only provides the low level requests. Offsets are committed
+// with the generation and member ID, which is required by Kafka for active
+// groups.
+type groupMember struct {
+	client   sarama.Client
+	config   *config
+	balance  balancer
+	strategy string
+
+	memberID   string
+	generation int32
+}
+
+func newGroupMember(client sarama.Client, config *config, strategy string) *groupMember {
+	return &groupMember{
+		client:   client,
+		config:   config,
+		balance:  balancers[strategy],
+		strategy: strategy,
+	}
+}
+
+// join joins the group and returns the partitions assigned to this member.
+// The group leader computes the assignment of all members.
+func (g *groupMember) join() (assignment, error) {
+	coordinator, err := g.coordinator()
+	if err != nil {
+		return nil, err
+	}
+
+	request := &sarama.JoinGroupRequest{
+		GroupId:        g.config.GroupID,
+		MemberId:       g.memberID,
+		SessionTimeout: int32(g.config.SessionTimeout / time.Millisecond),
+		ProtocolType:   "consumer",
+	}
+	err = request.AddGroupProtocolMetadata(g.strategy, &sarama.ConsumerGroupMemberMetadata{
+		Topics: g.config.Topics,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := coordinator.JoinGroup(request)
+	if err != nil {
+		return nil, g.handleError(err)
+	}
+	if response.Err != sarama.ErrNoError {
+		return nil, g.handleError(response.Err)
+	}
+
+	g.memberID = response.MemberId
+	g.generation = response.GenerationId
+	logp.Debug("kafka", "Joined group %v as member %v with generation %v",
+		g.config.GroupID, g.memberID, g.generation)
+
+	syncRequest := &sarama.SyncGroupRequest{
+		GroupId:      g.config.GroupID,
+		GenerationId: g.generation,
+		MemberId:     g.memberID,
+	}
+	if response.LeaderId == response.MemberId {
+		plan, err := g.plan(response)
+		if err != nil {
+			return nil, err
+		}
+		for member, topics := range plan {
+			err := syncRequest.AddGroupAssignmentMember(member, &sarama.ConsumerGroupMemberAssignment{
+				Topics: topics,
+			})
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	syncResponse, err := coordinator.SyncGroup(syncRequest)
+	if err != nil {
+		return nil, g.handleError(err)
+	}
+	if syncResponse.Err != sarama.ErrNoError {
+		return nil, g.handleError(syncResponse.Err)
+	}
+
+	// Members without partitions get an empty assignment
+	if len(syncResponse.MemberAssignment) == 0 {
+		return assignment{}, nil
+	}
+	memberAssignment, err := syncResponse.GetMemberAssignment()
+	if err != nil {
+		return nil, err
+	}
+	return assignment(memberAssignment.Topics), nil
+}
+
+// plan computes the assignment of all group members
+func (g *groupMember) plan(response *sarama.JoinGroupResponse) (map[string]assignment, error) {
+	metadata, err := response.GetMembers()
+	if err != nil {
+		return nil, err
+	}
+
+	members := map[string][]string{}
+	var topics []string
+	for member, meta := range metadata {
+		members[member] = meta.Topics
+		for _, topic := range meta.Topics {
+			if !contains(topics, topic) {
+				topics = append(topics, topic)
+			}
+		}
+	}
+
+	if err := g.client.RefreshMetadata(topics...); err != nil {
+		return nil, err
+	}
+	partitions := map[string][]int32{}
+	for _, topic := range topics {
+		p, err := g.client.Partitions(topic)
+		if err != nil {
+			return nil, err
+		}
+		partitions[topic] = p
+	}
+
+	return g.balance(members, partitions), nil
+}
+
+// heartbeat notifies the coordinator that the member is alive. An error is
+// returned if the group is rebalancing, in which case the group must be joined
+// again.
+func (g *groupMember) heartbeat() error {
+	coordinator, err := g.coordinator()
+	if err != nil {
+		return err
+	}
+
+	response, err := coordinator.Heartbeat(&sarama.HeartbeatRequest{
+		GroupId:      g.config.GroupID,
+		GenerationId: g.generation,
+		MemberId:     g.memberID,
+	})
+	if err != nil {
+		return g.handleError(err)
+	}
+	if response.Err != sarama.ErrNoError {
+		return g.handleError(response.Err)
+	}
+	return nil
+}
+
+// leave leaves the group, so the partitions are reassigned without waiting
+// for the session to time out.
+func (g *groupMember) leave() error {
+	if g.memberID == "" {
+		return nil
+	}
+
+	coordinator, err := g.coordinator()
+	if err != nil {
+		return err
+	}
+
+	response, err := coordinator.LeaveGroup(&sarama.LeaveGroupRequest{
+		GroupId:  g.config.GroupID,
+		MemberId: g.memberID,
+	})
+	g.memberID = ""
+	if err != nil {
+		return err
+	}
+	if response.Err != sarama.ErrNoError {
+		return response.Err
+	}
+	return nil
+}
+
+// fetchOffsets returns the committed offsets of the assigned partitions.
+// Partitions without committed offset are not part of the result.
+func (g *groupMember) fetchOffsets(partitions assignment) (map[string]map[int32]int64, error) {
+	coordinator, err := g.coordinator()
+	if err != nil {
+		return nil, err
+	}
+
+	request := &sarama.OffsetFetchRequest{
+		ConsumerGroup: g.config.GroupID,
+		Version:       1,
+	}
+	for topic, ids := range partitions {
+		for _, id := range ids {
+			request.AddPartition(topic, id)
+		}
+	}
+
+	response, err := coordinator.FetchOffset(request)
+	if err != nil {
+		return nil, g.handleError(err)
+	}
+
+	offsets := map[string]map[int32]int64{}
+	for topic, ids := range partitions {
+		offsets[topic] = map[int32]int64{}
+		for _, id := range ids {
+			block := response.GetBlock(topic, id)
+			if block == nil {
+				return nil, fmt.Errorf("no offset returned for topic %v partition %v", topic, id)
+			}
+			if block.Err != sarama.ErrNoError {
+				return nil, g.handleError(block.Err)
+			}
+			if block.Offset >= 0 {
+				offsets[topic][id] = block.Offset
+			}
+		}
+	}
+	return offsets, nil
+}
+
+// commit commits the given offsets for the current generation.
+func (g *groupMember) commit(offsets map[string]map[int32]int64) error {
+	if len(offsets) == 0 {
+		return nil
+	}
+
+	coordinator, err := g.coordinator()
+	if err != nil {
+		return err
+	}
+
+	request := &sarama.OffsetCommitRequest{
+		ConsumerGroup:           g.config.GroupID,
+		ConsumerGroupGeneration: g.generation,
+		ConsumerID:              g.memberID,
+		RetentionTime:           -1,
+		Version:                 2,
+	}
+	for topic, partitions := range offsets {
+		for partition, offset := range partitions {
+			request.AddBlock(topic, partition, offset, 0, "")
+		}
+	}
+
+	response, err := coordinator.CommitOffset(request)
+	if err != nil {
+		return g.handleError(err)
+	}
+	for _, partitions := range response.Errors {
+		for _, kerr := range partitions {
+			if kerr != sarama.ErrNoError {
+				return g.handleError(kerr)
+			}
+		}
+	}
+	return nil
+}
+
+func (g *groupMember) coordinator() (*sarama.Broker, error) {
+	return g.client.Coordinator(g.config.GroupID)
+}
+
+// handleError resets the group state depending on the error, so the next
+// requests are sent to the right coordinator and with a valid member ID.
+func (g *groupMember) handleError(err error) error {
+	switch err {
+	case sarama.ErrUnknownMemberId, sarama.ErrIllegalGeneration:
+		g.memberID = ""
+	case sarama.ErrNotCoordinatorForConsumer, sarama.ErrConsumerCoordinatorNotAvailable:
+		if err := g.client.RefreshCoordinator(g.config.GroupID); err != nil {
+			logp.Debug("kafka", "Failed to refresh coordinator of group %v: %v", g.config.GroupID, err)
+		}
+	default:
+		// Network errors require the coordinator to be looked up again
+		if _, ok := err.(sarama.KError); !ok {
+			if err := g.client.RefreshCoordinator(g.config.GroupID); err != nil {
+				logp.Debug("kafka", "Failed to refresh coordinator of group %v: %v", g.config.GroupID, err)
+			}
+		}
+	}
+	return err
+}
@@ -0,0 +1,61 @@
+// +build !integration
+
+package kafka
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBalanceRange(t *testing.T) {
+	members := map[string][]string{
+		"m1": {"a", "b"},
+		"m2": {"a"},
+		"m3": {"a", "b"},
+	}
+	partitions := map[string][]int32{
+		"a": {0, 1, 2, 3},
+		"b": {0, 1},
+	}
+
+	assert.Equal(t, map[string]assignment{
+		"m1": {"a": {0, 1}, "b": {0}},
+		"m2": {"a": {2}},
+		"m3": {"a": {3}, "b": {1}},
+	}, balanceRange(members, partitions))
+}
+
+func TestBalanceRoundRobin(t *testing.T) {
+	members := map[string][]string{
+		"m1": {"a", "b"},
+		"m2": {"a"},
+		"m3": {"a", "b"},
+	}
+	partitions := map[string][]int32{
+		"a": {0, 1, 2, 3},
+		"b": {0, 1},
+	}
+
+	assert.Equal(t, map[string]assignment{
+		"m1": {"a": {0, 3}, "b": {1}},
+		"m2": {"a": {1}},
+		"m3": {"a": {2}, "b": {0}},
+	}, balanceRoundRobin(members, partitions))
+}
+
+func TestBalanceMorePartitionsThanMembers(t *testing.T) {
+	members := map[string][]string{
+		"m1": {"a"},
+		"m2": {"a"},
+	}
+	partitions := map[string][]int32{
+		"a": {0},
+	}
+
+	for name, balance := range balancers {
+		assert.Equal(t, map[string]assignment{
+			"m1": {"a": {0}},
+		}, balance(members, partitions), name)
+	}
+}
@@ -0,0 +1,128 @@
+package kafka
+
+import (
+	"time"
+
+	"github.com/Shopify/sarama"
+
+	"github.com/elastic/beats/filebeat/harvester"
+	"github.com/elastic/beats/filebeat/harvester/reader"
+	"github.com/elastic/beats/filebeat/util"
+	"github.com/elastic/beats/libbeat/beat"
+	"github.com/elastic/beats/libbeat/common"
+	"github.com/elastic/beats/libbeat/logp"
+)
+
+// Harvester publishes the messages of a single topic partition.
+type Harvester struct {
+	forwarder *harvester.Forwarder
+	consumer  sarama.PartitionConsumer
+	offsets   *partitionOffsets
+	json      *reader.JSONConfig
+	done      chan struct{}
+}
+
+func newHarvester(
+	forwarder *harvester.Forwarder,
+	consumer sarama.PartitionConsumer,
+	offsets *partitionOffsets,
+	json *reader.JSONConfig,
+) *Harvester {
+	return &Harvester{
+		forwarder: forwarder,
+		consumer:  consumer,
+		offsets:   offsets,
+		json:      json,
+		done:      make(chan struct{}),
+	}
+}
+
+// Run publishes the messages until the harvester is stopped or the outlet is
+// closed.
+func (h *Harvester) Run() {
+	defer h.consumer.Close()
+
+	for {
+		select {
+		case <-h.done:
+			return
+		case msg, ok := <-h.consumer.Messages():
+			if !ok {
+				return
+			}
+
+			data := util.NewData()
+			data.Event = createEvent(msg, h.json)
+			data.Event.Private = h.offsets.published(msg.Offset)
+			if err := h.forwarder.Send(data); err != nil {
+				return
+			}
+		case err, ok := <-h.consumer.Errors():
+			if !ok {
+				return
+			}
+			logp.Err("Error reading from kafka topic %v partition %v: %v", err.Topic, err.Partition, err.Err)
+		}
+	}
+}
+
+// Stop stops the harvester
+func (h *Harvester) Stop() {
+	close(h.done)
+}
+
+// createEvent creates the event of a Kafka message. The message details are
+// stored in the event metadata.
+func createEvent(msg *sarama.ConsumerMessage, jsonConfig *reader.JSONConfig) beat.Event {
+	// Messages consumed with a protocol older than 0.10 have no timestamp
+	timestamp := msg.Timestamp
+	if timestamp.IsZero() {
+		timestamp = time.Now()
+	}
+
+	fields := common.MapStr{}
+	text := string(msg.Value)
+	if jsonConfig != nil {
+		content, jsonFields := reader.DecodeJSON(msg.Value, jsonConfig)
+		text = string(content)
+		if len(jsonFields) > 0 {
+			fields["json"] = jsonFields
+			reader.MergeJSONFields(fields, jsonFields, &text, *jsonConfig)
+		} else {
+			fields["message"] = text
+		}
+	} else {
+		fields["message"] = text
+	}
+
+	meta := common.MapStr{
+		"topic":     msg.Topic,
+		"partition": msg.Partition,
+		"offset":    msg.Offset,
+	}
+	if len(msg.Key) > 0 {
+		meta["key"] = string(msg.Key)
+	}
+	if headers := headersMapping(msg.Headers); len(headers) > 0 {
+		meta["headers"] = headers
+	}
+
+	return beat.Event{
+		Timestamp: timestamp,
+		Meta:      common.MapStr{"kafka": meta},
+		Fields:    fields,
+	}
+}
+
+// headersMapping converts the record headers of a message, only available
+// since Kafka 0.11, to a map. If a key is repeated the last value is kept.
+func headersMapping(headers []*sarama.RecordHeader) common.MapStr {
+	result := common.MapStr{}
+	for _, header := range headers {
+		if header == nil || len(header.Key) == 0 {
+			continue
+		}
+		result[string(header.Key)] = string(header.Value)
+	}
+	return result
+}
@@ -0,0 +1,86 @@
+// +build !integration
+
+package kafka
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Shopify/sarama"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/elastic/beats/filebeat/harvester/reader"
+	"github.com/elastic/beats/libbeat/common"
+)
+
+func TestCreateEvent(t *testing.T) {
+	timestamp := time.Date(2017, 10, 1, 12, 0, 0, 0, time.UTC)
+	msg := &sarama.ConsumerMessage{
+		Topic:     "logs",
+		Partition: 2,
+		Offset:    42,
+		Key:       []byte("host1"),
+		Value:     []byte(`{"level": "info", "msg": "started"}`),
+		Timestamp: timestamp,
+	}
+
+	event := createEvent(msg, nil)
+	assert.Equal(t, timestamp, event.Timestamp)
+	assert.Equal(t, common.MapStr{"message": `{"level": "info", "msg": "started"}`}, event.Fields)
+	assert.Equal(t, common.MapStr{
+		"kafka": common.MapStr{
+			"topic":     "logs",
+			"partition": int32(2),
+			"offset":    int64(42),
+			"key":       "host1",
+		},
+	}, event.Meta)
+
+	event = createEvent(msg, &reader.JSONConfig{MessageKey: "msg"})
+	assert.Equal(t, common.MapStr{
+		"json": common.MapStr{"level": "info", "msg": "started"},
+	}, event.Fields)
+
+	event = createEvent(msg, &reader.JSONConfig{MessageKey: "msg", KeysUnderRoot: true})
+	assert.Equal(t, common.MapStr{"level": "info", "msg": "started"}, event.Fields)
+}
+
+func TestCreateEventHeaders(t *testing.T) {
+	msg := &sarama.ConsumerMessage{
+		Topic:     "logs",
+		Partition: 1,
+		Offset:    7,
+		Value:     []byte("started"),
+		Headers: []*sarama.RecordHeader{
+			{Key: []byte("trace.id"), Value: []byte("abc123")},
+			{Key: []byte("source"), Value: []byte("app1")},
+			{Key: []byte("source"), Value: []byte("app2")},
+			{Key: []byte(""), Value: []byte("ignored")},
+			nil,
+		},
+	}
+
+	event := createEvent(msg, nil)
+	assert.Equal(t, common.MapStr{
+		"kafka": common.MapStr{
+			"topic":     "logs",
+			"partition": int32(1),
+			"offset":    int64(7),
+			"headers": common.MapStr{
+				"trace.id": "abc123",
+				"source":   "app2",
+			},
+		},
+	}, event.Meta)
+}
+
+func TestCreateEventInvalidJSON(t *testing.T) {
+	msg := &sarama.ConsumerMessage{Value: []byte("plain text")}
+
+	event := createEvent(msg, &reader.JSONConfig{})
+	assert.False(t, event.Timestamp.IsZero())
+	assert.Equal(t, common.MapStr{"message": "plain text"}, event.Fields)
+	assert.Equal(t, common.MapStr{
+		"kafka": common.MapStr{"topic": "", "partition": int32(0), "offset": int64(0)},
+	}, event.Meta)
+}
@@ -0,0 +1,156 @@
+package kafka
+
+import (
+	"sync"
+	"time"
+)
+
+// offsetTracker keeps track of the offsets of the events published for the
+// partitions assigned in one group generation. Offsets are only marked for
+// commit once the publisher pipeline has acknowledged the events.
+type offsetTracker struct {
+	mutex      sync.Mutex
+	partitions map[string]map[int32]*partitionOffsets
+
+	// number of published events not acknowledged yet
+	pending int
+	// closed once all published events have been acknowledged
+	idle chan struct{}
+}
+
+type partitionOffsets struct {
+	tracker *offsetTracker
+	topic   string
+	id      int32
+
+	// next offset to be committed, the one after the last acknowledged event
+	next      int64
+	committed int64
+}
+
+// eventACK is stored in the private field of the published events. The
+// filebeat event ACKer calls ACK once the event has been acknowledged by the
+// publisher pipeline.
+type eventACK struct {
+	partition *partitionOffsets
+	offset    int64
+}
+
+func newOffsetTracker() *offsetTracker {
+	idle := make(chan struct{})
+	close(idle)
+	return &offsetTracker{
+		partitions: map[string]map[int32]*partitionOffsets{},
+		idle:       idle,
+	}
+}
+
+// add starts tracking a partition. The offset is the one of the first message
+// to be consumed, which is also the offset currently committed.
+func (t *offsetTracker) add(topic string, id int32, offset int64) *partitionOffsets {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if t.partitions[topic] == nil {
+		t.partitions[topic] = map[int32]*partitionOffsets{}
+	}
+	p := &partitionOffsets{
+		tracker:   t,
+		topic:     topic,
+		id:        id,
+		next:      offset,
+		committed: offset,
+	}
+	t.partitions[topic][id] = p
+	return p
+}
+
+// published registers an event being sent to the pipeline and returns the
+// ACK handle of the event.
+func (p *partitionOffsets) published(offset int64) *eventACK {
+	t := p.tracker
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if t.pending == 0 {
+		t.idle = make(chan struct{})
+	}
+	t.pending++
+	return &eventACK{partition: p, offset: offset}
+}
+
+// ACK marks the event offset as processed
+func (a *eventACK) ACK() {
+	t := a.partition.tracker
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if a.offset >= a.partition.next {
+		a.partition.next = a.offset + 1
+	}
+
+	t.pending--
+	if t.pending == 0 {
+		close(t.idle)
+	}
+}
+
+// uncommitted returns the offsets acknowledged since the last commit.
+func (t *offsetTracker) uncommitted() map[string]map[int32]int64 {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	offsets := map[string]map[int32]int64{}
+	for topic, partitions := range t.partitions {
+		for id, p := range partitions {
+			if p.next == p.committed {
+				continue
+			}
+			if offsets[topic] == nil {
+				offsets[topic] = map[int32]int64{}
+			}
+			offsets[topic][id] = p.next
+		}
+	}
+	return offsets
+}
+
+// markCommitted updates the committed offsets after a successful commit.
+func (t *offsetTracker) markCommitted(offsets map[string]map[int32]int64) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	for topic, partitions := range offsets {
+		for id, offset := range partitions {
+			if p, ok := t.partitions[topic][id]; ok {
+				p.committed = offset
+			}
+		}
+	}
+}
+
+// wait waits until all published events have been acknowledged. It returns
+// false if the timeout is reached or done is closed before.
+func (t *offsetTracker) wait(timeout time.Duration, done <-chan struct{}) bool {
+	t.mutex.Lock()
+	idle := t.idle
+	t.mutex.Unlock()
+
+	select {
+	case <-idle:
+		return true
+	default:
+	}
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case <-idle:
+		return true
+	case <-timer.C:
+		return false
+	case <-done:
+		return false
+	}
+}
@@ -0,0 +1,51 @@
+// +build !integration
+
+package kafka
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Shopify/sarama"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOffsetTracker(t *testing.T) {
+	tracker := newOffsetTracker()
+	a := tracker.add("topic", 0, 10)
+	b := tracker.add("topic", 1, sarama.OffsetOldest)
+
+	acks := []*eventACK{
+		a.published(10),
+		a.published(11),
+		b.published(0),
+	}
+	assert.Empty(t, tracker.uncommitted())
+	assert.False(t, tracker.wait(10*time.Millisecond, nil))
+
+	acks[0].ACK()
+	acks[2].ACK()
+	offsets := tracker.uncommitted()
+	assert.Equal(t, map[string]map[int32]int64{
+		"topic": {0: 11, 1: 1},
+	}, offsets)
+
+	tracker.markCommitted(offsets)
+	assert.Empty(t, tracker.uncommitted())
+
+	acks[1].ACK()
+	assert.True(t, tracker.wait(10*time.Millisecond, nil))
+	assert.Equal(t, map[string]map[int32]int64{
+		"topic": {0: 12},
+	}, tracker.uncommitted())
+}
+
+func TestOffsetTrackerWaitDone(t *testing.T) {
+	tracker := newOffsetTracker()
+	assert.True(t, tracker.wait(0, nil))
+
+	tracker.add("topic", 0, 0).published(0)
+	done := make(chan struct{})
+	close(done)
+	assert.False(t, tracker.wait(time.Minute, done))
+}
@@ -0,0 +1,255 @@
+package kafka
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Shopify/sarama"
+
+	"github.com/elastic/beats/filebeat/channel"
+	"github.com/elastic/beats/filebeat/harvester"
+	"github.com/elastic/beats/filebeat/prospector"
+	"github.com/elastic/beats/libbeat/common"
+	"github.com/elastic/beats/libbeat/common/cfgwarn"
+	"github.com/elastic/beats/libbeat/logp"
+)
+
+func init() {
+	err := prospector.Register("kafka", NewProspector)
+	if err != nil {
+		panic(err)
+	}
+}
+
+// joinBackoff is the time to wait before joining the group again after a
+// retriable error
+const joinBackoff = 1 * time.Second
+
+// Prospector consumes topics from Kafka as a member of a consumer group
+type Prospector struct {
+	config       config
+	saramaConfig *sarama.Config
+	outlet       channel.Outleter
+	forwarder    *harvester.Forwarder
+	started      bool
+	done         chan struct{}
+	wg           sync.WaitGroup
+}
+
+// NewProspector creates a new kafka prospector
+func NewProspector(cfg *common.Config, outletFactory channel.Factory, context prospector.Context) (prospector.Prospectorer, error) {
+	cfgwarn.Experimental("Kafka prospector type is used")
+
+	config := defaultConfig
+	if err := cfg.Unpack(&config); err != nil {
+		return nil, err
+	}
+
+	saramaConfig, err := config.saramaConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	out, err := outletFactory(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Prospector{
+		config:       config,
+		saramaConfig: saramaConfig,
+		outlet:       out,
+		forwarder:    harvester.NewForwarder(out),
+		done:         make(chan struct{}),
+	}, nil
+}
+
+// Run starts consuming the topics. The group membership is handled in the
+// background, so Run is only effective the first time it's called.
+func (p *Prospector) Run() {
+	if p.started {
+		return
+	}
+	p.started = true
+
+	logp.Info("Starting kafka prospector for topics %v in group %v", p.config.Topics, p.config.GroupID)
+
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+		p.run()
+	}()
+}
+
+// Stop stops consuming, commits the acknowledged offsets and leaves the group
+func (p *Prospector) Stop() {
+	logp.Info("Stopping kafka prospector")
+	close(p.done)
+	p.outlet.Close()
+	p.wg.Wait()
+}
+
+// Wait stops the prospector, as consuming from Kafka never completes
+func (p *Prospector) Wait() {
+	p.Stop()
+}
+
+// run connects to Kafka and consumes the topics until the prospector is
+// stopped. The connection is established again after failures.
+func (p *Prospector) run() {
+	for {
+		client, err := sarama.NewClient(p.config.Hosts, p.saramaConfig)
+		if err != nil {
+			logp.Err("Error connecting to kafka hosts %v: %v", p.config.Hosts, err)
+		} else {
+			p.consume(client)
+			client.Close()
+		}
+
+		select {
+		case <-p.done:
+			return
+		case <-time.After(p.config.ConnectBackoff):
+		}
+	}
+}
+
+// consume joins the group and consumes the assigned partitions. After a
+// rebalance the group is joined again. It returns on unrecoverable errors or
+// when the prospector is stopped.
+func (p *Prospector) consume(client sarama.Client) {
+	consumer, err := sarama.NewConsumerFromClient(client)
+	if err != nil {
+		logp.Err("Error creating kafka consumer: %v", err)
+		return
+	}
+	defer consumer.Close()
+
+	member := newGroupMember(client, &p.config, strings.ToLower(p.config.Assignment))
+	defer func() {
+		if err := member.leave(); err != nil {
+			logp.Err("Error leaving kafka group %v: %v", p.config.GroupID, err)
+		}
+	}()
+
+	for {
+		select {
+		case <-p.done:
+			return
+		default:
+		}
+
+		partitions, err := member.join()
+		if err != nil {
+			switch err {
+			case sarama.ErrRebalanceInProgress, sarama.ErrUnknownMemberId, sarama.ErrIllegalGeneration,
+				sarama.ErrNotCoordinatorForConsumer, sarama.ErrConsumerCoordinatorNotAvailable:
+				logp.Debug("kafka", "Joining group %v failed, retrying: %v", p.config.GroupID, err)
+				select {
+				case <-p.done:
+					return
+				case <-time.After(joinBackoff):
+				}
+				continue
+			}
+			logp.Err("Error joining kafka group %v: %v", p.config.GroupID, err)
+			return
+		}
+		logp.Info("Kafka group %v assigned partitions: %v", p.config.GroupID, partitions)
+
+		if err := p.runSession(consumer, member, partitions); err != nil {
+			logp.Err("Error consuming from kafka: %v", err)
+			return
+		}
+	}
+}
+
+// runSession consumes the partitions assigned in one group generation. It
+// returns once the group rebalances or the prospector is stopped. Before
+// returning, it waits for the published events to be acknowledged and commits
+// their offsets, so the next owner of the partitions continues after them.
+func (p *Prospector) runSession(consumer sarama.Consumer, member *groupMember, partitions assignment) error {
+	committed, err := member.fetchOffsets(partitions)
+	if err != nil {
+		return err
+	}
+
+	tracker := newOffsetTracker()
+	var harvesters []*Harvester
+	var wg sync.WaitGroup
+	defer func() {
+		for _, h := range harvesters {
+			h.Stop()
+		}
+		wg.Wait()
+
+		// The group waits for its members until the session timeout, so only
+		// part of it can be used to wait for the pending events.
+		if !tracker.wait(p.config.SessionTimeout/2, p.done) {
+			logp.Info("Not all kafka events acknowledged before leaving generation %v", member.generation)
+		}
+		p.commit(member, tracker)
+	}()
+
+	initialOffset := initialOffsets[strings.ToLower(p.config.InitialOffset)]
+	for topic, ids := range partitions {
+		for _, id := range ids {
+			offset, found := committed[topic][id]
+			if !found {
+				offset = initialOffset
+			}
+
+			pc, err := consumer.ConsumePartition(topic, id, offset)
+			if err == sarama.ErrOffsetOutOfRange {
+				logp.Info("Committed offset %v of kafka topic %v partition %v is out of range, using initial offset", offset, topic, id)
+				offset = initialOffset
+				pc, err = consumer.ConsumePartition(topic, id, offset)
+			}
+			if err != nil {
+				return err
+			}
+
+			h := newHarvester(p.forwarder, pc, tracker.add(topic, id, offset), p.config.JSON)
+			harvesters = append(harvesters, h)
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				h.Run()
+			}()
+		}
+	}
+
+	heartbeat := time.NewTicker(p.config.HeartbeatInterval)
+	defer heartbeat.Stop()
+	commit := time.NewTicker(p.config.CommitInterval)
+	defer commit.Stop()
+
+	for {
+		select {
+		case <-p.done:
+			return nil
+		case <-commit.C:
+			p.commit(member, tracker)
+		case <-heartbeat.C:
+			if err := member.heartbeat(); err != nil {
+				logp.Info("Kafka group %v is rebalancing: %v", p.config.GroupID, err)
+				return nil
+			}
+		}
+	}
+}
+
+// commit commits the offsets of the acknowledged events
+func (p *Prospector) commit(member *groupMember, tracker *offsetTracker) {
+	offsets := tracker.uncommitted()
+	if len(offsets) == 0 {
+		return
+	}
+
+	if err := member.commit(offsets); err != nil {
+		logp.Err("Error committing kafka offsets of group %v: %v", p.config.GroupID, err)
+		return
+	}
+	tracker.markCommitted(offsets)
+}
@@ -33,6 +33,11 @@ var (
 		TailFiles:      false,
 		ScanSort:       "",
 		ScanOrder:      "asc",
+		FileIdentity:   FileIdentityNative,
+		Fingerprint: FingerprintConfig{
+			Offset: 0,
+			Length: 1024,
+		},
 
 		// Harvester
 		BufferSize: 16 * humanize.KiByte,
@@ -59,16 +64,18 @@ type config struct {
 	CleanInactive time.Duration `config:"clean_inactive" validate:"min=0"`
 
 	// Prospector
-	Enabled        bool            `config:"enabled"`
-	ExcludeFiles   []match.Matcher `config:"exclude_files"`
-	IgnoreOlder    time.Duration   `config:"ignore_older"`
-	Paths          []string        `config:"paths"`
-	ScanFrequency  time.Duration   `config:"scan_frequency" validate:"min=0,nonzero"`
-	CleanRemoved   bool            `config:"clean_removed"`
-	HarvesterLimit uint64          `config:"harvester_limit" validate:"min=0"`
-	Symlinks       bool            `config:"symlinks"`
-	TailFiles      bool            `config:"tail_files"`
-	RecursiveGlob  bool            `config:"recursive_glob.enabled"`
+	Enabled        bool              `config:"enabled"`
+	ExcludeFiles   []match.Matcher   `config:"exclude_files"`
+	IgnoreOlder    time.Duration     `config:"ignore_older"`
+	Paths          []string          `config:"paths"`
+	ScanFrequency  time.Duration     `config:"scan_frequency" validate:"min=0,nonzero"`
+	CleanRemoved   bool              `config:"clean_removed"`
+	HarvesterLimit uint64            `config:"harvester_limit" validate:"min=0"`
+	Symlinks       bool              `config:"symlinks"`
+	TailFiles      bool              `config:"tail_files"`
+	RecursiveGlob  bool              `config:"recursive_glob.enabled"`
+	FileIdentity   string            `config:"file_identity"`
+	Fingerprint    FingerprintConfig `config:"fingerprint"`
 
 	// Harvester
 	BufferSize int    `config:"harvester_buffer_size"`
@@ -81,6 +88,11 @@ type config struct {
 	MaxBytes     int                     `config:"max_bytes" validate:"min=0,nonzero"`
 	Multiline    *reader.MultilineConfig `config:"multiline"`
 	JSON         *reader.JSONConfig      `config:"json"`
+	DecodeCSV    *reader.CSVConfig       `config:"decode_csv"`
+	DecodeKV     *reader.KeyValueConfig  `config:"decode_kv"`
+
+	// Throughput limit of each harvester
+	RateLimit harvester.RateLimitConfig `config:"rate_limit.harvester"`
 }
 
 type LogConfig struct {
@@ -94,6 +106,32 @@ type LogConfig struct {
 	CloseTimeout  time.Duration `config:"close_timeout" validate:"min=0"`
 }
 
+// FingerprintConfig defines the part of the file used to compute the
+// fingerprint identifying the file
+type FingerprintConfig struct {
+	Offset int64 `config:"offset" validate:"min=0"`
+	Length int64 `config:"length" validate:"min=1"`
+}
+
+// Contains available file identity modes
+const (
+	// FileIdentityNative identifies files by inode and device (volume and file
+	// index on Windows)
+	FileIdentityNative = "native"
+	// FileIdentityFingerprint identifies files by the hash of their content
+	FileIdentityFingerprint = "fingerprint"
+	// FileIdentityNativeFingerprint identifies files by the native identity
+	// and the hash of their content
+	FileIdentityNativeFingerprint = "native_fingerprint"
+)
+
+// ValidFileIdentity of valid file identity modes
+var ValidFileIdentity = map[string]struct{}{
+	FileIdentityNative:            {},
+	FileIdentityFingerprint:       {},
+	FileIdentityNativeFingerprint: {},
+}
+
 // Contains available scan options
 const (
 	ScanOrderAsc     = "asc"
@@ -151,6 +189,22 @@ func (c *config) Validate() error {
 		return fmt.Errorf("When using the JSON decoder and line filtering together, you need to specify a message_key value")
 	}
 
+	if c.DecodeCSV != nil {
+		cfgwarn.Experimental("decode_csv is used.")
+	}
+	if c.DecodeKV != nil {
+		cfgwarn.Experimental("decode_kv is used.")
+	}
+
+	if c.DecodeCSV != nil && c.DecodeKV != nil {
+		return fmt.Errorf("decode_csv and decode_kv cannot be used together")
+	}
+
+	if c.JSON != nil && len(c.JSON.MessageKey) == 0 &&
+		(c.DecodeCSV != nil || c.DecodeKV != nil) {
+		return fmt.Errorf("When using the JSON decoder and decode_csv or decode_kv together, you need to specify a message_key value")
+	}
+
 	if c.ScanSort != "" {
 		cfgwarn.Experimental("scan_sort is used.")
 
@@ -165,9 +219,21 @@ func (c *config) Validate() error {
 		}
 	}
 
+	if c.FileIdentity == "" {
+		c.FileIdentity = FileIdentityNative
+	}
+	if _, ok := ValidFileIdentity[c.FileIdentity]; !ok {
+		return fmt.Errorf("Invalid file identity: %v", c.FileIdentity)
+	}
+
 	return nil
 }
 
+// useFingerprint returns true if the file identity uses the fingerprint
+func (c *config) useFingerprint() bool {
+	return c.FileIdentity == FileIdentityFingerprint || c.FileIdentity == FileIdentityNativeFingerprint
+}
+
 func (c *config) resolvePaths() error {
 	var paths []string
 	if !c.RecursiveGlob {
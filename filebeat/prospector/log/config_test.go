@@ -9,6 +9,7 @@ import (
 	"github.com/stretchr/testify/assert"
 
 	"github.com/elastic/beats/filebeat/harvester"
+	"github.com/elastic/beats/filebeat/harvester/reader"
 )
 
 func TestCleanOlderError(t *testing.T) {
@@ -53,3 +54,26 @@ func TestCleanOlderIgnoreOlder(t *testing.T) {
 	err := config.Validate()
 	assert.NoError(t, err)
 }
+
+func TestDecodersError(t *testing.T) {
+	config := config{
+		Paths: []string{"hello"},
+		ForwarderConfig: harvester.ForwarderConfig{
+			Type: "log",
+		},
+		DecodeCSV: &reader.CSVConfig{},
+		DecodeKV:  &reader.KeyValueConfig{},
+	}
+
+	err := config.Validate()
+	assert.Error(t, err)
+
+	config.DecodeKV = nil
+	config.JSON = &reader.JSONConfig{}
+	err = config.Validate()
+	assert.Error(t, err)
+
+	config.JSON.MessageKey = "log"
+	err = config.Validate()
+	assert.NoError(t, err)
+}
@@ -37,12 +37,13 @@ import (
 )
 
 var (
-	harvesterMetrics = monitoring.Default.NewRegistry("filebeat.harvester")
+	harvesterMetrics = harvester.Metrics
 
 	harvesterStarted   = monitoring.NewInt(harvesterMetrics, "started")
 	harvesterClosed    = monitoring.NewInt(harvesterMetrics, "closed")
 	harvesterRunning   = monitoring.NewInt(harvesterMetrics, "running")
 	harvesterOpenFiles = monitoring.NewInt(harvesterMetrics, "open_files")
+	harvesterThrottled = monitoring.NewInt(harvesterMetrics, "throttled.harvester_ms")
 
 	ErrFileTruncate = errors.New("detected file being truncated")
 	ErrRenamed      = errors.New("file was renamed")
@@ -74,7 +75,11 @@ type Harvester struct {
 
 	// event/state publishing
 	forwarder    *harvester.Forwarder
+	limiter      *harvester.RateLimiter
 	publishState func(*util.Data) bool
+
+	// collects the results of the harvester in batch mode, can be nil
+	report *harvester.Report
 }
 
 // NewHarvester creates a new harvester
@@ -111,6 +116,8 @@ func NewHarvester(
 		h.state.TTL = h.config.CleanInactive
 	}
 
+	h.limiter = harvester.NewRateLimiter(h.config.RateLimit, harvesterThrottled)
+
 	// Add outlet signal so harvester can also stop itself
 	outlet = channel.CloseOnSignal(outlet, h.done)
 	h.forwarder = harvester.NewForwarder(outlet)
@@ -232,6 +239,7 @@ func (h *Harvester) Run() error {
 				logp.Info("File is inactive: %s. Closing because close_inactive of %v reached.", h.state.Source, h.config.CloseInactive)
 			default:
 				logp.Err("Read line error: %s; File: ", err, h.state.Source)
+				h.report.Error(h.state.Source, err)
 			}
 			return nil
 		}
@@ -285,12 +293,27 @@ func (h *Harvester) Run() error {
 			}
 		}
 
+		events := 0
+		if data.HasEvent() {
+			events = 1
+		}
+
+		// Wait if the harvester exceeds its rate limit. All bytes read count,
+		// also the ones of skipped lines. The state is only updated once the
+		// event is sent, so the registry stays consistent if the harvester is
+		// stopped while waiting.
+		if h.limiter != nil && !h.limiter.Wait(events, message.Bytes, h.done) {
+			return nil
+		}
+
 		// Always send event to update state, also if lines was skipped
 		// Stop harvester in case of an error
 		if !h.sendEvent(data) {
 			return nil
 		}
 
+		h.report.Published(state.Source, events, message.Bytes)
+
 		// Update state of harvester as successfully sent
 		h.state = state
 	}
@@ -441,8 +464,14 @@ func (h *Harvester) getState() file.State {
 
 	state := h.state
 
-	// refreshes the values in State with the values from the harvester itself
-	state.FileStateOS = file.GetOSState(h.state.Fileinfo)
+	// refreshes the values in State with the values from the harvester itself.
+	// The fingerprint is kept, as it identifies the file without the native
+	// identity if file_identity is set to fingerprint.
+	if h.config.FileIdentity != FileIdentityFingerprint {
+		fingerprint := state.FileStateOS.Fingerprint
+		state.FileStateOS = file.GetOSState(h.state.Fileinfo)
+		state.FileStateOS.Fingerprint = fingerprint
+	}
 	return state
 }
 
@@ -477,12 +506,12 @@ func (h *Harvester) cleanup() {
 //
 // It creates a chain of readers which looks as following:
 //
-//   limit -> (multiline -> timeout) -> strip_newline -> json -> encode -> line -> log_file
+//   limit -> (csv|kv) -> (multiline -> timeout) -> strip_newline -> json -> encode -> line -> log_file
 //
 // Each reader on the left, contains the reader on the right and calls `Next()` to fetch more data.
 // At the base of all readers the the log_file reader. That means in the data is flowing in the opposite direction:
 //
-//   log_file -> line -> encode -> json -> strip_newline -> (timeout -> multiline) -> limit
+//   log_file -> line -> encode -> json -> strip_newline -> (timeout -> multiline) -> (csv|kv) -> limit
 //
 // log_file implements io.Reader interface and encode reader is an adapter for io.Reader to
 // reader.Reader also handling file encodings. All other readers implement reader.Reader
@@ -516,5 +545,45 @@ func (h *Harvester) newLogFileReader() (reader.Reader, error) {
 		}
 	}
 
+	if h.config.DecodeCSV != nil {
+		csv := reader.NewCSV(r, h.config.DecodeCSV)
+		if h.config.DecodeCSV.Header && h.state.Offset > 0 {
+			if err := h.readCSVHeader(csv); err != nil {
+				return nil, err
+			}
+		}
+		r = csv
+	}
+
+	if h.config.DecodeKV != nil {
+		r = reader.NewKeyValue(r, h.config.DecodeKV)
+	}
+
 	return reader.NewLimit(r, h.config.MaxBytes), nil
 }
+
+// readCSVHeader reads the header line from the beginning of the file, as the
+// harvester continues reading after it.
+func (h *Harvester) readCSVHeader(csv *reader.CSV) error {
+	f, err := file.ReadOpen(h.state.Source)
+	if err != nil {
+		return fmt.Errorf("Failed opening %s to read the CSV header: %s", h.state.Source, err)
+	}
+	defer f.Close()
+
+	enc, err := h.encodingFactory(f)
+	if err != nil {
+		return err
+	}
+
+	r, err := reader.NewEncode(f, enc, h.config.BufferSize)
+	if err != nil {
+		return err
+	}
+
+	message, err := reader.NewStripNewline(r).Next()
+	if err != nil {
+		return fmt.Errorf("Failed reading CSV header of %s: %s", h.state.Source, err)
+	}
+	return csv.SetHeader(message.Content)
+}
@@ -45,6 +45,7 @@ type Prospector struct {
 	outlet      channel.Outleter
 	stateOutlet channel.Outleter
 	done        chan struct{}
+	report      *harvester.Report
 }
 
 // NewProspector instantiates a new Log
@@ -78,6 +79,7 @@ func NewProspector(
 		stateOutlet: stateOut,
 		states:      &file.States{},
 		done:        context.Done,
+		report:      context.Report,
 	}
 
 	if err := cfg.Unpack(&p.config); err != nil {
@@ -125,6 +127,8 @@ func (p *Prospector) loadStates(states []file.State) error {
 				return fmt.Errorf("Can only start a prospector when all related states are finished: %+v", state)
 			}
 
+			state = p.migrateState(state)
+
 			// Update prospector states and send new states to registry
 			err := p.updateState(state)
 			if err != nil {
@@ -138,6 +142,58 @@ func (p *Prospector) loadStates(states []file.State) error {
 	return nil
 }
 
+// migrateState converts a state stored with another file_identity mode, so
+// files are not harvested again after the mode is changed. The state is only
+// converted if the file at its source path is still the file identified by
+// the stored state. The old registry entry is removed.
+func (p *Prospector) migrateState(state file.State) file.State {
+	info, err := os.Stat(state.Source)
+	if err != nil {
+		return state
+	}
+
+	newState, err := getFileState(state.Source, info, p)
+	if err != nil || newState.FileStateOS.IsSame(state.FileStateOS) {
+		return state
+	}
+
+	// Compare the stored state to all identities of the file
+	native := file.GetOSState(info)
+	candidates := []file.StateOS{native}
+	fingerprint := newState.FileStateOS.Fingerprint
+	if fingerprint == "" && state.FileStateOS.Fingerprint != "" {
+		fingerprint, _ = file.Fingerprint(state.Source, info, p.config.Fingerprint.Offset, p.config.Fingerprint.Length)
+	}
+	if fingerprint != "" {
+		withFingerprint := native
+		withFingerprint.Fingerprint = fingerprint
+		candidates = append(candidates, withFingerprint, file.StateOS{Fingerprint: fingerprint})
+	}
+
+	for _, candidate := range candidates {
+		if !candidate.IsSame(state.FileStateOS) {
+			continue
+		}
+
+		logp.Info("Migrating state of %s to file_identity %s", state.Source, p.config.FileIdentity)
+
+		// Remove the old registry entry
+		old := state
+		old.TTL = 0
+		data := util.NewData()
+		data.SetState(old)
+		if !p.outlet.OnEvent(data) {
+			return state
+		}
+
+		state.Id = ""
+		state.FileStateOS = newState.FileStateOS
+		return state
+	}
+
+	return state
+}
+
 // Run runs the prospector
 func (p *Prospector) Run() {
 	logp.Debug("prospector", "Start next scan")
@@ -178,8 +234,12 @@ func (p *Prospector) Run() {
 				}
 			} else {
 				// Check if existing source on disk and state are the same. Remove if not the case.
-				newState := file.NewState(stat, state.Source, p.config.Type)
-				if !newState.FileStateOS.IsSame(state.FileStateOS) {
+				newState, err := getFileState(state.Source, stat, p)
+				if err != nil && err != file.ErrFileTooSmall {
+					logp.Err("Prospector state for %s was not removed: %s", state.Source, err)
+					continue
+				}
+				if err == file.ErrFileTooSmall || !newState.FileStateOS.IsSame(state.FileStateOS) {
 					p.removeState(state)
 					logp.Debug("prospector", "Remove state for file as file removed or renamed: %s", state.Source)
 				}
@@ -357,6 +417,19 @@ func getFileState(path string, info os.FileInfo, p *Prospector) (file.State, err
 	logp.Debug("prospector", "Check file for harvesting: %s", absolutePath)
 	// Create new state for comparison
 	newState := file.NewState(info, absolutePath, p.config.Type)
+
+	if p.config.useFingerprint() {
+		fingerprint, err := file.Fingerprint(absolutePath, info, p.config.Fingerprint.Offset, p.config.Fingerprint.Length)
+		if err != nil {
+			return file.State{}, err
+		}
+		// The fingerprint replaces the native identity
+		if p.config.FileIdentity == FileIdentityFingerprint {
+			newState.FileStateOS = file.StateOS{}
+		}
+		newState.FileStateOS.Fingerprint = fingerprint
+	}
+
 	return newState, nil
 }
 
@@ -409,9 +482,17 @@ func (p *Prospector) scan() {
 		}
 
 		newState, err := getFileState(path, info, p)
+		if err == file.ErrFileTooSmall {
+			logp.Debug("prospector", "Defer harvesting of file smaller than the fingerprint: %s", path)
+			p.report.Error(path, err)
+			continue
+		}
 		if err != nil {
 			logp.Err("Skipping file %s due to error %s", path, err)
+			p.report.Error(path, err)
+			continue
 		}
+		p.report.Add(newState.Source)
 
 		// Load last state
 		lastState := p.states.FindPrevious(newState)
@@ -431,6 +512,7 @@ func (p *Prospector) scan() {
 			err := p.startHarvester(newState, 0)
 			if err != nil {
 				logp.Err("Harvester could not be started on new file: %s, Err: %s", newState.Source, err)
+				p.report.Error(newState.Source, err)
 			}
 		} else {
 			p.harvestExistingFile(newState, lastState)
@@ -453,6 +535,7 @@ func (p *Prospector) harvestExistingFile(newState file.State, oldState file.Stat
 		err := p.startHarvester(newState, oldState.Offset)
 		if err != nil {
 			logp.Err("Harvester could not be started on existing file: %s, Err: %s", newState.Source, err)
+			p.report.Error(newState.Source, err)
 		}
 		return
 	}
@@ -463,6 +546,7 @@ func (p *Prospector) harvestExistingFile(newState file.State, oldState file.Stat
 		err := p.startHarvester(newState, 0)
 		if err != nil {
 			logp.Err("Harvester could not be started on truncated file: %s, Err: %s", newState.Source, err)
+			p.report.Error(newState.Source, err)
 		}
 
 		filesTruncated.Add(1)
@@ -580,8 +664,12 @@ func (p *Prospector) createHarvester(state file.State) (*Harvester, error) {
 		},
 		outlet,
 	)
+	if err != nil {
+		return nil, err
+	}
 
-	return h, err
+	h.report = p.report
+	return h, nil
 }
 
 // startHarvester starts a new harvester with the given offset
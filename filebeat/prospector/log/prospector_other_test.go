@@ -3,7 +3,11 @@
 package log
 
 import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/elastic/beats/filebeat/input/file"
 	"github.com/elastic/beats/filebeat/util"
@@ -152,3 +156,124 @@ type TestOutlet struct{}
 
 func (o TestOutlet) OnEvent(event *util.Data) bool { return true }
 func (o TestOutlet) Close() error                  { return nil }
+
+// recordingOutlet records the states sent to the outlet
+type recordingOutlet struct {
+	states []file.State
+}
+
+func (o *recordingOutlet) OnEvent(event *util.Data) bool {
+	o.states = append(o.states, event.GetState())
+	return true
+}
+func (o *recordingOutlet) Close() error { return nil }
+
+func writeTestFile(t *testing.T, dir, name, content string) (string, os.FileInfo) {
+	path := filepath.Join(dir, name)
+	if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return path, info
+}
+
+func TestGetFileStateFingerprint(t *testing.T) {
+	dir, err := ioutil.TempDir("", "fingerprint")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	small, smallInfo := writeTestFile(t, dir, "small.log", "short\n")
+	path, info := writeTestFile(t, dir, "test.log", "first line\nsecond line\n")
+	native := file.GetOSState(info)
+
+	p := Prospector{config: defaultConfig}
+	p.config.Fingerprint.Length = 10
+
+	state, err := getFileState(path, info, &p)
+	assert.NoError(t, err)
+	assert.Equal(t, native, state.FileStateOS)
+
+	p.config.FileIdentity = FileIdentityFingerprint
+	state, err = getFileState(path, info, &p)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(0), state.FileStateOS.Inode)
+	assert.NotEmpty(t, state.FileStateOS.Fingerprint)
+
+	_, err = getFileState(small, smallInfo, &p)
+	assert.Equal(t, file.ErrFileTooSmall, err)
+
+	// Files with the same beginning have the same fingerprint, which is
+	// combined with the native identity in native_fingerprint mode
+	other, otherInfo := writeTestFile(t, dir, "other.log", "first line\nother line\n")
+	otherState, err := getFileState(other, otherInfo, &p)
+	assert.NoError(t, err)
+	assert.True(t, state.FileStateOS.IsSame(otherState.FileStateOS))
+
+	p.config.FileIdentity = FileIdentityNativeFingerprint
+	state, err = getFileState(path, info, &p)
+	assert.NoError(t, err)
+	otherState, err = getFileState(other, otherInfo, &p)
+	assert.NoError(t, err)
+	assert.Equal(t, native.Inode, state.FileStateOS.Inode)
+	assert.False(t, state.FileStateOS.IsSame(otherState.FileStateOS))
+}
+
+func TestMigrateState(t *testing.T) {
+	dir, err := ioutil.TempDir("", "fingerprint")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path, info := writeTestFile(t, dir, "test.log", "first line\nsecond line\n")
+	replaced, _ := writeTestFile(t, dir, "replaced.log", "first line\nsecond line\n")
+
+	stored := file.NewState(info, path, "log")
+	stored.Offset = 11
+	stored.Finished = true
+
+	// The inode of replaced.log doesn't match the stored state
+	storedReplaced := stored
+	storedReplaced.Source = replaced
+
+	outlet := &recordingOutlet{}
+	p := Prospector{
+		config: defaultConfig,
+		states: &file.States{},
+		outlet: outlet,
+	}
+	p.config.Paths = []string{filepath.Join(dir, "*.log")}
+	p.config.FileIdentity = FileIdentityFingerprint
+	p.config.Fingerprint.Length = 10
+
+	err = p.loadStates([]file.State{stored, storedReplaced})
+	assert.NoError(t, err)
+
+	states := p.states.GetStates()
+	if assert.Len(t, states, 2) {
+		assert.NotEmpty(t, states[0].FileStateOS.Fingerprint)
+		assert.Equal(t, uint64(0), states[0].FileStateOS.Inode)
+		assert.Equal(t, int64(11), states[0].Offset)
+		assert.Equal(t, stored.FileStateOS, states[1].FileStateOS)
+	}
+
+	// The old registry entry is removed, the migrated state is added
+	if assert.Len(t, outlet.states, 3) {
+		assert.Equal(t, time.Duration(0), outlet.states[0].TTL)
+		assert.Equal(t, stored.FileStateOS, outlet.states[0].FileStateOS)
+		assert.Equal(t, states[0].FileStateOS, outlet.states[1].FileStateOS)
+	}
+
+	// Migrating back to the native identity
+	outlet.states = nil
+	p.states = &file.States{}
+	p.config.FileIdentity = FileIdentityNative
+	err = p.loadStates([]file.State{states[0]})
+	assert.NoError(t, err)
+	assert.Equal(t, stored.FileStateOS, p.states.GetStates()[0].FileStateOS)
+}
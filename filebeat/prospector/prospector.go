@@ -7,6 +7,7 @@ import (
 	"github.com/mitchellh/hashstructure"
 
 	"github.com/elastic/beats/filebeat/channel"
+	"github.com/elastic/beats/filebeat/harvester"
 	"github.com/elastic/beats/filebeat/input/file"
 	"github.com/elastic/beats/libbeat/common"
 	"github.com/elastic/beats/libbeat/logp"
@@ -30,12 +31,14 @@ type Prospector struct {
 	beatDone     chan struct{}
 }
 
-// NewProspector instantiates a new prospector
+// NewProspector instantiates a new prospector. The report is optional and
+// collects the results of the harvesters.
 func New(
 	conf *common.Config,
 	outlet channel.Factory,
 	beatDone chan struct{},
 	states []file.State,
+	report *harvester.Report,
 ) (*Prospector, error) {
 	prospector := &Prospector{
 		config:   defaultConfig,
@@ -67,6 +70,7 @@ func New(
 		States:   states,
 		Done:     prospector.done,
 		BeatDone: prospector.beatDone,
+		Report:   report,
 	}
 	var prospectorer Prospectorer
 	prospectorer, err = f(conf, outlet, context)
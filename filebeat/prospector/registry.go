@@ -4,6 +4,7 @@ import (
 	"fmt"
 
 	"github.com/elastic/beats/filebeat/channel"
+	"github.com/elastic/beats/filebeat/harvester"
 	"github.com/elastic/beats/filebeat/input/file"
 	"github.com/elastic/beats/libbeat/common"
 	"github.com/elastic/beats/libbeat/logp"
@@ -13,6 +14,7 @@ type Context struct {
 	States   []file.State
 	Done     chan struct{}
 	BeatDone chan struct{}
+	Report   *harvester.Report // collects the results per file in batch mode, can be nil
 }
 
 type Factory func(config *common.Config, outletFactory channel.Factory, context Context) (Prospectorer, error)
@@ -25,7 +25,7 @@ func NewRunnerFactory(outlet channel.Factory, registrar *registrar.Registrar, be
 
 // Create creates a prospector based on a config
 func (r *RunnerFactory) Create(c *common.Config) (cfgfile.Runner, error) {
-	p, err := New(c, r.outlet, r.beatDone, r.registrar.GetStates())
+	p, err := New(c, r.outlet, r.beatDone, r.registrar.GetStates(), nil)
 	if err != nil {
 		// In case of error with loading state, prospector is still returned
 		return p, err
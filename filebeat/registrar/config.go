@@ -0,0 +1,58 @@
+package registrar
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/dustin/go-humanize"
+)
+
+// Storage types supported by the registrar
+const (
+	FileStorage = "file"
+	LogStorage  = "log"
+)
+
+// Fsync policies for the log storage
+const (
+	FsyncAlways   = "always"
+	FsyncInterval = "interval"
+	FsyncNever    = "never"
+)
+
+// StorageConfig defines how the registry states are persisted on disk
+type StorageConfig struct {
+	Type           string        `config:"type"`
+	Fsync          string        `config:"fsync"`
+	FsyncInterval  time.Duration `config:"fsync_interval" validate:"min=0"`
+	CheckpointSize int64         `config:"checkpoint_size" validate:"min=0"`
+}
+
+var (
+	DefaultStorageConfig = StorageConfig{
+		Type:           FileStorage,
+		Fsync:          FsyncAlways,
+		FsyncInterval:  1 * time.Second,
+		CheckpointSize: 10 * humanize.MiByte,
+	}
+)
+
+func (c *StorageConfig) Validate() error {
+	switch c.Type {
+	case FileStorage, LogStorage:
+	default:
+		return fmt.Errorf("Invalid registry storage type: %v", c.Type)
+	}
+
+	switch c.Fsync {
+	case FsyncAlways, FsyncNever:
+	case FsyncInterval:
+		if c.FsyncInterval <= 0 {
+			return fmt.Errorf("fsync_interval must be > 0 when fsync is set to %s", FsyncInterval)
+		}
+	default:
+		return fmt.Errorf("Invalid registry fsync policy: %v", c.Fsync)
+	}
+
+	return nil
+}
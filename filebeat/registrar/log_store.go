@@ -0,0 +1,195 @@
+package registrar
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"time"
+
+	"github.com/elastic/beats/filebeat/input/file"
+	"github.com/elastic/beats/libbeat/logp"
+)
+
+// logStore appends all state changes to a log file next to the registry file.
+// As soon as the log file grows over the configured checkpoint size, all states
+// are written to the registry file (the checkpoint) and the log is truncated.
+//
+// The checkpoint has the same format as the registry file written by the file
+// storage, so an existing registry is picked up without any conversion.
+type logStore struct {
+	registryFile string
+	logFile      string
+	config       StorageConfig
+
+	file     logFile
+	size     int64
+	torn     bool // A failed write left an incomplete entry in the log
+	lastSync time.Time
+}
+
+// logFile is the file the changes are appended to.
+type logFile interface {
+	io.WriteCloser
+	io.Seeker
+	Truncate(size int64) error
+	Sync() error
+}
+
+func newLogStore(registryFile string, config StorageConfig) *logStore {
+	return &logStore{
+		registryFile: registryFile,
+		logFile:      logFilePath(registryFile),
+		config:       config,
+	}
+}
+
+// Load reads the checkpoint and applies all changes found in the log file. A
+// partially written entry at the end of the log is truncated.
+func (s *logStore) Load() ([]file.State, error) {
+	states, err := readCheckpoint(s.registryFile)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := os.OpenFile(s.logFile, os.O_RDWR|os.O_CREATE, 0600)
+	if err != nil {
+		return nil, err
+	}
+
+	states, offset, err := replayLog(f, states)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	// Drop torn writes, so new entries are appended after the last valid one
+	if err := f.Truncate(offset); err != nil {
+		f.Close()
+		return nil, err
+	}
+	if _, err := f.Seek(offset, io.SeekStart); err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	s.file = f
+	s.size = offset
+	s.lastSync = time.Now()
+
+	statesCurrent.Set(int64(len(states)))
+
+	return states, nil
+}
+
+// Write appends the changes to the log file and creates a new checkpoint if the
+// log file has reached the checkpoint size.
+func (s *logStore) Write(states *file.States, changes []stateChange) error {
+	if len(changes) == 0 {
+		return nil
+	}
+
+	var buf []byte
+	for _, change := range changes {
+		entry, err := json.Marshal(change)
+		if err != nil {
+			logp.Err("Error when encoding the state: %s", err)
+			return err
+		}
+		buf = append(buf, entry...)
+		buf = append(buf, '\n')
+	}
+
+	if s.torn {
+		if err := s.rollback(); err != nil {
+			return err
+		}
+	}
+
+	n, err := s.file.Write(buf)
+	if err != nil {
+		// Entries appended after an incomplete one are lost on replay, so the
+		// log is truncated back to the last complete entry.
+		if n > 0 {
+			s.torn = true
+			if rerr := s.rollback(); rerr != nil {
+				logp.Err("Failed to remove incomplete registry log entry: %v", rerr)
+			}
+		}
+		return err
+	}
+	s.size += int64(n)
+	registryLogWrites.Add(1)
+
+	if err := s.sync(); err != nil {
+		return err
+	}
+
+	if s.config.CheckpointSize > 0 && s.size >= s.config.CheckpointSize {
+		return s.checkpoint(states.GetStates())
+	}
+	return nil
+}
+
+// Close writes a last checkpoint, so the log does not have to be replayed on
+// the next start.
+func (s *logStore) Close(states *file.States) error {
+	if s.file == nil {
+		return writeCheckpoint(s.registryFile, states.GetStates())
+	}
+
+	err := s.checkpoint(states.GetStates())
+	if cerr := s.file.Close(); err == nil {
+		err = cerr
+	}
+	s.file = nil
+	return err
+}
+
+// rollback truncates the log to the size of the complete entries written.
+func (s *logStore) rollback() error {
+	if err := s.file.Truncate(s.size); err != nil {
+		return err
+	}
+	if _, err := s.file.Seek(s.size, io.SeekStart); err != nil {
+		return err
+	}
+	s.torn = false
+	return nil
+}
+
+func (s *logStore) sync() error {
+	switch s.config.Fsync {
+	case FsyncNever:
+		return nil
+	case FsyncInterval:
+		if time.Since(s.lastSync) < s.config.FsyncInterval {
+			return nil
+		}
+	}
+
+	s.lastSync = time.Now()
+	return s.file.Sync()
+}
+
+// checkpoint writes all states to the registry file and truncates the log. If
+// the process crashes after the checkpoint was written, but before the log was
+// truncated, the log is replayed on top of the new checkpoint. This is safe, as
+// every entry contains the complete state.
+func (s *logStore) checkpoint(states []file.State) error {
+	logp.Debug("registrar", "Compacting registry log of %d bytes", s.size)
+
+	if err := writeCheckpoint(s.registryFile, states); err != nil {
+		return err
+	}
+
+	if err := s.file.Truncate(0); err != nil {
+		return err
+	}
+	if _, err := s.file.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	s.size = 0
+	registryCheckpoints.Add(1)
+
+	return s.file.Sync()
+}
@@ -0,0 +1,306 @@
+// +build !integration
+
+package registrar
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/elastic/beats/filebeat/input/file"
+)
+
+func TestLogStoreReplay(t *testing.T) {
+	dir, registry := setupRegistryDir(t)
+	defer os.RemoveAll(dir)
+
+	states := newTestStates(t, dir, 3)
+	require.NoError(t, writeCheckpoint(registry, nil))
+
+	config := DefaultStorageConfig
+	config.Type = LogStorage
+
+	store := newLogStore(registry, config)
+	loaded, err := store.Load()
+	require.NoError(t, err)
+	assert.Len(t, loaded, 0)
+
+	all := file.NewStates()
+	for _, state := range states {
+		all.Update(state)
+	}
+	removed := states[1]
+	all.SetStates([]file.State{states[0], states[2]})
+
+	require.NoError(t, store.Write(all, []stateChange{
+		{Op: opSet, State: states[0]},
+		{Op: opSet, State: states[1]},
+	}))
+	states[0].Offset = 42
+	require.NoError(t, store.Write(all, []stateChange{
+		{Op: opSet, State: states[0]},
+		{Op: opSet, State: states[2]},
+		{Op: opRemove, State: removed},
+	}))
+	store.file.Close()
+
+	// The checkpoint must not have been touched
+	checkpoint, err := readCheckpoint(registry)
+	require.NoError(t, err)
+	assert.Len(t, checkpoint, 0)
+
+	loaded, err = newLogStore(registry, config).Load()
+	require.NoError(t, err)
+	assertSameStates(t, []file.State{states[0], states[2]}, loaded)
+}
+
+func TestLogStoreTornWrite(t *testing.T) {
+	dir, registry := setupRegistryDir(t)
+	defer os.RemoveAll(dir)
+
+	states := newTestStates(t, dir, 2)
+	require.NoError(t, writeCheckpoint(registry, nil))
+
+	config := DefaultStorageConfig
+	config.Type = LogStorage
+
+	store := newLogStore(registry, config)
+	_, err := store.Load()
+	require.NoError(t, err)
+	require.NoError(t, store.Write(file.NewStates(), []stateChange{{Op: opSet, State: states[0]}}))
+	validSize := store.size
+	store.file.Close()
+
+	// Simulate a crash while writing the second entry
+	f, err := os.OpenFile(logFilePath(registry), os.O_WRONLY|os.O_APPEND, 0600)
+	require.NoError(t, err)
+	_, err = f.WriteString(`{"op":"set","state":{"source":"` + states[1].Source)
+	require.NoError(t, err)
+	f.Close()
+
+	store = newLogStore(registry, config)
+	loaded, err := store.Load()
+	require.NoError(t, err)
+	assertSameStates(t, states[:1], loaded)
+
+	// The incomplete entry must be removed, so new entries stay readable
+	require.NoError(t, store.Write(file.NewStates(), []stateChange{{Op: opSet, State: states[1]}}))
+	store.file.Close()
+
+	info, err := os.Stat(logFilePath(registry))
+	require.NoError(t, err)
+	assert.True(t, info.Size() > validSize)
+
+	loaded, err = newLogStore(registry, config).Load()
+	require.NoError(t, err)
+	assertSameStates(t, states, loaded)
+}
+
+func TestLogStoreFailedWrite(t *testing.T) {
+	dir, registry := setupRegistryDir(t)
+	defer os.RemoveAll(dir)
+
+	states := newTestStates(t, dir, 3)
+	require.NoError(t, writeCheckpoint(registry, nil))
+
+	config := DefaultStorageConfig
+	config.Type = LogStorage
+
+	store := newLogStore(registry, config)
+	_, err := store.Load()
+	require.NoError(t, err)
+	require.NoError(t, store.Write(file.NewStates(), []stateChange{{Op: opSet, State: states[0]}}))
+	validSize := store.size
+
+	// The disk gets full in the middle of the second write
+	f := &failingFile{logFile: store.file, fail: true}
+	store.file = f
+	err = store.Write(file.NewStates(), []stateChange{{Op: opSet, State: states[1]}})
+	assert.Equal(t, errNoSpace, err)
+	assert.Equal(t, validSize, store.size)
+
+	info, err := os.Stat(logFilePath(registry))
+	require.NoError(t, err)
+	assert.Equal(t, validSize, info.Size())
+
+	// Writes after the failure must be readable on restart
+	f.fail = false
+	require.NoError(t, store.Write(file.NewStates(), []stateChange{
+		{Op: opSet, State: states[1]},
+		{Op: opSet, State: states[2]},
+	}))
+	store.file.Close()
+
+	loaded, err := newLogStore(registry, config).Load()
+	require.NoError(t, err)
+	assertSameStates(t, states, loaded)
+}
+
+func TestLogStoreFailedRollback(t *testing.T) {
+	dir, registry := setupRegistryDir(t)
+	defer os.RemoveAll(dir)
+
+	states := newTestStates(t, dir, 2)
+	require.NoError(t, writeCheckpoint(registry, nil))
+
+	config := DefaultStorageConfig
+	config.Type = LogStorage
+
+	store := newLogStore(registry, config)
+	_, err := store.Load()
+	require.NoError(t, err)
+
+	// The incomplete entry can't be removed right away
+	f := &failingFile{logFile: store.file, fail: true, failTruncate: true}
+	store.file = f
+	assert.Error(t, store.Write(file.NewStates(), []stateChange{{Op: opSet, State: states[0]}}))
+	assert.Error(t, store.Write(file.NewStates(), []stateChange{{Op: opSet, State: states[0]}}))
+
+	// It is removed before the next write
+	f.fail, f.failTruncate = false, false
+	require.NoError(t, store.Write(file.NewStates(), []stateChange{
+		{Op: opSet, State: states[0]},
+		{Op: opSet, State: states[1]},
+	}))
+	store.file.Close()
+
+	loaded, err := newLogStore(registry, config).Load()
+	require.NoError(t, err)
+	assertSameStates(t, states, loaded)
+}
+
+func TestLogStoreCheckpoint(t *testing.T) {
+	dir, registry := setupRegistryDir(t)
+	defer os.RemoveAll(dir)
+
+	states := newTestStates(t, dir, 2)
+	require.NoError(t, writeCheckpoint(registry, nil))
+
+	config := DefaultStorageConfig
+	config.Type = LogStorage
+	config.CheckpointSize = 1
+
+	all := file.NewStates()
+	all.SetStates(states)
+
+	store := newLogStore(registry, config)
+	_, err := store.Load()
+	require.NoError(t, err)
+	require.NoError(t, store.Write(all, []stateChange{{Op: opSet, State: states[0]}}))
+
+	// Every write exceeds the checkpoint size of 1 byte
+	assert.Equal(t, int64(0), store.size)
+	checkpoint, err := readCheckpoint(registry)
+	require.NoError(t, err)
+	assertSameStates(t, states, checkpoint)
+
+	require.NoError(t, store.Close(all))
+	info, err := os.Stat(logFilePath(registry))
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), info.Size())
+}
+
+func TestStorageMigration(t *testing.T) {
+	dir, registry := setupRegistryDir(t)
+	defer os.RemoveAll(dir)
+
+	states := newTestStates(t, dir, 2)
+
+	// Registry written by the file storage is used as checkpoint
+	require.NoError(t, writeCheckpoint(registry, states[:1]))
+
+	config := DefaultStorageConfig
+	config.Type = LogStorage
+
+	logStore := newLogStore(registry, config)
+	loaded, err := logStore.Load()
+	require.NoError(t, err)
+	assertSameStates(t, states[:1], loaded)
+
+	require.NoError(t, logStore.Write(file.NewStates(), []stateChange{{Op: opSet, State: states[1]}}))
+	logStore.file.Close()
+
+	// Switching back to the file storage applies and removes the log
+	fileStore := newFileStore(registry)
+	loaded, err = fileStore.Load()
+	require.NoError(t, err)
+	assertSameStates(t, states, loaded)
+
+	all := file.NewStates()
+	all.SetStates(loaded)
+	require.NoError(t, fileStore.Write(all, nil))
+
+	_, err = os.Stat(logFilePath(registry))
+	assert.True(t, os.IsNotExist(err))
+
+	checkpoint, err := readCheckpoint(registry)
+	require.NoError(t, err)
+	assertSameStates(t, states, checkpoint)
+}
+
+var errNoSpace = errors.New("no space left on device")
+
+// failingFile writes only half of the data when fail is set.
+type failingFile struct {
+	logFile
+	fail         bool
+	failTruncate bool
+}
+
+func (f *failingFile) Write(p []byte) (int, error) {
+	if !f.fail {
+		return f.logFile.Write(p)
+	}
+	n, _ := f.logFile.Write(p[:len(p)/2])
+	return n, errNoSpace
+}
+
+func (f *failingFile) Truncate(size int64) error {
+	if f.failTruncate {
+		return errors.New("truncate failed")
+	}
+	return f.logFile.Truncate(size)
+}
+
+func setupRegistryDir(t *testing.T) (string, string) {
+	dir, err := ioutil.TempDir("", "registrar")
+	require.NoError(t, err)
+	return dir, filepath.Join(dir, "registry")
+}
+
+// newTestStates creates n files in dir and returns a state for each of them
+func newTestStates(t *testing.T, dir string, n int) []file.State {
+	var states []file.State
+	for i := 0; i < n; i++ {
+		f, err := ioutil.TempFile(dir, "log")
+		require.NoError(t, err)
+		f.Close()
+
+		info, err := os.Stat(f.Name())
+		require.NoError(t, err)
+		states = append(states, file.NewState(info, f.Name(), "log"))
+	}
+	return states
+}
+
+func assertSameStates(t *testing.T, expected, actual []file.State) {
+	if !assert.Len(t, actual, len(expected)) {
+		return
+	}
+
+	offsets := map[string]int64{}
+	for _, state := range actual {
+		offsets[state.ID()] = state.Offset
+	}
+	for _, state := range expected {
+		offset, found := offsets[state.ID()]
+		if assert.True(t, found, "missing state for %s", state.Source) {
+			assert.Equal(t, state.Offset, offset)
+		}
+	}
+}
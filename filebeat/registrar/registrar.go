@@ -1,7 +1,6 @@
 package registrar
 
 import (
-	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -9,7 +8,6 @@ import (
 	"time"
 
 	"github.com/elastic/beats/filebeat/input/file"
-	helper "github.com/elastic/beats/libbeat/common/file"
 	"github.com/elastic/beats/libbeat/logp"
 	"github.com/elastic/beats/libbeat/monitoring"
 	"github.com/elastic/beats/libbeat/paths"
@@ -22,7 +20,11 @@ type Registrar struct {
 	registryFile string // Path to the Registry File
 	wg           sync.WaitGroup
 
-	states               *file.States // Map with all file paths inside and the corresponding state
+	storageConfig StorageConfig
+	store         store
+
+	states               *file.States           // Map with all file paths inside and the corresponding state
+	changes              map[string]stateChange // State changes not yet written to the store
 	flushTimeout         time.Duration
 	bufferedStateUpdates int
 }
@@ -36,17 +38,22 @@ var (
 	statesCleanup  = monitoring.NewInt(nil, "registrar.states.cleanup")
 	statesCurrent  = monitoring.NewInt(nil, "registrar.states.current")
 	registryWrites = monitoring.NewInt(nil, "registrar.writes")
+
+	registryLogWrites   = monitoring.NewInt(nil, "registrar.log.writes")
+	registryCheckpoints = monitoring.NewInt(nil, "registrar.log.checkpoints")
 )
 
-func New(registryFile string, flushTimeout time.Duration, out successLogger) (*Registrar, error) {
+func New(registryFile string, storageConfig StorageConfig, flushTimeout time.Duration, out successLogger) (*Registrar, error) {
 	r := &Registrar{
-		registryFile: registryFile,
-		done:         make(chan struct{}),
-		states:       file.NewStates(),
-		Channel:      make(chan []file.State, 1),
-		flushTimeout: flushTimeout,
-		out:          out,
-		wg:           sync.WaitGroup{},
+		registryFile:  registryFile,
+		storageConfig: storageConfig,
+		done:          make(chan struct{}),
+		states:        file.NewStates(),
+		changes:       map[string]stateChange{},
+		Channel:       make(chan []file.State, 1),
+		flushTimeout:  flushTimeout,
+		out:           out,
+		wg:            sync.WaitGroup{},
 	}
 	err := r.Init()
 
@@ -65,12 +72,24 @@ func (r *Registrar) Init() error {
 		return fmt.Errorf("Failed to created registry file dir %s: %v", registryPath, err)
 	}
 
+	r.store = newStore(r.registryFile, r.storageConfig)
+
 	// Check if files exists
 	fileInfo, err := os.Lstat(r.registryFile)
 	if os.IsNotExist(err) {
 		logp.Info("No registry file found under: %s. Creating a new registry file.", r.registryFile)
+
+		// A log file without registry file is left over from a registry which was
+		// removed to reset all states. It must not be applied to the new registry.
+		logFile := logFilePath(r.registryFile)
+		if err := os.Remove(logFile); err == nil {
+			logp.Info("Removed registry log file %s without registry file.", logFile)
+		} else if !os.IsNotExist(err) {
+			return err
+		}
+
 		// No registry exists yet, write empty state to check if registry can be written
-		return r.writeRegistry()
+		return writeCheckpoint(r.registryFile, r.states.GetStates())
 	}
 	if err != nil {
 		return err
@@ -98,22 +117,11 @@ func (r *Registrar) GetStates() []file.State {
 // loadStates fetches the previous reading state from the configure RegistryFile file
 // The default file is `registry` in the data path.
 func (r *Registrar) loadStates() error {
-	f, err := os.Open(r.registryFile)
+	states, err := r.store.Load()
 	if err != nil {
 		return err
 	}
 
-	defer f.Close()
-
-	logp.Info("Loading registrar data from %s", r.registryFile)
-
-	decoder := json.NewDecoder(f)
-	states := []file.State{}
-	err = decoder.Decode(&states)
-	if err != nil {
-		return fmt.Errorf("Error decoding states: %s", err)
-	}
-
 	states = resetStates(states)
 	r.states.SetStates(states)
 	logp.Info("States Loaded from registrar: %+v", len(states))
@@ -150,7 +158,9 @@ func (r *Registrar) Run() {
 	logp.Info("Starting Registrar")
 	// Writes registry on shutdown
 	defer func() {
-		r.writeRegistry()
+		if err := r.store.Close(r.states); err != nil {
+			logp.Err("Writing of registry returned error: %v", err)
+		}
 		r.wg.Done()
 	}()
 
@@ -185,7 +195,9 @@ func (r *Registrar) onEvents(states []file.State) {
 	r.processEventStates(states)
 
 	beforeCount := r.states.Count()
-	cleanedStates := r.states.Cleanup()
+	cleanedStates := r.states.CleanupWith(func(state file.State) {
+		r.changes[state.ID()] = stateChange{Op: opRemove, State: state}
+	})
 	statesCleanup.Add(int64(cleanedStates))
 
 	r.bufferedStateUpdates += len(states)
@@ -202,6 +214,10 @@ func (r *Registrar) processEventStates(states []file.State) {
 	for i := range states {
 		r.states.Update(states[i])
 		statesUpdate.Add(1)
+
+		state := states[i]
+		state.Timestamp = time.Now()
+		r.changes[state.ID()] = stateChange{Op: opSet, State: state}
 	}
 }
 
@@ -223,36 +239,17 @@ func (r *Registrar) flushRegistry() {
 	r.bufferedStateUpdates = 0
 }
 
-// writeRegistry writes the new json registry file to disk.
+// writeRegistry persists all pending state changes.
 func (r *Registrar) writeRegistry() error {
-	logp.Debug("registrar", "Write registry file: %s", r.registryFile)
-
-	tempfile := r.registryFile + ".new"
-	f, err := os.OpenFile(tempfile, os.O_RDWR|os.O_CREATE|os.O_TRUNC|os.O_SYNC, 0600)
-	if err != nil {
-		logp.Err("Failed to create tempfile (%s) for writing: %s", tempfile, err)
-		return err
+	changes := make([]stateChange, 0, len(r.changes))
+	for _, change := range r.changes {
+		changes = append(changes, change)
 	}
 
-	// First clean up states
-	states := r.states.GetStates()
-
-	encoder := json.NewEncoder(f)
-	err = encoder.Encode(states)
-	if err != nil {
-		f.Close()
-		logp.Err("Error when encoding the states: %s", err)
+	if err := r.store.Write(r.states, changes); err != nil {
 		return err
 	}
 
-	// Directly close file because of windows
-	f.Close()
-
-	err = helper.SafeFileRotate(r.registryFile, tempfile)
-
-	logp.Debug("registrar", "Registry file updated. %d states written.", len(states))
-	registryWrites.Add(1)
-	statesCurrent.Set(int64(len(states)))
-
-	return err
+	r.changes = map[string]stateChange{}
+	return nil
 }
@@ -0,0 +1,220 @@
+package registrar
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/elastic/beats/filebeat/input/file"
+	helper "github.com/elastic/beats/libbeat/common/file"
+	"github.com/elastic/beats/libbeat/logp"
+)
+
+// Operations recorded for a state change
+const (
+	opSet    = "set"
+	opRemove = "remove"
+)
+
+// stateChange is a single state update or removal. It is the entry format of the
+// registry log file.
+type stateChange struct {
+	Op    string     `json:"op"`
+	State file.State `json:"state"`
+}
+
+// store persists the registrar states
+type store interface {
+	// Load returns all states persisted by the store.
+	Load() ([]file.State, error)
+
+	// Write persists the changes since the last call to Write. The complete set of
+	// states is passed as well for stores that need to write a full snapshot.
+	Write(states *file.States, changes []stateChange) error
+
+	// Close writes the final states and releases all resources held by the store.
+	Close(states *file.States) error
+}
+
+func newStore(registryFile string, config StorageConfig) store {
+	if config.Type == LogStorage {
+		return newLogStore(registryFile, config)
+	}
+	return newFileStore(registryFile)
+}
+
+// logFilePath returns the path of the log file belonging to the registry file
+func logFilePath(registryFile string) string {
+	return registryFile + ".log"
+}
+
+// fileStore rewrites the complete registry file on every write.
+type fileStore struct {
+	registryFile string
+
+	// staleLog is set if a log file from the log storage was found on load. It is
+	// removed as soon as its states are contained in the registry file.
+	staleLog bool
+}
+
+func newFileStore(registryFile string) *fileStore {
+	return &fileStore{registryFile: registryFile}
+}
+
+func (s *fileStore) Load() ([]file.State, error) {
+	states, err := readCheckpoint(s.registryFile)
+	if err != nil {
+		return nil, err
+	}
+
+	// Apply updates left behind by the log storage, so switching back to the
+	// file storage does not loose any states.
+	f, err := os.Open(logFilePath(s.registryFile))
+	if os.IsNotExist(err) {
+		return states, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	logp.Info("Migrating registry log file %s", f.Name())
+	states, _, err = replayLog(f, states)
+	if err != nil {
+		return nil, err
+	}
+	s.staleLog = true
+
+	return states, nil
+}
+
+func (s *fileStore) Write(states *file.States, _ []stateChange) error {
+	if err := writeCheckpoint(s.registryFile, states.GetStates()); err != nil {
+		return err
+	}
+
+	if s.staleLog {
+		if err := os.Remove(logFilePath(s.registryFile)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		s.staleLog = false
+	}
+	return nil
+}
+
+func (s *fileStore) Close(states *file.States) error {
+	return s.Write(states, nil)
+}
+
+// readCheckpoint reads the json encoded states from the given registry file
+func readCheckpoint(path string) ([]file.State, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	defer f.Close()
+
+	logp.Info("Loading registrar data from %s", path)
+
+	decoder := json.NewDecoder(f)
+	states := []file.State{}
+	err = decoder.Decode(&states)
+	if err != nil {
+		return nil, fmt.Errorf("Error decoding states: %s", err)
+	}
+
+	return states, nil
+}
+
+// writeCheckpoint writes the given states as json array to the registry file.
+// The states are first written to a temporary file which then replaces the
+// existing registry file.
+func writeCheckpoint(path string, states []file.State) error {
+	logp.Debug("registrar", "Write registry file: %s", path)
+
+	tempfile := path + ".new"
+	f, err := os.OpenFile(tempfile, os.O_RDWR|os.O_CREATE|os.O_TRUNC|os.O_SYNC, 0600)
+	if err != nil {
+		logp.Err("Failed to create tempfile (%s) for writing: %s", tempfile, err)
+		return err
+	}
+
+	encoder := json.NewEncoder(f)
+	err = encoder.Encode(states)
+	if err != nil {
+		f.Close()
+		logp.Err("Error when encoding the states: %s", err)
+		return err
+	}
+
+	// Directly close file because of windows
+	f.Close()
+
+	err = helper.SafeFileRotate(path, tempfile)
+
+	logp.Debug("registrar", "Registry file updated. %d states written.", len(states))
+	registryWrites.Add(1)
+	statesCurrent.Set(int64(len(states)))
+
+	return err
+}
+
+// replayLog applies all changes read from the log on top of the given states.
+// Reading stops at the first entry which can not be decoded, as it is the result
+// of a torn write. The offset of the end of the last valid entry is returned.
+func replayLog(r io.Reader, states []file.State) ([]file.State, int64, error) {
+	index := make(map[string]int, len(states))
+	for i := range states {
+		index[states[i].ID()] = i
+	}
+
+	var (
+		offset  int64
+		applied int
+		reader  = bufio.NewReader(r)
+	)
+
+	for {
+		line, err := reader.ReadBytes('\n')
+		if err == io.EOF {
+			if len(bytes.TrimSpace(line)) > 0 {
+				logp.Warn("Ignoring incomplete entry at the end of the registry log")
+			}
+			break
+		}
+		if err != nil {
+			return nil, 0, err
+		}
+
+		var change stateChange
+		if err := json.Unmarshal(line, &change); err != nil || (change.Op != opSet && change.Op != opRemove) {
+			logp.Warn("Ignoring corrupted registry log after offset %d", offset)
+			break
+		}
+		offset += int64(len(line))
+		applied++
+
+		id := change.State.ID()
+		i, exists := index[id]
+		switch {
+		case change.Op == opSet && exists:
+			states[i] = change.State
+		case change.Op == opSet:
+			index[id] = len(states)
+			states = append(states, change.State)
+		case change.Op == opRemove && exists:
+			last := len(states) - 1
+			states[i] = states[last]
+			index[states[i].ID()] = i
+			states = states[:last]
+			delete(index, id)
+		}
+	}
+
+	logp.Info("Registry log entries applied: %d", applied)
+	return states, offset, nil
+}
@@ -0,0 +1,61 @@
+package kafka
+
+import (
+	"fmt"
+
+	"github.com/Shopify/sarama"
+)
+
+// Version is a kafka version
+type Version string
+
+// TODO: remove me.
+// Compat version overwrite for missing versions in sarama
+// Public API is compatible between these versions.
+var (
+	v0_10_2_1 = sarama.V0_10_2_0
+	v0_11_0_0 = sarama.V0_10_2_0
+)
+
+var kafkaVersions = map[string]sarama.KafkaVersion{
+	"": sarama.V0_8_2_0,
+
+	"0.8.2.0": sarama.V0_8_2_0,
+	"0.8.2.1": sarama.V0_8_2_1,
+	"0.8.2.2": sarama.V0_8_2_2,
+	"0.8.2":   sarama.V0_8_2_2,
+	"0.8":     sarama.V0_8_2_2,
+
+	"0.9.0.0": sarama.V0_9_0_0,
+	"0.9.0.1": sarama.V0_9_0_1,
+	"0.9.0":   sarama.V0_9_0_1,
+	"0.9":     sarama.V0_9_0_1,
+
+	"0.10.0.0": sarama.V0_10_0_0,
+	"0.10.0.1": sarama.V0_10_0_1,
+	"0.10.0":   sarama.V0_10_0_1,
+	"0.10.1.0": sarama.V0_10_1_0,
+	"0.10.1":   sarama.V0_10_1_0,
+	"0.10.2.0": sarama.V0_10_2_0,
+	"0.10.2.1": v0_10_2_1,
+	"0.10.2":   v0_10_2_1,
+	"0.10":     v0_10_2_1,
+
+	"0.11.0.0": v0_11_0_0,
+	"0.11.0":   v0_11_0_0,
+	"0.11":     v0_11_0_0,
+}
+
+// Validate checks that the version is known.
+func (v Version) Validate() error {
+	if _, ok := v.Get(); !ok {
+		return fmt.Errorf("unknown/unsupported kafka version '%v'", string(v))
+	}
+	return nil
+}
+
+// Get returns the sarama version.
+func (v Version) Get() (sarama.KafkaVersion, bool) {
+	version, ok := kafkaVersions[string(v)]
+	return version, ok
+}
@@ -12,6 +12,7 @@ import (
 
 	"github.com/elastic/beats/libbeat/beat"
 	"github.com/elastic/beats/libbeat/common"
+	kafkacommon "github.com/elastic/beats/libbeat/common/kafka"
 	"github.com/elastic/beats/libbeat/logp"
 	"github.com/elastic/beats/libbeat/monitoring"
 	"github.com/elastic/beats/libbeat/monitoring/adapter"
@@ -45,14 +46,6 @@ var (
 	errNoHosts    = errors.New("No hosts configured")
 )
 
-// TODO: remove me.
-// Compat version overwrite for missing versions in sarama
-// Public API is compatible between these versions.
-var (
-	v0_10_2_1 = sarama.V0_10_2_0
-	v0_11_0_0 = sarama.V0_10_2_0
-)
-
 var (
 	compressionModes = map[string]sarama.CompressionCodec{
 		"none":   sarama.CompressionNone,
@@ -62,35 +55,6 @@ var (
 		"lz4":    sarama.CompressionLZ4,
 		"snappy": sarama.CompressionSnappy,
 	}
-
-	kafkaVersions = map[string]sarama.KafkaVersion{
-		"": sarama.V0_8_2_0,
-
-		"0.8.2.0": sarama.V0_8_2_0,
-		"0.8.2.1": sarama.V0_8_2_1,
-		"0.8.2.2": sarama.V0_8_2_2,
-		"0.8.2":   sarama.V0_8_2_2,
-		"0.8":     sarama.V0_8_2_2,
-
-		"0.9.0.0": sarama.V0_9_0_0,
-		"0.9.0.1": sarama.V0_9_0_1,
-		"0.9.0":   sarama.V0_9_0_1,
-		"0.9":     sarama.V0_9_0_1,
-
-		"0.10.0.0": sarama.V0_10_0_0,
-		"0.10.0.1": sarama.V0_10_0_1,
-		"0.10.0":   sarama.V0_10_0_1,
-		"0.10.1.0": sarama.V0_10_1_0,
-		"0.10.1":   sarama.V0_10_1_0,
-		"0.10.2.0": sarama.V0_10_2_0,
-		"0.10.2.1": v0_10_2_1,
-		"0.10.2":   v0_10_2_1,
-		"0.10":     v0_10_2_1,
-
-		"0.11.0.0": v0_11_0_0,
-		"0.11.0":   v0_11_0_0,
-		"0.11":     v0_11_0_0,
-	}
 )
 
 func init() {
@@ -231,7 +195,7 @@ func newKafkaConfig(config *kafkaConfig) (*sarama.Config, error) {
 		return nil, err
 	}
 
-	version, ok := kafkaVersions[config.Version]
+	version, ok := kafkacommon.Version(config.Version).Get()
 	if !ok {
 		return nil, fmt.Errorf("Unknown/unsupported kafka version: %v", config.Version)
 	}
@@ -107,6 +107,10 @@ func (h *HTTP) SetURI(uri string) {
 	h.uri = uri
 }
 
+func (h *HTTP) GetURI() string {
+	return h.uri
+}
+
 func (h *HTTP) SetBody(body []byte) {
 	h.body = body
 }
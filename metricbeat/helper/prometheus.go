@@ -14,9 +14,13 @@ type Prometheus struct {
 	HTTP
 }
 
-// NewPrometheusClient creates new prometheus helper
+// NewPrometheusClient creates new prometheus helper, it returns nil if the
+// HTTP helper cannot be created
 func NewPrometheusClient(base mb.BaseMetricSet) *Prometheus {
 	http := NewHTTP(base)
+	if http == nil {
+		return nil
+	}
 	return &Prometheus{*http}
 }
 
@@ -34,7 +34,12 @@ func (h *HttpEvent) GetMeta() server.Meta {
 }
 
 func NewHttpServer(mb mb.BaseMetricSet) (server.Server, error) {
-	config := defaultHttpConfig()
+	return NewHttpServerWithConfig(mb, defaultHttpConfig())
+}
+
+// NewHttpServerWithConfig creates a new HTTP server using the given config as
+// defaults for the settings of the module.
+func NewHttpServerWithConfig(mb mb.BaseMetricSet, config HttpConfig) (server.Server, error) {
 	err := mb.Module().UnpackConfig(&config)
 	if err != nil {
 		return nil, err
@@ -34,7 +34,12 @@ func (u *UdpEvent) GetMeta() server.Meta {
 }
 
 func NewUdpServer(base mb.BaseMetricSet) (server.Server, error) {
-	config := defaultUdpConfig()
+	return NewUdpServerWithConfig(base, defaultUdpConfig())
+}
+
+// NewUdpServerWithConfig creates a new UDP server using the given config as
+// defaults for the settings of the module.
+func NewUdpServerWithConfig(base mb.BaseMetricSet, config UdpConfig) (server.Server, error) {
 	err := base.Module().UnpackConfig(&config)
 	if err != nil {
 		return nil, err
@@ -82,9 +87,13 @@ func (g *UdpServer) watchMetrics() {
 			continue
 		}
 
+		// The buffer is reused by the next read.
+		data := make([]byte, length)
+		copy(data, buffer[:length])
+
 		g.eventQueue <- &UdpEvent{
 			event: common.MapStr{
-				server.EventDataKey: buffer[:length],
+				server.EventDataKey: data,
 			},
 			meta: server.Meta{
 				"client_ip": addr.IP.String(),
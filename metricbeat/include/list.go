@@ -26,6 +26,7 @@ import (
 	_ "github.com/elastic/beats/metricbeat/module/docker/container"
 	_ "github.com/elastic/beats/metricbeat/module/docker/cpu"
 	_ "github.com/elastic/beats/metricbeat/module/docker/diskio"
+	_ "github.com/elastic/beats/metricbeat/module/docker/event"
 	_ "github.com/elastic/beats/metricbeat/module/docker/healthcheck"
 	_ "github.com/elastic/beats/metricbeat/module/docker/image"
 	_ "github.com/elastic/beats/metricbeat/module/docker/info"
@@ -34,8 +35,16 @@ import (
 	_ "github.com/elastic/beats/metricbeat/module/dropwizard"
 	_ "github.com/elastic/beats/metricbeat/module/dropwizard/collector"
 	_ "github.com/elastic/beats/metricbeat/module/elasticsearch"
+	_ "github.com/elastic/beats/metricbeat/module/elasticsearch/cluster_stats"
+	_ "github.com/elastic/beats/metricbeat/module/elasticsearch/index"
 	_ "github.com/elastic/beats/metricbeat/module/elasticsearch/node"
 	_ "github.com/elastic/beats/metricbeat/module/elasticsearch/node_stats"
+	_ "github.com/elastic/beats/metricbeat/module/elasticsearch/shard"
+	_ "github.com/elastic/beats/metricbeat/module/etcd"
+	_ "github.com/elastic/beats/metricbeat/module/etcd/leader"
+	_ "github.com/elastic/beats/metricbeat/module/etcd/metrics"
+	_ "github.com/elastic/beats/metricbeat/module/etcd/self"
+	_ "github.com/elastic/beats/metricbeat/module/etcd/store"
 	_ "github.com/elastic/beats/metricbeat/module/golang"
 	_ "github.com/elastic/beats/metricbeat/module/golang/expvar"
 	_ "github.com/elastic/beats/metricbeat/module/golang/heap"
@@ -60,17 +69,23 @@ import (
 	_ "github.com/elastic/beats/metricbeat/module/kubernetes/node"
 	_ "github.com/elastic/beats/metricbeat/module/kubernetes/pod"
 	_ "github.com/elastic/beats/metricbeat/module/kubernetes/state_container"
+	_ "github.com/elastic/beats/metricbeat/module/kubernetes/state_cronjob"
+	_ "github.com/elastic/beats/metricbeat/module/kubernetes/state_daemonset"
 	_ "github.com/elastic/beats/metricbeat/module/kubernetes/state_deployment"
+	_ "github.com/elastic/beats/metricbeat/module/kubernetes/state_job"
 	_ "github.com/elastic/beats/metricbeat/module/kubernetes/state_node"
 	_ "github.com/elastic/beats/metricbeat/module/kubernetes/state_pod"
 	_ "github.com/elastic/beats/metricbeat/module/kubernetes/state_replicaset"
+	_ "github.com/elastic/beats/metricbeat/module/kubernetes/state_statefulset"
 	_ "github.com/elastic/beats/metricbeat/module/kubernetes/system"
 	_ "github.com/elastic/beats/metricbeat/module/kubernetes/util"
 	_ "github.com/elastic/beats/metricbeat/module/kubernetes/volume"
 	_ "github.com/elastic/beats/metricbeat/module/memcached"
 	_ "github.com/elastic/beats/metricbeat/module/memcached/stats"
 	_ "github.com/elastic/beats/metricbeat/module/mongodb"
+	_ "github.com/elastic/beats/metricbeat/module/mongodb/collstats"
 	_ "github.com/elastic/beats/metricbeat/module/mongodb/dbstats"
+	_ "github.com/elastic/beats/metricbeat/module/mongodb/replstatus"
 	_ "github.com/elastic/beats/metricbeat/module/mongodb/status"
 	_ "github.com/elastic/beats/metricbeat/module/mysql"
 	_ "github.com/elastic/beats/metricbeat/module/mysql/status"
@@ -82,16 +97,27 @@ import (
 	_ "github.com/elastic/beats/metricbeat/module/postgresql/activity"
 	_ "github.com/elastic/beats/metricbeat/module/postgresql/bgwriter"
 	_ "github.com/elastic/beats/metricbeat/module/postgresql/database"
+	_ "github.com/elastic/beats/metricbeat/module/postgresql/statement"
 	_ "github.com/elastic/beats/metricbeat/module/prometheus"
 	_ "github.com/elastic/beats/metricbeat/module/prometheus/collector"
+	_ "github.com/elastic/beats/metricbeat/module/prometheus/remote_write"
 	_ "github.com/elastic/beats/metricbeat/module/prometheus/stats"
 	_ "github.com/elastic/beats/metricbeat/module/rabbitmq"
+	_ "github.com/elastic/beats/metricbeat/module/rabbitmq/connection"
+	_ "github.com/elastic/beats/metricbeat/module/rabbitmq/exchange"
 	_ "github.com/elastic/beats/metricbeat/module/rabbitmq/node"
 	_ "github.com/elastic/beats/metricbeat/module/rabbitmq/queue"
 	_ "github.com/elastic/beats/metricbeat/module/redis"
 	_ "github.com/elastic/beats/metricbeat/module/redis/info"
+	_ "github.com/elastic/beats/metricbeat/module/redis/key"
 	_ "github.com/elastic/beats/metricbeat/module/redis/keyspace"
+	_ "github.com/elastic/beats/metricbeat/module/redis/slowlog"
+	_ "github.com/elastic/beats/metricbeat/module/sql"
+	_ "github.com/elastic/beats/metricbeat/module/sql/query"
+	_ "github.com/elastic/beats/metricbeat/module/statsd"
+	_ "github.com/elastic/beats/metricbeat/module/statsd/server"
 	_ "github.com/elastic/beats/metricbeat/module/system"
+	_ "github.com/elastic/beats/metricbeat/module/system/conntrack"
 	_ "github.com/elastic/beats/metricbeat/module/system/core"
 	_ "github.com/elastic/beats/metricbeat/module/system/cpu"
 	_ "github.com/elastic/beats/metricbeat/module/system/diskio"
@@ -103,6 +129,7 @@ import (
 	_ "github.com/elastic/beats/metricbeat/module/system/process"
 	_ "github.com/elastic/beats/metricbeat/module/system/process_summary"
 	_ "github.com/elastic/beats/metricbeat/module/system/socket"
+	_ "github.com/elastic/beats/metricbeat/module/system/socket_summary"
 	_ "github.com/elastic/beats/metricbeat/module/system/uptime"
 	_ "github.com/elastic/beats/metricbeat/module/vsphere"
 	_ "github.com/elastic/beats/metricbeat/module/vsphere/datastore"
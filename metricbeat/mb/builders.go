@@ -200,6 +200,10 @@ func mustImplementFetcher(ms MetricSet) error {
 		ifcs = append(ifcs, "ReportingMetricSet")
 	}
 
+	if _, ok := ms.(ReportingMetricSetWithContext); ok {
+		ifcs = append(ifcs, "ReportingMetricSetWithContext")
+	}
+
 	if _, ok := ms.(PushMetricSet); ok {
 		ifcs = append(ifcs, "PushMetricSet")
 	}
@@ -208,7 +212,7 @@ func mustImplementFetcher(ms MetricSet) error {
 	case 0:
 		return fmt.Errorf("MetricSet '%s/%s' does not implement an event "+
 			"producing interface (EventFetcher, EventsFetcher, "+
-			"ReportingMetricSet, or PushMetricSet)",
+			"ReportingMetricSet, ReportingMetricSetWithContext, or PushMetricSet)",
 			ms.Module().Name(), ms.Name())
 	case 1:
 		return nil
@@ -5,6 +5,7 @@ to implement Modules and their associated MetricSets.
 package mb
 
 import (
+	"context"
 	"fmt"
 	"time"
 
@@ -112,6 +113,15 @@ type ReportingMetricSet interface {
 	Fetch(r Reporter)
 }
 
+// ReportingMetricSetWithContext is a MetricSet that reports events or errors
+// through the Reporter interface. Fetch is called periodically to collect
+// events. The context is cancelled once the fetch exceeds the configured
+// timeout or the MetricSet is stopped, so blocking calls should use it.
+type ReportingMetricSetWithContext interface {
+	MetricSet
+	Fetch(ctx context.Context, r Reporter)
+}
+
 // PushReporter is used by a MetricSet to report events, errors, or errors with
 // metadata. It provides a done channel used to signal that reporter should
 // stop.
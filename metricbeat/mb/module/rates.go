@@ -0,0 +1,248 @@
+package module
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/elastic/beats/libbeat/beat"
+	"github.com/elastic/beats/libbeat/common"
+)
+
+// ratesConfig is the configuration of the optional rates computed by the
+// module wrapper from monotonic counters.
+type ratesConfig struct {
+	// Fields are the full names of the counters, e.g. system.network.in.bytes.
+	Fields []string `config:"fields" validate:"required"`
+	// KeyFields identify the samples of multi event metricsets, e.g.
+	// system.network.name. All the string values of the module are used when
+	// not set.
+	KeyFields []string `config:"key_fields"`
+	// Suffix is appended to the field name of a counter to get the name of
+	// its rate.
+	Suffix string `config:"suffix" validate:"required"`
+	// TTL is the maximum age of a previous sample. Older samples are
+	// discarded and no rate is computed from them. Defaults to three times
+	// the period.
+	TTL time.Duration `config:"ttl"`
+	// MaxEntries is the maximum number of samples kept per metricset and
+	// host.
+	MaxEntries int `config:"max_entries" validate:"min=1"`
+}
+
+func defaultRatesConfig() ratesConfig {
+	return ratesConfig{
+		Suffix:     "_rate",
+		MaxEntries: 10000,
+	}
+}
+
+// Counters decreasing from the upper quarter of these ranges to the lower
+// quarter are considered to have wrapped around, any other decrease is
+// considered a reset.
+var counterLimits = []float64{math.Pow(2, 32), math.Pow(2, 64)}
+
+// sample is the previous value of a counter.
+type sample struct {
+	value     float64
+	timestamp time.Time
+}
+
+// Rates computes the per second rates of monotonic counters from their
+// previous values. It is used by the module wrapper for the configured rates,
+// and can be used by metricsets to report the rates of their own counters.
+type Rates struct {
+	ttl        time.Duration
+	maxEntries int
+
+	mutex   sync.Mutex
+	samples map[string]sample
+}
+
+// NewRates returns a Rates for counters fetched every period. Samples older
+// than three periods are discarded, and at most 10000 counters are tracked.
+func NewRates(period time.Duration) *Rates {
+	return newRates(3*period, defaultRatesConfig().MaxEntries)
+}
+
+func newRates(ttl time.Duration, maxEntries int) *Rates {
+	return &Rates{
+		ttl:        ttl,
+		maxEntries: maxEntries,
+		samples:    map[string]sample{},
+	}
+}
+
+// Rate stores the value of the counter identified by key and returns its per
+// second rate since the previous value. It returns false on the first sample
+// of the counter, after a reset, when the previous sample is expired, or when
+// the maximum number of counters is already tracked.
+func (r *Rates) Rate(key string, value float64, now time.Time) (float64, bool) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	prev, found := r.samples[key]
+	if !found && !r.reserve(now) {
+		debugf("Not computing rate of %s, already tracking %d samples", key, len(r.samples))
+		return 0, false
+	}
+	r.samples[key] = sample{value: value, timestamp: now}
+
+	if !found || now.Sub(prev.timestamp) > r.ttl {
+		return 0, false
+	}
+	return computeRate(prev, value, now)
+}
+
+// reserve checks there is room for a new sample, discarding the expired ones
+// if the limit is reached.
+func (r *Rates) reserve(now time.Time) bool {
+	if len(r.samples) < r.maxEntries {
+		return true
+	}
+
+	for k, s := range r.samples {
+		if now.Sub(s.timestamp) > r.ttl {
+			delete(r.samples, k)
+		}
+	}
+	return len(r.samples) < r.maxEntries
+}
+
+// rateTracker adds the per second rates of the configured counters of a
+// metricset and host to its events.
+type rateTracker struct {
+	config ratesConfig
+	*Rates
+}
+
+func newRateTracker(config ratesConfig, period time.Duration) *rateTracker {
+	if config.TTL <= 0 {
+		config.TTL = 3 * period
+	}
+	return &rateTracker{
+		config: config,
+		Rates:  newRates(config.TTL, config.MaxEntries),
+	}
+}
+
+// apply adds the rates of the configured counters to the event. No rate is
+// added for a counter on its first sample, after a reset or when its previous
+// sample is missing or expired.
+func (r *rateTracker) apply(event *beat.Event, module string) {
+	key := r.eventKey(event.Fields, module)
+
+	for _, field := range r.config.Fields {
+		v, err := event.Fields.GetValue(field)
+		if err != nil {
+			continue
+		}
+		value, ok := ToFloat(v)
+		if !ok {
+			continue
+		}
+
+		if rate, ok := r.Rate(field+"|"+key, value, event.Timestamp); ok {
+			event.Fields.Put(field+r.config.Suffix, rate)
+		}
+	}
+}
+
+// eventKey returns the values identifying the event among the ones of the
+// same metricset and host.
+func (r *rateTracker) eventKey(fields common.MapStr, module string) string {
+	if len(r.config.KeyFields) > 0 {
+		values := make([]string, len(r.config.KeyFields))
+		for i, field := range r.config.KeyFields {
+			if v, err := fields.GetValue(field); err == nil {
+				values[i] = fmt.Sprint(v)
+			}
+		}
+		return strings.Join(values, "|")
+	}
+
+	moduleFields, ok := fields[module].(common.MapStr)
+	if !ok {
+		return ""
+	}
+	var values []string
+	collectStrings(moduleFields, "", &values)
+	sort.Strings(values)
+	return strings.Join(values, "|")
+}
+
+// collectStrings appends all the string values of the map with their path.
+func collectStrings(m common.MapStr, prefix string, values *[]string) {
+	for k, v := range m {
+		switch value := v.(type) {
+		case string:
+			*values = append(*values, prefix+k+"="+value)
+		case common.MapStr:
+			collectStrings(value, prefix+k+".", values)
+		case map[string]interface{}:
+			collectStrings(common.MapStr(value), prefix+k+".", values)
+		}
+	}
+}
+
+// computeRate returns the per second rate between the previous sample and the
+// current value. It returns false if the counter was reset.
+func computeRate(prev sample, value float64, now time.Time) (float64, bool) {
+	elapsed := now.Sub(prev.timestamp).Seconds()
+	if elapsed <= 0 {
+		return 0, false
+	}
+
+	delta := value - prev.value
+	if delta < 0 {
+		delta = wrappedDelta(prev.value, value)
+		if delta < 0 {
+			return 0, false
+		}
+	}
+	return delta / elapsed, true
+}
+
+// wrappedDelta returns the increase of a counter that wrapped around, or -1 if
+// the decrease looks like a reset.
+func wrappedDelta(prev, value float64) float64 {
+	for _, limit := range counterLimits {
+		if prev >= limit {
+			continue
+		}
+		if prev >= limit*3/4 && value < limit/4 {
+			return limit - prev + value
+		}
+		return -1
+	}
+	return -1
+}
+
+// ToFloat converts the numeric value of a counter to a float64. It returns
+// false if the value is not a number.
+func ToFloat(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case int:
+		return float64(n), true
+	case int32:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case uint:
+		return float64(n), true
+	case uint32:
+		return float64(n), true
+	case uint64:
+		return float64(n), true
+	case float32:
+		return float64(n), true
+	case float64:
+		return n, true
+	case common.Float:
+		return float64(n), true
+	}
+	return 0, false
+}
@@ -0,0 +1,179 @@
+// +build !integration
+
+package module
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/elastic/beats/libbeat/beat"
+	"github.com/elastic/beats/libbeat/common"
+)
+
+func newNetworkEvent(ts time.Time, name string, in uint64) *beat.Event {
+	return &beat.Event{
+		Timestamp: ts,
+		Fields: common.MapStr{
+			"system": common.MapStr{
+				"network": common.MapStr{
+					"name": name,
+					"in":   common.MapStr{"bytes": in},
+				},
+			},
+			"metricset": common.MapStr{"name": "network"},
+		},
+	}
+}
+
+func newTestRateTracker(config ratesConfig) *rateTracker {
+	c := defaultRatesConfig()
+	c.Fields = []string{"system.network.in.bytes"}
+	c.KeyFields = config.KeyFields
+	if config.MaxEntries > 0 {
+		c.MaxEntries = config.MaxEntries
+	}
+	return newRateTracker(c, 10*time.Second)
+}
+
+func getRate(event *beat.Event) interface{} {
+	rate, err := event.Fields.GetValue("system.network.in.bytes_rate")
+	if err != nil {
+		return nil
+	}
+	return rate
+}
+
+func TestRates(t *testing.T) {
+	r := newTestRateTracker(ratesConfig{})
+	now := time.Now()
+
+	// First sample, no rate yet.
+	event := newNetworkEvent(now, "eth0", 1000)
+	r.apply(event, "system")
+	assert.Nil(t, getRate(event))
+
+	// Samples are tracked per interface.
+	event = newNetworkEvent(now.Add(time.Second), "eth1", 5000)
+	r.apply(event, "system")
+	assert.Nil(t, getRate(event))
+
+	event = newNetworkEvent(now.Add(10*time.Second), "eth0", 3000)
+	r.apply(event, "system")
+	assert.Equal(t, 200.0, getRate(event))
+
+	event = newNetworkEvent(now.Add(11*time.Second), "eth1", 5100)
+	r.apply(event, "system")
+	assert.Equal(t, 10.0, getRate(event))
+}
+
+func TestRatesKeyFields(t *testing.T) {
+	r := newTestRateTracker(ratesConfig{KeyFields: []string{"system.network.name"}})
+	now := time.Now()
+
+	r.apply(newNetworkEvent(now, "eth0", 1000), "system")
+
+	// Other string fields don't change the key.
+	event := newNetworkEvent(now.Add(10*time.Second), "eth0", 2000)
+	event.Fields.Put("system.network.state", "up")
+	r.apply(event, "system")
+	assert.Equal(t, 100.0, getRate(event))
+}
+
+func TestRatesReset(t *testing.T) {
+	r := newTestRateTracker(ratesConfig{})
+	now := time.Now()
+
+	r.apply(newNetworkEvent(now, "eth0", 1000000), "system")
+
+	// Counter reset, e.g. after a restart.
+	event := newNetworkEvent(now.Add(10*time.Second), "eth0", 100)
+	r.apply(event, "system")
+	assert.Nil(t, getRate(event))
+
+	// The new value is used as baseline.
+	event = newNetworkEvent(now.Add(20*time.Second), "eth0", 200)
+	r.apply(event, "system")
+	assert.Equal(t, 10.0, getRate(event))
+}
+
+func TestRatesWraparound(t *testing.T) {
+	r := newTestRateTracker(ratesConfig{})
+	now := time.Now()
+
+	r.apply(newNetworkEvent(now, "eth0", 1<<32-1000), "system")
+
+	event := newNetworkEvent(now.Add(10*time.Second), "eth0", 1000)
+	r.apply(event, "system")
+	assert.Equal(t, 200.0, getRate(event))
+
+	r.apply(newNetworkEvent(now.Add(20*time.Second), "eth0", 1<<64-1<<20), "system")
+
+	event = newNetworkEvent(now.Add(30*time.Second), "eth0", 1<<20)
+	r.apply(event, "system")
+	assert.Equal(t, float64(1<<21)/10, getRate(event))
+}
+
+func TestRatesMissingSamples(t *testing.T) {
+	r := newTestRateTracker(ratesConfig{})
+	now := time.Now()
+
+	r.apply(newNetworkEvent(now, "eth0", 1000), "system")
+
+	// One sample missing, the rate is averaged over both periods.
+	event := newNetworkEvent(now.Add(20*time.Second), "eth0", 3000)
+	r.apply(event, "system")
+	assert.Equal(t, 100.0, getRate(event))
+
+	// Previous sample older than the TTL.
+	event = newNetworkEvent(now.Add(60*time.Second), "eth0", 4000)
+	r.apply(event, "system")
+	assert.Nil(t, getRate(event))
+
+	// Counters missing from the event are ignored.
+	event = newNetworkEvent(now.Add(70*time.Second), "eth0", 5000)
+	event.Fields.Delete("system.network.in")
+	r.apply(event, "system")
+	assert.Nil(t, getRate(event))
+}
+
+func TestRatesMaxEntries(t *testing.T) {
+	r := newTestRateTracker(ratesConfig{MaxEntries: 1})
+	now := time.Now()
+
+	r.apply(newNetworkEvent(now, "eth0", 1000), "system")
+	r.apply(newNetworkEvent(now, "eth1", 1000), "system")
+	assert.Len(t, r.samples, 1)
+
+	event := newNetworkEvent(now.Add(10*time.Second), "eth1", 2000)
+	r.apply(event, "system")
+	assert.Nil(t, getRate(event))
+
+	// Expired samples are discarded to make room for new ones.
+	r.apply(newNetworkEvent(now.Add(time.Minute), "eth1", 3000), "system")
+	event = newNetworkEvent(now.Add(time.Minute+10*time.Second), "eth1", 4000)
+	r.apply(event, "system")
+	assert.Equal(t, 100.0, getRate(event))
+	assert.Len(t, r.samples, 1)
+}
+
+func TestRatesRate(t *testing.T) {
+	r := NewRates(10 * time.Second)
+	now := time.Now()
+
+	_, ok := r.Rate("requests", 100, now)
+	assert.False(t, ok)
+
+	rate, ok := r.Rate("requests", 150, now.Add(10*time.Second))
+	assert.True(t, ok)
+	assert.Equal(t, 5.0, rate)
+
+	// Counter reset.
+	_, ok = r.Rate("requests", 10, now.Add(20*time.Second))
+	assert.False(t, ok)
+
+	// Previous sample older than three periods.
+	_, ok = r.Rate("requests", 20, now.Add(time.Minute))
+	assert.False(t, ok)
+}
@@ -1,6 +1,7 @@
 package module
 
 import (
+	"context"
 	"fmt"
 	"math/rand"
 	"sync"
@@ -8,6 +9,7 @@ import (
 
 	"github.com/elastic/beats/libbeat/beat"
 	"github.com/elastic/beats/libbeat/common"
+	"github.com/elastic/beats/libbeat/common/atomic"
 	"github.com/elastic/beats/libbeat/logp"
 	"github.com/elastic/beats/libbeat/monitoring"
 	"github.com/elastic/beats/libbeat/testing"
@@ -19,6 +21,8 @@ const (
 	successesKey = "success"
 	failuresKey  = "failures"
 	eventsKey    = "events"
+	timeoutsKey  = "timeouts"
+	skippedKey   = "skipped"
 )
 
 var (
@@ -42,8 +46,11 @@ type Wrapper struct {
 // running the MetricSet. It contains a pointer to the parent Module.
 type metricSetWrapper struct {
 	mb.MetricSet
-	module *Wrapper // Parent Module.
-	stats  *stats   // stats for this MetricSet.
+	module *Wrapper     // Parent Module.
+	stats  *stats       // stats for this MetricSet.
+	rates  *rateTracker // Rates of the counters of this MetricSet, nil if disabled.
+
+	fetching atomic.Bool // Set while a fetch is in progress.
 }
 
 // stats bundles common metricset stats.
@@ -53,6 +60,8 @@ type stats struct {
 	success  *monitoring.Int // Total success events.
 	failures *monitoring.Int // Total error events.
 	events   *monitoring.Int // Total events published.
+	timeouts *monitoring.Int // Total fetches that exceeded the timeout.
+	skipped  *monitoring.Int // Total fetches skipped because the previous one was still running.
 }
 
 // NewWrapper create a new Module and its associated MetricSets based
@@ -63,6 +72,11 @@ func NewWrapper(maxStartDelay time.Duration, config *common.Config, r *mb.Regist
 		return nil, err
 	}
 
+	rates, err := getRatesConfig(config)
+	if err != nil {
+		return nil, err
+	}
+
 	wrapper := &Wrapper{
 		Module:        module,
 		maxStartDelay: maxStartDelay,
@@ -70,11 +84,15 @@ func NewWrapper(maxStartDelay time.Duration, config *common.Config, r *mb.Regist
 	}
 
 	for i, ms := range metricsets {
-		wrapper.metricSets[i] = &metricSetWrapper{
+		msw := &metricSetWrapper{
 			MetricSet: ms,
 			module:    wrapper,
 			stats:     getMetricSetStats(wrapper.Name(), ms.Name()),
 		}
+		if rates != nil {
+			msw.rates = newRateTracker(*rates, module.Config().Period)
+		}
+		wrapper.metricSets[i] = msw
 	}
 
 	return wrapper, nil
@@ -159,7 +177,7 @@ func (msw *metricSetWrapper) run(done <-chan struct{}, out chan<- beat.Event) {
 	switch ms := msw.MetricSet.(type) {
 	case mb.PushMetricSet:
 		ms.Run(reporter)
-	case mb.EventFetcher, mb.EventsFetcher, mb.ReportingMetricSet:
+	case mb.EventFetcher, mb.EventsFetcher, mb.ReportingMetricSet, mb.ReportingMetricSetWithContext:
 		msw.startPeriodicFetching(reporter)
 	default:
 		// Earlier startup stages prevent this from happening.
@@ -171,9 +189,9 @@ func (msw *metricSetWrapper) run(done <-chan struct{}, out chan<- beat.Event) {
 // startPeriodicFetching performs an immediate fetch for the MetricSet then it
 // begins a continuous timer scheduled loop to fetch data. To stop the loop the
 // done channel should be closed.
-func (msw *metricSetWrapper) startPeriodicFetching(reporter reporter) {
+func (msw *metricSetWrapper) startPeriodicFetching(reporter *eventReporter) {
 	// Fetch immediately.
-	msw.fetch(reporter)
+	msw.fetchWithTimeout(reporter)
 
 	// Start timer for future fetches.
 	t := time.NewTicker(msw.Module().Config().Period)
@@ -183,15 +201,58 @@ func (msw *metricSetWrapper) startPeriodicFetching(reporter reporter) {
 		case <-reporter.Done():
 			return
 		case <-t.C:
-			msw.fetch(reporter)
+			msw.fetchWithTimeout(reporter)
+		}
+	}
+}
+
+// fetchWithTimeout runs a single fetch in the background and waits for it to
+// complete, for the configured timeout to expire or for the done channel to be
+// closed, whatever happens first. A timed out fetch is reported as an error
+// event and anything it reports afterwards is dropped. If the previous fetch
+// is still running, no new fetch is started and the skipped counter is
+// increased instead.
+func (msw *metricSetWrapper) fetchWithTimeout(reporter *eventReporter) {
+	if !msw.fetching.CAS(false, true) {
+		msw.stats.skipped.Add(1)
+		debugf("Skipping fetch of %s, the previous fetch is still running", msw)
+		return
+	}
+
+	timeout := msw.Module().Config().Timeout
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	fetchReporter := reporter.forFetch()
+	defer fetchReporter.expire()
+
+	fetched := make(chan struct{})
+	go func() {
+		defer msw.fetching.Store(false)
+		defer close(fetched)
+		defer logp.Recover(fmt.Sprintf("recovered from panic while fetching "+
+			"'%s/%s' for host '%s'", msw.module.Name(), msw.Name(), msw.Host()))
+
+		msw.fetch(ctx, fetchReporter)
+	}()
+
+	select {
+	case <-fetched:
+	case <-reporter.Done():
+	case <-ctx.Done():
+		if !fetchReporter.expire() {
+			return
 		}
+		msw.stats.timeouts.Add(1)
+		debugf("Fetch of %s timed out after %v", msw, timeout)
+		fetchReporter.report(fmt.Errorf("timeout after %v while fetching metrics", timeout), nil)
 	}
 }
 
 // fetch invokes the appropriate Fetch method for the MetricSet and publishes
-// the result using the publisher client. This method will recover from panics
-// and log a stack track if one occurs.
-func (msw *metricSetWrapper) fetch(reporter reporter) {
+// the result using the publisher client. The context is only passed to
+// MetricSets implementing mb.ReportingMetricSetWithContext.
+func (msw *metricSetWrapper) fetch(ctx context.Context, reporter reporter) {
 	switch fetcher := msw.MetricSet.(type) {
 	case mb.EventFetcher:
 		msw.singleEventFetch(fetcher, reporter)
@@ -199,6 +260,8 @@ func (msw *metricSetWrapper) fetch(reporter reporter) {
 		msw.multiEventFetch(fetcher, reporter)
 	case mb.ReportingMetricSet:
 		msw.reportingFetch(fetcher, reporter)
+	case mb.ReportingMetricSetWithContext:
+		msw.reportingFetchWithContext(ctx, fetcher, reporter)
 	default:
 		panic(fmt.Sprintf("unexpected fetcher type for %v", msw))
 	}
@@ -227,6 +290,11 @@ func (msw *metricSetWrapper) reportingFetch(fetcher mb.ReportingMetricSet, repor
 	fetcher.Fetch(reporter)
 }
 
+func (msw *metricSetWrapper) reportingFetchWithContext(ctx context.Context, fetcher mb.ReportingMetricSetWithContext, reporter reporter) {
+	reporter.StartFetchTimer()
+	fetcher.Fetch(ctx, reporter)
+}
+
 // close closes the underlying MetricSet if it implements the mb.Closer
 // interface.
 func (msw *metricSetWrapper) close() error {
@@ -255,7 +323,10 @@ func (msw *metricSetWrapper) Test(d testing.Driver) {
 			driver: d,
 			done:   done,
 		}
-		msw.fetch(reporter)
+
+		ctx, cancel := context.WithTimeout(context.Background(), msw.Module().Config().Timeout)
+		defer cancel()
+		msw.fetch(ctx, reporter)
 	})
 }
 
@@ -274,11 +345,41 @@ type eventReporter struct {
 	done  <-chan struct{}
 	out   chan<- beat.Event
 	start time.Time // Start time of the current fetch (or zero for push sources).
+
+	mutex   sync.Mutex
+	expired bool // Set once the fetch is over, further events are dropped.
+}
+
+// forFetch returns a new reporter for a single fetch. It must be expired once
+// the fetch is over.
+func (r *eventReporter) forFetch() *eventReporter {
+	return &eventReporter{
+		msw:   r.msw,
+		done:  r.done,
+		out:   r.out,
+		start: time.Now(),
+	}
+}
+
+// expire marks the fetch as over, so anything reported afterwards is dropped.
+// It waits for events being reported concurrently and returns false if the
+// reporter was already expired.
+func (r *eventReporter) expire() bool {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.expired {
+		return false
+	}
+	r.expired = true
+	return true
 }
 
 // startFetchTimer demarcates the start of a new fetch. The elapsed time of a
 // fetch is computed based on the time of this call.
 func (r *eventReporter) StartFetchTimer() {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
 	r.start = time.Now()
 }
 
@@ -299,6 +400,19 @@ func (r *eventReporter) ErrorWith(err error, meta common.MapStr) bool {
 	if err == nil && meta == nil {
 		return true
 	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.expired {
+		return false
+	}
+	return r.report(err, meta)
+}
+
+// report publishes the event or error, the caller is responsible for checking
+// the reporter is not expired.
+func (r *eventReporter) report(err error, meta common.MapStr) bool {
 	timestamp := r.start
 	elapsed := time.Duration(0)
 
@@ -320,6 +434,10 @@ func (r *eventReporter) ErrorWith(err error, meta common.MapStr) bool {
 		return false
 	}
 
+	if r.msw.rates != nil {
+		r.msw.rates.apply(&event, r.msw.module.Name())
+	}
+
 	if !writeEvent(r.done, r.out, event) {
 		return false
 	}
@@ -330,6 +448,26 @@ func (r *eventReporter) ErrorWith(err error, meta common.MapStr) bool {
 
 // other utility functions
 
+// getRatesConfig returns the configuration of the rates of the module, or nil
+// if they are not enabled.
+func getRatesConfig(config *common.Config) (*ratesConfig, error) {
+	raw := struct {
+		Rates *common.Config `config:"rates"`
+	}{}
+	if err := config.Unpack(&raw); err != nil {
+		return nil, err
+	}
+	if raw.Rates == nil || !raw.Rates.Enabled() {
+		return nil, nil
+	}
+
+	rates := defaultRatesConfig()
+	if err := raw.Rates.Unpack(&rates); err != nil {
+		return nil, err
+	}
+	return &rates, nil
+}
+
 func writeEvent(done <-chan struct{}, out chan<- beat.Event, event beat.Event) bool {
 	select {
 	case <-done:
@@ -357,6 +495,8 @@ func getMetricSetStats(module, name string) *stats {
 		success:  monitoring.NewInt(reg, successesKey),
 		failures: monitoring.NewInt(reg, failuresKey),
 		events:   monitoring.NewInt(reg, eventsKey),
+		timeouts: monitoring.NewInt(reg, timeoutsKey),
+		skipped:  monitoring.NewInt(reg, skippedKey),
 	}
 
 	fetches[key] = s
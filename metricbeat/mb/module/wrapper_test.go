@@ -3,10 +3,12 @@
 package module_test
 
 import (
+	"context"
 	"testing"
 	"time"
 
 	"github.com/elastic/beats/libbeat/common"
+	"github.com/elastic/beats/libbeat/monitoring"
 	"github.com/elastic/beats/metricbeat/mb"
 	"github.com/elastic/beats/metricbeat/mb/module"
 
@@ -18,6 +20,8 @@ const (
 	eventFetcherName     = "EventFetcher"
 	reportingFetcherName = "ReportingFetcher"
 	pushMetricSetName    = "PushMetricSet"
+	hangingFetcherName   = "HangingFetcher"
+	contextFetcherName   = "ContextFetcher"
 )
 
 // fakeMetricSet
@@ -32,6 +36,12 @@ func init() {
 	if err := mb.Registry.AddMetricSet(moduleName, pushMetricSetName, newFakePushMetricSet); err != nil {
 		panic(err)
 	}
+	if err := mb.Registry.AddMetricSet(moduleName, hangingFetcherName, newFakeHangingFetcher); err != nil {
+		panic(err)
+	}
+	if err := mb.Registry.AddMetricSet(moduleName, contextFetcherName, newFakeContextFetcher); err != nil {
+		panic(err)
+	}
 }
 
 // EventFetcher
@@ -85,6 +95,44 @@ func newFakePushMetricSet(base mb.BaseMetricSet) (mb.MetricSet, error) {
 	return &fakePushMetricSet{BaseMetricSet: base}, nil
 }
 
+// HangingFetcher
+
+// hangingFetcherRelease is closed to unblock the fetches of the
+// HangingFetchers created since it was set.
+var hangingFetcherRelease chan struct{}
+
+type fakeHangingFetcher struct {
+	mb.BaseMetricSet
+	release chan struct{}
+}
+
+func (ms *fakeHangingFetcher) Fetch(r mb.Reporter) {
+	<-ms.release
+	r.Event(common.MapStr{"metric": 1})
+}
+
+func newFakeHangingFetcher(base mb.BaseMetricSet) (mb.MetricSet, error) {
+	return &fakeHangingFetcher{BaseMetricSet: base, release: hangingFetcherRelease}, nil
+}
+
+// ContextFetcher
+
+// contextFetcherCancelled receives the error of every cancelled fetch.
+var contextFetcherCancelled = make(chan error, 10)
+
+type fakeContextFetcher struct {
+	mb.BaseMetricSet
+}
+
+func (ms *fakeContextFetcher) Fetch(ctx context.Context, r mb.Reporter) {
+	<-ctx.Done()
+	contextFetcherCancelled <- ctx.Err()
+}
+
+func newFakeContextFetcher(base mb.BaseMetricSet) (mb.MetricSet, error) {
+	return &fakeContextFetcher{BaseMetricSet: base}, nil
+}
+
 // test utilities
 
 func newTestRegistry(t testing.TB) *mb.Register {
@@ -99,6 +147,12 @@ func newTestRegistry(t testing.TB) *mb.Register {
 	if err := r.AddMetricSet(moduleName, pushMetricSetName, newFakePushMetricSet); err != nil {
 		t.Fatal(err)
 	}
+	if err := r.AddMetricSet(moduleName, hangingFetcherName, newFakeHangingFetcher); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.AddMetricSet(moduleName, contextFetcherName, newFakeContextFetcher); err != nil {
+		t.Fatal(err)
+	}
 
 	return r
 }
@@ -210,3 +264,107 @@ func TestWrapperOfPushMetricSet(t *testing.T) {
 		}
 	}
 }
+
+func TestWrapperFetchTimeout(t *testing.T) {
+	c := newConfig(t, map[string]interface{}{
+		"module":     moduleName,
+		"metricsets": []string{hangingFetcherName},
+		"hosts":      []string{"alpha"},
+		"period":     "10ms",
+		"timeout":    "20ms",
+	})
+
+	hangingFetcherRelease = make(chan struct{})
+	m, err := module.NewWrapper(0, c, newTestRegistry(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	done := make(chan struct{})
+	output := m.Start(done)
+
+	event := <-output
+	errMsg, err := event.Fields.GetValue("error.message")
+	if assert.NoError(t, err) {
+		assert.Contains(t, errMsg, "timeout after 20ms")
+	}
+	rtt, err := event.Fields.GetValue("metricset.rtt")
+	if assert.NoError(t, err) {
+		assert.True(t, rtt.(int64) >= int64(20*time.Millisecond/time.Microsecond))
+	}
+
+	// The first fetch is still hanging, so the following ones are skipped
+	// instead of being queued.
+	select {
+	case e := <-output:
+		assert.Fail(t, "received unexpected event", "%v", e)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	skipped := monitoring.Default.Get("metricbeat.fake.hangingfetcher.skipped").(*monitoring.Int)
+	assert.True(t, skipped.Get() > 0)
+	timeouts := monitoring.Default.Get("metricbeat.fake.hangingfetcher.timeouts").(*monitoring.Int)
+	assert.Equal(t, int64(1), timeouts.Get())
+
+	// Events reported by the timed out fetch are dropped.
+	close(hangingFetcherRelease)
+	close(done)
+	for e := range output {
+		assert.Fail(t, "received unexpected event", "%v", e)
+	}
+}
+
+func TestWrapperOfReportingFetcherWithContext(t *testing.T) {
+	c := newConfig(t, map[string]interface{}{
+		"module":     moduleName,
+		"metricsets": []string{contextFetcherName},
+		"hosts":      []string{"alpha"},
+		"period":     "1h",
+		"timeout":    "10ms",
+	})
+
+	m, err := module.NewWrapper(0, c, newTestRegistry(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	done := make(chan struct{})
+	output := m.Start(done)
+
+	event := <-output
+	errMsg, err := event.Fields.GetValue("error.message")
+	if assert.NoError(t, err) {
+		assert.Contains(t, errMsg, "timeout after 10ms")
+	}
+	assert.Equal(t, context.DeadlineExceeded, <-contextFetcherCancelled)
+
+	close(done)
+	for e := range output {
+		assert.Fail(t, "received unexpected event", "%v", e)
+	}
+}
+
+func TestWrapperRatesConfig(t *testing.T) {
+	c := newConfig(t, map[string]interface{}{
+		"module":     moduleName,
+		"metricsets": []string{eventFetcherName},
+		"hosts":      []string{"alpha"},
+		"rates":      map[string]interface{}{"suffix": "_per_sec"},
+	})
+
+	_, err := module.NewWrapper(0, c, newTestRegistry(t))
+	assert.Error(t, err, "rates without fields must fail")
+
+	c = newConfig(t, map[string]interface{}{
+		"module":     moduleName,
+		"metricsets": []string{eventFetcherName},
+		"hosts":      []string{"alpha"},
+		"rates": map[string]interface{}{
+			"fields":  []string{"fake.EventFetcher.metric"},
+			"enabled": false,
+		},
+	})
+
+	_, err = module.NewWrapper(0, c, newTestRegistry(t))
+	assert.NoError(t, err)
+}
@@ -170,15 +170,22 @@ func getURL(rawURL, scheme, username, password, path, query string) (*url.URL, e
 	}
 
 	// Add the query params to existing query parameters overwriting any
-	// keys that already exist.
-	q := u.Query()
-	params, err := url.ParseQuery(query)
-	for key, values := range params {
-		for _, v := range values {
-			q.Set(key, v)
+	// keys that already exist. The query is kept as is if there are no
+	// params to add, as some services expect it in a particular format, like
+	// the HAProxy stats page (/haproxy?stats).
+	if query != "" {
+		q := u.Query()
+		params, err := url.ParseQuery(query)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing query params: %v", err)
+		}
+		for key, values := range params {
+			for _, v := range values {
+				q.Set(key, v)
+			}
 		}
+		u.RawQuery = q.Encode()
 	}
-	u.RawQuery = q.Encode()
 
 	return u, nil
 }
@@ -37,6 +37,7 @@ that Metricbeat does it and with the same validations.
 package testing
 
 import (
+	"context"
 	"sync"
 	"testing"
 	"time"
@@ -138,6 +139,29 @@ func ReportingFetch(metricSet mb.ReportingMetricSet) ([]common.MapStr, []error)
 	return r.events, r.errs
 }
 
+// NewReportingMetricSetWithContext instantiates a new
+// ReportingMetricSetWithContext using the given configuration. The
+// ModuleFactory and MetricSetFactory are obtained from the global Registry.
+func NewReportingMetricSetWithContext(t testing.TB, config interface{}) mb.ReportingMetricSetWithContext {
+	metricSet := newMetricSet(t, config)
+
+	reportingMetricSet, ok := metricSet.(mb.ReportingMetricSetWithContext)
+	if !ok {
+		t.Fatal("MetricSet does not implement ReportingMetricSetWithContext")
+	}
+
+	return reportingMetricSet
+}
+
+// ReportingFetchWithContext runs the given reporting metricset with the
+// context and returns all of the events and errors that occur during that
+// period.
+func ReportingFetchWithContext(ctx context.Context, metricSet mb.ReportingMetricSetWithContext) ([]common.MapStr, []error) {
+	r := &capturingReporter{}
+	metricSet.Fetch(ctx, r)
+	return r.events, r.errs
+}
+
 // NewPushMetricSet instantiates a new PushMetricSet using the given
 // configuration. The ModuleFactory and MetricSetFactory are obtained from the
 // global Registry.
@@ -0,0 +1,69 @@
+package event
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+
+	"github.com/elastic/beats/metricbeat/module/docker"
+)
+
+// newHTTPClient returns an HTTP client connecting to the Docker endpoint and
+// the base URL of the API. Endpoints can be unix sockets or TCP addresses,
+// like unix:///var/run/docker.sock or tcp://localhost:2375.
+func newHTTPClient(endpoint string, config *docker.TLSConfig) (*http.Client, string, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, "", err
+	}
+
+	switch u.Scheme {
+	case "unix":
+		socket := u.Path
+		transport := &http.Transport{
+			Dial: func(_, _ string) (net.Conn, error) {
+				return net.Dial("unix", socket)
+			},
+		}
+		// The host is not used to connect, only to build valid URLs.
+		return &http.Client{Transport: transport}, "http://docker", nil
+	case "tcp", "http", "https":
+		if !config.IsEnabled() {
+			return &http.Client{}, "http://" + u.Host, nil
+		}
+
+		tlsConfig, err := loadTLSConfig(config)
+		if err != nil {
+			return nil, "", err
+		}
+		transport := &http.Transport{TLSClientConfig: tlsConfig}
+		return &http.Client{Transport: transport}, "https://" + u.Host, nil
+	default:
+		return nil, "", fmt.Errorf("unsupported docker endpoint '%s'", endpoint)
+	}
+}
+
+func loadTLSConfig(config *docker.TLSConfig) (*tls.Config, error) {
+	cert, err := tls.LoadX509KeyPair(config.Certificate, config.Key)
+	if err != nil {
+		return nil, err
+	}
+	tlsConfig := &tls.Config{Certificates: []tls.Certificate{cert}}
+
+	if config.CA != "" {
+		ca, err := ioutil.ReadFile(config.CA)
+		if err != nil {
+			return nil, err
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(ca) {
+			return nil, fmt.Errorf("no certificates found in %s", config.CA)
+		}
+		tlsConfig.RootCAs = pool
+	}
+	return tlsConfig, nil
+}
@@ -0,0 +1,43 @@
+package event
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/elastic/beats/metricbeat/module/docker"
+)
+
+// Config is the configuration of the docker event metricset.
+type Config struct {
+	docker.Config `config:",inline"`
+	Event         EventConfig `config:"event"`
+}
+
+// EventConfig contains the filters and the reconnection settings of the
+// events stream.
+type EventConfig struct {
+	// Types of the objects the events are reported for, e.g. container.
+	Types []string `config:"types"`
+	// Actions reported, e.g. die or oom.
+	Actions []string `config:"actions"`
+	Backoff struct {
+		Init time.Duration `config:"init" validate:"positive"`
+		Max  time.Duration `config:"max" validate:"positive"`
+	} `config:"backoff"`
+}
+
+func defaultConfig() Config {
+	var c Config
+	c.Event.Backoff.Init = time.Second
+	c.Event.Backoff.Max = time.Minute
+	return c
+}
+
+// Validate validates the config.
+func (c *EventConfig) Validate() error {
+	if c.Backoff.Max < c.Backoff.Init {
+		return fmt.Errorf("event.backoff.max (%v) must be greater than event.backoff.init (%v)",
+			c.Backoff.Max, c.Backoff.Init)
+	}
+	return nil
+}
@@ -0,0 +1,139 @@
+package event
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	dc "github.com/fsouza/go-dockerclient"
+
+	"github.com/elastic/beats/libbeat/common"
+	"github.com/elastic/beats/libbeat/common/cfgwarn"
+	"github.com/elastic/beats/libbeat/logp"
+	"github.com/elastic/beats/metricbeat/mb"
+	"github.com/elastic/beats/metricbeat/module/docker"
+)
+
+var debugf = logp.MakeDebug("docker.event")
+
+func init() {
+	if err := mb.Registry.AddMetricSet("docker", "event", New, docker.HostParser); err != nil {
+		panic(err)
+	}
+}
+
+// MetricSet type defines all fields of the MetricSet
+// The event MetricSet listens to the events of the Docker daemon and streams them to the output.
+// MetricSet implements the mb.PushMetricSet interface, and therefore does not rely on polling.
+type MetricSet struct {
+	mb.BaseMetricSet
+	watcher *watcher
+}
+
+// New creates a new instance of the docker event MetricSet.
+func New(base mb.BaseMetricSet) (mb.MetricSet, error) {
+	cfgwarn.Experimental("The docker event metricset is experimental")
+
+	config := defaultConfig()
+	if err := base.Module().UnpackConfig(&config); err != nil {
+		return nil, err
+	}
+
+	client, baseURL, err := newHTTPClient(base.HostData().URI, config.TLS)
+	if err != nil {
+		return nil, err
+	}
+
+	watcher, err := newWatcher(client, baseURL, config.Event)
+	if err != nil {
+		return nil, err
+	}
+
+	return &MetricSet{
+		BaseMetricSet: base,
+		watcher:       watcher,
+	}, nil
+}
+
+// Run streams the events of the Docker daemon until the reporter is done.
+func (m *MetricSet) Run(reporter mb.PushReporter) {
+	m.watcher.run(reporter.Done(), func(event *dc.APIEvents) {
+		reporter.Event(eventMapping(event))
+	})
+}
+
+// Attributes of the container events that are not labels.
+var containerAttributes = map[string]bool{
+	"name":     true,
+	"image":    true,
+	"exitCode": true,
+	"signal":   true,
+	"execID":   true,
+}
+
+func eventMapping(event *dc.APIEvents) common.MapStr {
+	// Events from daemons older than API 1.22 only have the status and the id.
+	action, typ, id := event.Action, event.Type, event.Actor.ID
+	if action == "" {
+		action = event.Status
+	}
+	if typ == "" {
+		typ = "container"
+	}
+	if id == "" {
+		id = event.ID
+	}
+
+	data := common.MapStr{
+		"type":   typ,
+		"action": action,
+		"actor": common.MapStr{
+			"id": id,
+		},
+	}
+	if event.TimeNano != 0 {
+		data["@timestamp"] = common.Time(time.Unix(0, event.TimeNano))
+	} else if event.Time != 0 {
+		data["@timestamp"] = common.Time(time.Unix(event.Time, 0))
+	}
+
+	if typ != "container" {
+		if len(event.Actor.Attributes) > 0 {
+			data.Put("actor.attributes", docker.DeDotLabels(event.Actor.Attributes))
+		}
+		return data
+	}
+
+	attributes := event.Actor.Attributes
+	if exitCode, err := strconv.Atoi(attributes["exitCode"]); err == nil {
+		data["exit_code"] = exitCode
+	}
+	if image := attributes["image"]; image != "" {
+		data["image"] = image
+	} else if event.From != "" {
+		data["image"] = event.From
+	}
+
+	labels := map[string]string{}
+	for k, v := range attributes {
+		if !containerAttributes[k] {
+			labels[k] = v
+		}
+	}
+
+	// Health status actions contain the status, e.g. "health_status: healthy".
+	if strings.HasPrefix(action, "health_status: ") {
+		data["action"] = "health_status"
+		data["health_status"] = strings.TrimPrefix(action, "health_status: ")
+	}
+
+	container := docker.Container{
+		ID:     id,
+		Name:   attributes["name"],
+		Labels: docker.DeDotLabels(labels),
+	}
+	data[mb.ModuleDataKey] = common.MapStr{
+		"container": container.ToMapStr(),
+	}
+	return data
+}
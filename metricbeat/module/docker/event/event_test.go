@@ -0,0 +1,188 @@
+// +build !integration
+
+package event
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	dc "github.com/fsouza/go-dockerclient"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/elastic/beats/libbeat/common"
+	mbtest "github.com/elastic/beats/metricbeat/mb/testing"
+)
+
+const (
+	dieEvent = `{"status":"die","id":"e0b6d6a6e8c2","from":"nginx:1.13","Type":"container","Action":"die",` +
+		`"Actor":{"ID":"e0b6d6a6e8c2","Attributes":{"exitCode":"137","image":"nginx:1.13","name":"web",` +
+		`"com.example.team":"frontend"}},"time":1508332612,"timeNano":1508332612123456789}`
+	oomEvent = `{"status":"oom","id":"e0b6d6a6e8c2","from":"nginx:1.13","Type":"container","Action":"oom",` +
+		`"Actor":{"ID":"e0b6d6a6e8c2","Attributes":{"image":"nginx:1.13","name":"web"}},` +
+		`"time":1508332613,"timeNano":1508332613000000000}`
+	networkEvent = `{"Type":"network","Action":"connect","Actor":{"ID":"7c5f0e5a1b1c",` +
+		`"Attributes":{"container":"e0b6d6a6e8c2","name":"bridge","type":"bridge"}},` +
+		`"time":1508332614,"timeNano":1508332614000000000}`
+)
+
+// fakeDocker is a Docker daemon listening on a unix socket that sends the
+// events of the first connection, then closes it, and sends the events of
+// the second connection.
+type fakeDocker struct {
+	server   *httptest.Server
+	dir      string
+	mutex    sync.Mutex
+	requests []*http.Request
+	streams  [][]string
+}
+
+func newFakeDocker(t *testing.T, streams ...[]string) *fakeDocker {
+	dir, err := ioutil.TempDir("", "docker-event")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	listener, err := net.Listen("unix", filepath.Join(dir, "docker.sock"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	d := &fakeDocker{dir: dir, streams: streams}
+	d.server = httptest.NewUnstartedServer(http.HandlerFunc(d.handle))
+	d.server.Listener = listener
+	d.server.Start()
+	return d
+}
+
+func (d *fakeDocker) handle(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/events" {
+		w.WriteHeader(404)
+		return
+	}
+
+	d.mutex.Lock()
+	n := len(d.requests)
+	d.requests = append(d.requests, r)
+	d.mutex.Unlock()
+
+	if n >= len(d.streams) {
+		w.WriteHeader(503)
+		return
+	}
+	for _, event := range d.streams[n] {
+		fmt.Fprintln(w, event)
+		w.(http.Flusher).Flush()
+	}
+}
+
+func (d *fakeDocker) close() {
+	d.server.Close()
+	os.RemoveAll(d.dir)
+}
+
+func (d *fakeDocker) endpoint() string {
+	return "unix://" + filepath.Join(d.dir, "docker.sock")
+}
+
+func getConfig(endpoint string) map[string]interface{} {
+	return map[string]interface{}{
+		"module":     "docker",
+		"metricsets": []string{"event"},
+		"hosts":      []string{endpoint},
+		"event": map[string]interface{}{
+			"types":   []string{"container", "network"},
+			"actions": []string{"die", "oom", "connect"},
+			"backoff": map[string]interface{}{"init": "10ms", "max": "20ms"},
+		},
+	}
+}
+
+func TestFetchEvents(t *testing.T) {
+	// The die event is sent again after the reconnection and is deduplicated.
+	docker := newFakeDocker(t, []string{dieEvent}, []string{dieEvent, oomEvent, networkEvent})
+	defer docker.close()
+
+	ms := mbtest.NewPushMetricSet(t, getConfig(docker.endpoint()))
+	events, errs := mbtest.RunPushMetricSet(500*time.Millisecond, ms)
+	assert.Empty(t, errs)
+	if !assert.Len(t, events, 3) {
+		return
+	}
+
+	die := events[0]
+	assert.Equal(t, "die", die["action"])
+	assert.Equal(t, "container", die["type"])
+	assert.Equal(t, 137, die["exit_code"])
+	assert.Equal(t, "nginx:1.13", die["image"])
+	assert.Equal(t, common.Time(time.Unix(0, 1508332612123456789)), die["@timestamp"])
+	assert.Equal(t, common.MapStr{
+		"id":     "e0b6d6a6e8c2",
+		"name":   "web",
+		"labels": common.MapStr{"com_example_team": "frontend"},
+	}, die["_module"].(common.MapStr)["container"])
+
+	assert.Equal(t, "oom", events[1]["action"])
+
+	network := events[2]
+	assert.Equal(t, "network", network["type"])
+	attributes, _ := network.GetValue("actor.attributes")
+	assert.Equal(t, common.MapStr{"container": "e0b6d6a6e8c2", "name": "bridge", "type": "bridge"}, attributes)
+	assert.NotContains(t, network, "_module")
+
+	docker.mutex.Lock()
+	defer docker.mutex.Unlock()
+	if assert.True(t, len(docker.requests) >= 3, "the watcher must reconnect") {
+		filters := docker.requests[0].URL.Query().Get("filters")
+		assert.JSONEq(t, `{"type":["container","network"],"event":["die","oom","connect"]}`, filters)
+		assert.Empty(t, docker.requests[0].URL.Query().Get("since"))
+		assert.Equal(t, "1508332612.123456789", docker.requests[1].URL.Query().Get("since"))
+		assert.Equal(t, "1508332614.000000000", docker.requests[2].URL.Query().Get("since"))
+	}
+}
+
+func TestEventMappingLegacy(t *testing.T) {
+	event := eventMapping(&dc.APIEvents{
+		Status: "start",
+		ID:     "e0b6d6a6e8c2",
+		From:   "nginx:1.13",
+		Time:   1508332612,
+	})
+
+	assert.Equal(t, "start", event["action"])
+	assert.Equal(t, "container", event["type"])
+	assert.Equal(t, "nginx:1.13", event["image"])
+	assert.Equal(t, common.Time(time.Unix(1508332612, 0)), event["@timestamp"])
+	id, _ := event.GetValue("actor.id")
+	assert.Equal(t, "e0b6d6a6e8c2", id)
+}
+
+func TestEventMappingHealthStatus(t *testing.T) {
+	event := eventMapping(&dc.APIEvents{
+		Type:   "container",
+		Action: "health_status: unhealthy",
+		Actor:  dc.APIActor{ID: "e0b6d6a6e8c2", Attributes: map[string]string{"name": "web"}},
+	})
+
+	assert.Equal(t, "health_status", event["action"])
+	assert.Equal(t, "unhealthy", event["health_status"])
+}
+
+func TestConfigValidation(t *testing.T) {
+	config := getConfig("unix:///var/run/docker.sock")
+	config["event"].(map[string]interface{})["backoff"] = map[string]interface{}{"init": "1m", "max": "1s"}
+
+	c, err := common.NewConfigFrom(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	eventConfig := defaultConfig()
+	assert.Error(t, c.Unpack(&eventConfig))
+}
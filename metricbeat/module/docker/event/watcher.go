@@ -0,0 +1,143 @@
+package event
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	dc "github.com/fsouza/go-dockerclient"
+
+	"github.com/elastic/beats/libbeat/common"
+	"github.com/elastic/beats/libbeat/logp"
+)
+
+// watcher streams the events of the Docker daemon. It reconnects with
+// exponential backoff when the stream is interrupted, and resumes it from the
+// last event received so no event is lost meanwhile.
+type watcher struct {
+	client  *http.Client
+	baseURL string
+	filters string
+	config  EventConfig
+
+	// Time of the first connection and of the last event received, in
+	// nanoseconds. The stream is resumed from them on reconnection.
+	start, last int64
+}
+
+func newWatcher(client *http.Client, baseURL string, config EventConfig) (*watcher, error) {
+	filters := map[string][]string{}
+	if len(config.Types) > 0 {
+		filters["type"] = config.Types
+	}
+	if len(config.Actions) > 0 {
+		filters["event"] = config.Actions
+	}
+
+	encoded, err := json.Marshal(filters)
+	if err != nil {
+		return nil, err
+	}
+
+	return &watcher{
+		client:  client,
+		baseURL: baseURL,
+		filters: string(encoded),
+		config:  config,
+	}, nil
+}
+
+// run streams the events to the handler until the done channel is closed.
+func (w *watcher) run(done <-chan struct{}, handler func(*dc.APIEvents)) {
+	backoff := common.NewBackoff(done, w.config.Backoff.Init, w.config.Backoff.Max)
+	for {
+		connected, err := w.stream(done, handler)
+
+		select {
+		case <-done:
+			return
+		default:
+		}
+
+		if connected {
+			backoff.Reset()
+		}
+		logp.Err("docker: events stream interrupted, reconnecting: %v", err)
+		if !backoff.Wait() {
+			return
+		}
+	}
+}
+
+// stream connects to the events endpoint and passes the events to the
+// handler until the connection is interrupted. It returns if the connection
+// succeeded and the error that interrupted it.
+func (w *watcher) stream(done <-chan struct{}, handler func(*dc.APIEvents)) (bool, error) {
+	params := url.Values{}
+	if w.filters != "{}" {
+		params.Set("filters", w.filters)
+	}
+	if since := w.resumeTime(); since > 0 {
+		params.Set("since", fmt.Sprintf("%d.%09d", since/int64(time.Second), since%int64(time.Second)))
+	}
+
+	req, err := http.NewRequest("GET", w.baseURL+"/events?"+params.Encode(), nil)
+	if err != nil {
+		return false, err
+	}
+
+	// Interrupt the stream when done is closed.
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		select {
+		case <-done:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	resp, err := w.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return false, fmt.Errorf("HTTP error %d in %s: %s", resp.StatusCode, req.URL.Path, resp.Status)
+	}
+
+	debugf("Connected to the events stream of %s", w.baseURL)
+	if w.start == 0 {
+		w.start = time.Now().UnixNano()
+	}
+
+	decoder := json.NewDecoder(resp.Body)
+	for {
+		var event dc.APIEvents
+		if err := decoder.Decode(&event); err != nil {
+			return true, err
+		}
+
+		// Events received before the reconnection may be sent again.
+		if event.TimeNano != 0 && event.TimeNano <= w.last {
+			continue
+		}
+		if event.TimeNano > w.last {
+			w.last = event.TimeNano
+		}
+		handler(&event)
+	}
+}
+
+// resumeTime returns the time from which the stream must be resumed, or 0 on
+// the first connection.
+func (w *watcher) resumeTime() int64 {
+	if w.last > 0 {
+		return w.last
+	}
+	return w.start
+}
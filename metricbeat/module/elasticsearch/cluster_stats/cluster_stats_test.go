@@ -0,0 +1,75 @@
+// +build !integration
+
+package cluster_stats
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	mbtest "github.com/elastic/beats/metricbeat/mb/testing"
+)
+
+func newServer(t *testing.T, masterNode string) *httptest.Server {
+	stats, err := ioutil.ReadFile("./_meta/test/cluster_stats.563.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/_nodes/_local/process":
+			w.Write([]byte(`{"cluster_name": "elasticsearch", "nodes": {"VKaOTz9cTTiq8HYbXQa3lw": {"name": "es-node-1"}}}`))
+		case "/_cluster/state/master_node":
+			w.Write([]byte(`{"cluster_name": "elasticsearch", "master_node": "` + masterNode + `"}`))
+		case "/_cluster/stats":
+			w.Write(stats)
+		default:
+			w.WriteHeader(404)
+		}
+	}))
+}
+
+func getConfig(host string) map[string]interface{} {
+	return map[string]interface{}{
+		"module":     "elasticsearch",
+		"metricsets": []string{"cluster_stats"},
+		"hosts":      []string{host},
+	}
+}
+
+func TestFetchOnMaster(t *testing.T) {
+	server := newServer(t, "VKaOTz9cTTiq8HYbXQa3lw")
+	defer server.Close()
+
+	f := mbtest.NewEventFetcher(t, getConfig(server.URL))
+	event, err := f.Fetch()
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Equal(t, "yellow", event["status"])
+}
+
+func TestFetchOnOtherNode(t *testing.T) {
+	server := newServer(t, "jbqVLK6XTiW4m0SnYT6F1Q")
+	defer server.Close()
+
+	f := mbtest.NewEventFetcher(t, getConfig(server.URL))
+	event, err := f.Fetch()
+	assert.NoError(t, err)
+	assert.Nil(t, event)
+}
+
+func TestFetchWithoutMaster(t *testing.T) {
+	server := newServer(t, "")
+	defer server.Close()
+
+	f := mbtest.NewEventFetcher(t, getConfig(server.URL))
+	_, err := f.Fetch()
+	assert.Error(t, err)
+}
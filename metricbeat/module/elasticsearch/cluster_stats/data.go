@@ -0,0 +1,78 @@
+package cluster_stats
+
+import (
+	"encoding/json"
+
+	"github.com/elastic/beats/libbeat/common"
+	s "github.com/elastic/beats/libbeat/common/schema"
+	c "github.com/elastic/beats/libbeat/common/schema/mapstriface"
+	"github.com/elastic/beats/metricbeat/mb"
+)
+
+var (
+	schema = s.Schema{
+		"status": c.Str("status"),
+		"indices": c.Dict("indices", s.Schema{
+			"count": c.Int("count"),
+			"shards": c.Dict("shards", s.Schema{
+				"total":     c.Int("total", s.Optional),
+				"primaries": c.Int("primaries", s.Optional),
+			}),
+			"docs": c.Dict("docs", s.Schema{
+				"count":   c.Int("count"),
+				"deleted": c.Int("deleted"),
+			}),
+			"store": c.Dict("store", s.Schema{
+				"size": s.Object{
+					"bytes": c.Int("size_in_bytes"),
+				},
+			}),
+			"segments": c.Dict("segments", s.Schema{
+				"count": c.Int("count"),
+				"memory": s.Object{
+					"bytes": c.Int("memory_in_bytes"),
+				},
+			}),
+		}),
+		"nodes": c.Dict("nodes", s.Schema{
+			"count": c.Dict("count", s.Schema{
+				"total":  c.Int("total"),
+				"data":   c.Int("data"),
+				"master": c.Int("master"),
+				"ingest": c.Int("ingest", s.Optional),
+			}),
+			"jvm": c.Dict("jvm", s.Schema{
+				"mem": c.Dict("mem", s.Schema{
+					"heap_used": s.Object{
+						"bytes": c.Int("heap_used_in_bytes"),
+					},
+					"heap_max": s.Object{
+						"bytes": c.Int("heap_max_in_bytes"),
+					},
+				}),
+			}),
+		}),
+	}
+)
+
+func eventMapping(content []byte) (common.MapStr, error) {
+	var stats map[string]interface{}
+	if err := json.Unmarshal(content, &stats); err != nil {
+		return nil, err
+	}
+
+	event, errs := schema.Apply(stats)
+
+	clusterName, _ := stats["cluster_name"].(string)
+	event[mb.ModuleDataKey] = common.MapStr{
+		"cluster": common.MapStr{
+			"name": clusterName,
+		},
+	}
+	event[mb.NamespaceKey] = "cluster.stats"
+
+	if errs.HasRequiredErrors() {
+		return event, errs
+	}
+	return event, nil
+}
@@ -0,0 +1,57 @@
+// +build !integration
+
+package cluster_stats
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/elastic/beats/libbeat/common"
+	s "github.com/elastic/beats/libbeat/common/schema"
+)
+
+func TestStats(t *testing.T) {
+	files, err := filepath.Glob("./_meta/test/cluster_stats.*.json")
+	assert.NoError(t, err)
+
+	for _, f := range files {
+		content, err := ioutil.ReadFile(f)
+		assert.NoError(t, err)
+
+		_, errs := eventMapping(content)
+		if errs == nil {
+			continue
+		}
+		errors, ok := errs.(*s.Errors)
+		if ok {
+			assert.False(t, errors.HasRequiredErrors(), "mapping error: %s", errors)
+		} else {
+			t.Error(errs)
+		}
+	}
+}
+
+func TestEventMapping(t *testing.T) {
+	content, err := ioutil.ReadFile("./_meta/test/cluster_stats.563.json")
+	assert.NoError(t, err)
+
+	event, _ := eventMapping(content)
+
+	assert.Equal(t, "yellow", event["status"])
+	assert.Equal(t, common.MapStr{"total": int64(15), "primaries": int64(11)},
+		event["indices"].(common.MapStr)["shards"])
+
+	nodes, _ := event.GetValue("nodes.count.total")
+	assert.Equal(t, int64(2), nodes)
+
+	clusterName, _ := event.GetValue("_module.cluster.name")
+	assert.Equal(t, "elasticsearch", clusterName)
+}
+
+func TestInvalid(t *testing.T) {
+	_, err := eventMapping([]byte("not json"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,128 @@
+package elasticsearch
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/elastic/beats/metricbeat/helper"
+)
+
+// BaseURI returns the given URI of a metricset without the metricset path, so
+// other endpoints can be requested relative to any path prefix configured for
+// the host, for example when Elasticsearch is behind a proxy. The path of the
+// URI is kept as is if it doesn't end with the metricset path.
+func BaseURI(uri, metricsetPath string) (string, error) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return "", err
+	}
+	u.Path = strings.TrimSuffix(u.Path, "/"+strings.Trim(metricsetPath, "/"))
+	u.RawQuery = ""
+	return u.String(), nil
+}
+
+// joinPath returns the given base URI with the path appended to its path.
+func joinPath(baseURI, path string) (string, error) {
+	u, err := url.Parse(baseURI)
+	if err != nil {
+		return "", err
+	}
+	u.Path = strings.TrimSuffix(u.Path, "/") + "/" + strings.TrimPrefix(path, "/")
+	u.RawQuery = ""
+	return u.String(), nil
+}
+
+// FetchPath fetches the given path relative to the given base URI, as returned
+// by BaseURI. The URI of the HTTP helper is restored afterwards.
+func FetchPath(http *helper.HTTP, baseURI, path string) ([]byte, error) {
+	uri, err := joinPath(baseURI, path)
+	if err != nil {
+		return nil, err
+	}
+
+	defer http.SetURI(http.GetURI())
+	http.SetURI(uri)
+
+	return http.FetchContent()
+}
+
+// MasterChecker checks if the local node of a host is the elected master of
+// the cluster. It is used by the metricsets collecting cluster wide data, to
+// only report it once per cluster.
+type MasterChecker struct {
+	http    *helper.HTTP
+	baseURI string
+	nodeID  string // ID of the local node, requested on the first check
+}
+
+// NewMasterChecker returns a MasterChecker for the host of the given metricset
+// URI and path.
+func NewMasterChecker(http *helper.HTTP, uri, metricsetPath string) (*MasterChecker, error) {
+	baseURI, err := BaseURI(uri, metricsetPath)
+	if err != nil {
+		return nil, err
+	}
+	return &MasterChecker{http: http, baseURI: baseURI}, nil
+}
+
+// IsMaster checks if the local node is the elected master. The ID of the local
+// node doesn't change while the node keeps its data path, so it is requested
+// on the first check only.
+func (c *MasterChecker) IsMaster() (bool, error) {
+	if c.nodeID == "" {
+		nodeID, err := getNodeID(c.http, c.baseURI)
+		if err != nil {
+			return false, err
+		}
+		c.nodeID = nodeID
+	}
+
+	masterID, err := getMasterNodeID(c.http, c.baseURI)
+	if err != nil {
+		return false, err
+	}
+	return c.nodeID == masterID, nil
+}
+
+func getNodeID(http *helper.HTTP, baseURI string) (string, error) {
+	content, err := FetchPath(http, baseURI, "_nodes/_local/process")
+	if err != nil {
+		return "", err
+	}
+
+	nodes := struct {
+		Nodes map[string]interface{} `json:"nodes"`
+	}{}
+	if err := json.Unmarshal(content, &nodes); err != nil {
+		return "", err
+	}
+
+	if len(nodes.Nodes) != 1 {
+		return "", fmt.Errorf("expected one local node, got %d", len(nodes.Nodes))
+	}
+	for id := range nodes.Nodes {
+		return id, nil
+	}
+	return "", nil
+}
+
+func getMasterNodeID(http *helper.HTTP, baseURI string) (string, error) {
+	content, err := FetchPath(http, baseURI, "_cluster/state/master_node")
+	if err != nil {
+		return "", err
+	}
+
+	state := struct {
+		MasterNode string `json:"master_node"`
+	}{}
+	if err := json.Unmarshal(content, &state); err != nil {
+		return "", err
+	}
+
+	if state.MasterNode == "" {
+		return "", fmt.Errorf("no master node elected")
+	}
+	return state.MasterNode, nil
+}
@@ -0,0 +1,46 @@
+// +build !integration
+
+package elasticsearch
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBaseURI(t *testing.T) {
+	tests := []struct {
+		uri, metricsetPath, expected string
+	}{
+		{"http://localhost:9200/_cluster/stats", "_cluster/stats", "http://localhost:9200"},
+		{"http://localhost:9200/es/_cluster/stats", "_cluster/stats", "http://localhost:9200/es"},
+		{"http://localhost:9200/es/_stats?level=shards", "_stats", "http://localhost:9200/es"},
+		{"http://localhost:9200/es/_cluster/state/nodes,routing_table", "_cluster/state/nodes,routing_table", "http://localhost:9200/es"},
+		{"http://localhost:9200/es", "_cluster/stats", "http://localhost:9200/es"},
+	}
+
+	for _, test := range tests {
+		baseURI, err := BaseURI(test.uri, test.metricsetPath)
+		if assert.NoError(t, err, test.uri) {
+			assert.Equal(t, test.expected, baseURI, test.uri)
+		}
+	}
+}
+
+func TestJoinPath(t *testing.T) {
+	tests := []struct {
+		baseURI, path, expected string
+	}{
+		{"http://localhost:9200", "_nodes/_local/process", "http://localhost:9200/_nodes/_local/process"},
+		{"http://localhost:9200/", "/_nodes/_local/process", "http://localhost:9200/_nodes/_local/process"},
+		{"http://localhost:9200/es", "_cluster/state/master_node", "http://localhost:9200/es/_cluster/state/master_node"},
+		{"http://localhost:9200/es/", "_cluster/state/master_node", "http://localhost:9200/es/_cluster/state/master_node"},
+	}
+
+	for _, test := range tests {
+		uri, err := joinPath(test.baseURI, test.path)
+		if assert.NoError(t, err, test.baseURI) {
+			assert.Equal(t, test.expected, uri, test.baseURI)
+		}
+	}
+}
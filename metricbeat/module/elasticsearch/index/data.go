@@ -0,0 +1,80 @@
+package index
+
+import (
+	"encoding/json"
+
+	"github.com/elastic/beats/libbeat/common"
+	s "github.com/elastic/beats/libbeat/common/schema"
+	c "github.com/elastic/beats/libbeat/common/schema/mapstriface"
+	"github.com/elastic/beats/metricbeat/mb"
+)
+
+var (
+	docsSchema = c.Dict("docs", s.Schema{
+		"count":   c.Int("count"),
+		"deleted": c.Int("deleted"),
+	})
+
+	storeSchema = c.Dict("store", s.Schema{
+		"size": s.Object{
+			"bytes": c.Int("size_in_bytes"),
+		},
+	})
+
+	schema = s.Schema{
+		"primaries": c.Dict("primaries", s.Schema{
+			"docs":  docsSchema,
+			"store": storeSchema,
+		}),
+		"total": c.Dict("total", s.Schema{
+			"docs":  docsSchema,
+			"store": storeSchema,
+			"indexing": c.Dict("indexing", s.Schema{
+				"index_total": c.Int("index_total"),
+				"index_time": s.Object{
+					"ms": c.Int("index_time_in_millis"),
+				},
+			}),
+			"search": c.Dict("search", s.Schema{
+				"query_total": c.Int("query_total"),
+				"query_time": s.Object{
+					"ms": c.Int("query_time_in_millis"),
+				},
+			}),
+			"segments": c.Dict("segments", s.Schema{
+				"count": c.Int("count"),
+				"memory": s.Object{
+					"bytes": c.Int("memory_in_bytes"),
+				},
+			}),
+		}),
+	}
+)
+
+type stats struct {
+	Indices map[string]map[string]interface{} `json:"indices"`
+}
+
+func eventsMapping(content []byte) ([]common.MapStr, error) {
+	var indicesStats stats
+	if err := json.Unmarshal(content, &indicesStats); err != nil {
+		return nil, err
+	}
+
+	var events []common.MapStr
+	errors := s.NewErrors()
+
+	for name, index := range indicesStats.Indices {
+		event, errs := schema.Apply(index)
+		errors.AddErrors(errs)
+
+		event["name"] = name
+		event[mb.NamespaceKey] = "index"
+		events = append(events, event)
+	}
+
+	if errors.HasRequiredErrors() {
+		return events, errors
+	}
+	return events, nil
+}
@@ -0,0 +1,62 @@
+// +build !integration
+
+package index
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	s "github.com/elastic/beats/libbeat/common/schema"
+)
+
+func TestStats(t *testing.T) {
+	files, err := filepath.Glob("./_meta/test/stats.*.json")
+	assert.NoError(t, err)
+
+	for _, f := range files {
+		content, err := ioutil.ReadFile(f)
+		assert.NoError(t, err)
+
+		_, errs := eventsMapping(content)
+		if errs == nil {
+			continue
+		}
+		errors, ok := errs.(*s.Errors)
+		if ok {
+			assert.False(t, errors.HasRequiredErrors(), "mapping error: %s", errors)
+		} else {
+			t.Error(errs)
+		}
+	}
+}
+
+func TestEventsMapping(t *testing.T) {
+	content, err := ioutil.ReadFile("./_meta/test/stats.563.json")
+	assert.NoError(t, err)
+
+	events, err := eventsMapping(content)
+	assert.NoError(t, err)
+	if !assert.Len(t, events, 2) {
+		return
+	}
+
+	for _, event := range events {
+		if event["name"] != ".kibana" {
+			continue
+		}
+
+		docs, _ := event.GetValue("primaries.docs.count")
+		assert.Equal(t, int64(4), docs)
+		size, _ := event.GetValue("total.store.size.bytes")
+		assert.Equal(t, int64(8800), size)
+		indexed, _ := event.GetValue("total.indexing.index_total")
+		assert.Equal(t, int64(8), indexed)
+		queries, _ := event.GetValue("total.search.query_total")
+		assert.Equal(t, int64(20), queries)
+		return
+	}
+	t.Error(".kibana index not found")
+}
@@ -0,0 +1,82 @@
+package index
+
+import (
+	"time"
+
+	"github.com/elastic/beats/libbeat/common"
+	"github.com/elastic/beats/libbeat/common/cfgwarn"
+	"github.com/elastic/beats/libbeat/logp"
+	"github.com/elastic/beats/metricbeat/helper"
+	"github.com/elastic/beats/metricbeat/mb"
+	"github.com/elastic/beats/metricbeat/mb/module"
+	"github.com/elastic/beats/metricbeat/mb/parse"
+	"github.com/elastic/beats/metricbeat/module/elasticsearch"
+)
+
+// init registers the MetricSet with the central registry.
+// The New method will be called after the setup of the module and before starting to fetch data
+func init() {
+	if err := mb.Registry.AddMetricSet("elasticsearch", "index", New, hostParser); err != nil {
+		panic(err)
+	}
+}
+
+const defaultPath = "_stats"
+
+var (
+	debugf = logp.MakeDebug("elasticsearch.index")
+
+	hostParser = parse.URLHostParserBuilder{
+		DefaultScheme: "http",
+		PathConfigKey: "path",
+		DefaultPath:   defaultPath,
+	}.Build()
+)
+
+// MetricSet type defines all fields of the MetricSet
+type MetricSet struct {
+	mb.BaseMetricSet
+	http   *helper.HTTP
+	master *elasticsearch.MasterChecker
+	rates  *module.Rates
+}
+
+// New create a new instance of the MetricSet
+func New(base mb.BaseMetricSet) (mb.MetricSet, error) {
+	cfgwarn.Experimental("The elasticsearch index metricset is experimental")
+
+	http := helper.NewHTTP(base)
+	master, err := elasticsearch.NewMasterChecker(http, base.HostData().SanitizedURI, defaultPath)
+	if err != nil {
+		return nil, err
+	}
+
+	return &MetricSet{
+		BaseMetricSet: base,
+		http:          http,
+		master:        master,
+		rates:         module.NewRates(base.Module().Config().Period),
+	}, nil
+}
+
+// Fetch fetches the stats of each index. Only the elected master reports
+// them, so they are reported once per cluster.
+func (m *MetricSet) Fetch() ([]common.MapStr, error) {
+	isMaster, err := m.master.IsMaster()
+	if err != nil {
+		return nil, err
+	}
+	if !isMaster {
+		debugf("%s is not the elected master, skipping index stats", m.Host())
+		return nil, nil
+	}
+
+	content, err := m.http.FetchContent()
+	if err != nil {
+		return nil, err
+	}
+
+	events, err := eventsMapping(content)
+	addRates(m.rates, events, time.Now())
+	return events, err
+}
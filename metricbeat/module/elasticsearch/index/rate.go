@@ -0,0 +1,41 @@
+package index
+
+import (
+	"time"
+
+	"github.com/elastic/beats/libbeat/common"
+	"github.com/elastic/beats/metricbeat/mb/module"
+)
+
+// rateFields are the counters of the indices whose rates per second are
+// reported, and the fields of the rates.
+var rateFields = map[string]string{
+	"total.indexing.index_total": "total.indexing.rate",
+	"total.search.query_total":   "total.search.rate",
+}
+
+// addRates adds the indexing and search rates since the previous fetch to the
+// events of the indices. Rates are not added on the first fetch of an index and
+// when its counters decreased, what happens when the index is recreated.
+func addRates(rates *module.Rates, events []common.MapStr, now time.Time) {
+	for _, event := range events {
+		name, ok := event["name"].(string)
+		if !ok {
+			continue
+		}
+
+		for field, rateField := range rateFields {
+			v, err := event.GetValue(field)
+			if err != nil {
+				continue
+			}
+			value, ok := module.ToFloat(v)
+			if !ok {
+				continue
+			}
+			if rate, ok := rates.Rate(name+"|"+field, value, now); ok {
+				event.Put(rateField, rate)
+			}
+		}
+	}
+}
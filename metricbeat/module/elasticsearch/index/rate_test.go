@@ -0,0 +1,53 @@
+// +build !integration
+
+package index
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/elastic/beats/libbeat/common"
+	"github.com/elastic/beats/metricbeat/mb/module"
+)
+
+func newIndexEvent(name string, indexed, queries int64) common.MapStr {
+	return common.MapStr{
+		"name": name,
+		"total": common.MapStr{
+			"indexing": common.MapStr{"index_total": indexed},
+			"search":   common.MapStr{"query_total": queries},
+		},
+	}
+}
+
+func TestAddRates(t *testing.T) {
+	rates := module.NewRates(10 * time.Second)
+	now := time.Now()
+
+	// First fetch, no rates yet.
+	first := []common.MapStr{newIndexEvent("foo", 100, 10), newIndexEvent("bar", 50, 5)}
+	addRates(rates, first, now)
+	for _, event := range first {
+		_, err := event.GetValue("total.indexing.rate")
+		assert.Error(t, err)
+	}
+
+	// Second fetch 10 seconds later, bar was recreated in between and baz is new.
+	second := []common.MapStr{newIndexEvent("foo", 200, 30), newIndexEvent("bar", 1, 0), newIndexEvent("baz", 10, 1)}
+	addRates(rates, second, now.Add(10*time.Second))
+
+	indexingRate, err := second[0].GetValue("total.indexing.rate")
+	assert.NoError(t, err)
+	assert.Equal(t, 10.0, indexingRate)
+	searchRate, err := second[0].GetValue("total.search.rate")
+	assert.NoError(t, err)
+	assert.Equal(t, 2.0, searchRate)
+
+	for _, event := range second[1:] {
+		_, err := event.GetValue("total.indexing.rate")
+		assert.Error(t, err, "unexpected rate for %s", event["name"])
+	}
+
+}
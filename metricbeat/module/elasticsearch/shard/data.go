@@ -0,0 +1,82 @@
+package shard
+
+import (
+	"encoding/json"
+
+	"github.com/elastic/beats/libbeat/common"
+	"github.com/elastic/beats/metricbeat/mb"
+)
+
+type clusterState struct {
+	ClusterName string `json:"cluster_name"`
+	Nodes       map[string]struct {
+		Name string `json:"name"`
+	} `json:"nodes"`
+	RoutingTable struct {
+		Indices map[string]struct {
+			Shards map[string][]shardRouting `json:"shards"`
+		} `json:"indices"`
+	} `json:"routing_table"`
+}
+
+type shardRouting struct {
+	State          string  `json:"state"`
+	Primary        bool    `json:"primary"`
+	Node           *string `json:"node"`
+	RelocatingNode *string `json:"relocating_node"`
+	Shard          int     `json:"shard"`
+	Index          string  `json:"index"`
+	UnassignedInfo *struct {
+		Reason string `json:"reason"`
+	} `json:"unassigned_info"`
+}
+
+func eventsMapping(content []byte) ([]common.MapStr, error) {
+	var state clusterState
+	if err := json.Unmarshal(content, &state); err != nil {
+		return nil, err
+	}
+
+	// node returns the id and the name of the node with the given id.
+	node := func(id *string) common.MapStr {
+		n := common.MapStr{"id": *id}
+		if info, found := state.Nodes[*id]; found {
+			n["name"] = info.Name
+		}
+		return n
+	}
+
+	var events []common.MapStr
+	for _, index := range state.RoutingTable.Indices {
+		for _, copies := range index.Shards {
+			for _, shard := range copies {
+				event := common.MapStr{
+					"state":   shard.State,
+					"primary": shard.Primary,
+					"number":  shard.Shard,
+					"index":   shard.Index,
+				}
+				if shard.Node != nil {
+					event["node"] = node(shard.Node)
+				}
+				if shard.RelocatingNode != nil {
+					event["relocating_node"] = node(shard.RelocatingNode)
+				}
+				if shard.UnassignedInfo != nil {
+					event["unassigned"] = common.MapStr{
+						"reason": shard.UnassignedInfo.Reason,
+					}
+				}
+
+				event[mb.ModuleDataKey] = common.MapStr{
+					"cluster": common.MapStr{
+						"name": state.ClusterName,
+					},
+				}
+				events = append(events, event)
+			}
+		}
+	}
+
+	return events, nil
+}
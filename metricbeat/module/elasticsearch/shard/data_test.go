@@ -0,0 +1,60 @@
+// +build !integration
+
+package shard
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/elastic/beats/libbeat/common"
+)
+
+func TestStats(t *testing.T) {
+	files, err := filepath.Glob("./_meta/test/cluster_state.*.json")
+	assert.NoError(t, err)
+
+	for _, f := range files {
+		content, err := ioutil.ReadFile(f)
+		assert.NoError(t, err)
+
+		events, err := eventsMapping(content)
+		assert.NoError(t, err)
+		assert.NotEmpty(t, events)
+	}
+}
+
+func TestEventsMapping(t *testing.T) {
+	content, err := ioutil.ReadFile("./_meta/test/cluster_state.563.json")
+	assert.NoError(t, err)
+
+	events, err := eventsMapping(content)
+	assert.NoError(t, err)
+	if !assert.Len(t, events, 3) {
+		return
+	}
+
+	states := map[string]common.MapStr{}
+	for _, event := range events {
+		states[event["state"].(string)] = event
+	}
+
+	started := states["STARTED"]
+	assert.Equal(t, true, started["primary"])
+	assert.Equal(t, 0, started["number"])
+	assert.Equal(t, "metricbeat-2017.10.18", started["index"])
+	assert.Equal(t, common.MapStr{"id": "VKaOTz9cTTiq8HYbXQa3lw", "name": "es-node-1"}, started["node"])
+	assert.Nil(t, started["relocating_node"])
+
+	unassigned := states["UNASSIGNED"]
+	assert.Nil(t, unassigned["node"])
+	assert.Equal(t, common.MapStr{"reason": "INDEX_CREATED"}, unassigned["unassigned"])
+
+	relocating := states["RELOCATING"]
+	assert.Equal(t, common.MapStr{"id": "jbqVLK6XTiW4m0SnYT6F1Q", "name": "es-node-2"}, relocating["relocating_node"])
+
+	clusterName, _ := relocating.GetValue("_module.cluster.name")
+	assert.Equal(t, "elasticsearch", clusterName)
+}
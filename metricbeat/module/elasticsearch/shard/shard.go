@@ -0,0 +1,76 @@
+package shard
+
+import (
+	"github.com/elastic/beats/libbeat/common"
+	"github.com/elastic/beats/libbeat/common/cfgwarn"
+	"github.com/elastic/beats/libbeat/logp"
+	"github.com/elastic/beats/metricbeat/helper"
+	"github.com/elastic/beats/metricbeat/mb"
+	"github.com/elastic/beats/metricbeat/mb/parse"
+	"github.com/elastic/beats/metricbeat/module/elasticsearch"
+)
+
+// init registers the MetricSet with the central registry.
+// The New method will be called after the setup of the module and before starting to fetch data
+func init() {
+	if err := mb.Registry.AddMetricSet("elasticsearch", "shard", New, hostParser); err != nil {
+		panic(err)
+	}
+}
+
+const defaultPath = "_cluster/state/nodes,routing_table"
+
+var (
+	debugf = logp.MakeDebug("elasticsearch.shard")
+
+	hostParser = parse.URLHostParserBuilder{
+		DefaultScheme: "http",
+		PathConfigKey: "path",
+		// Only the nodes and the routing table are needed from the cluster state
+		DefaultPath: defaultPath,
+	}.Build()
+)
+
+// MetricSet type defines all fields of the MetricSet
+type MetricSet struct {
+	mb.BaseMetricSet
+	http   *helper.HTTP
+	master *elasticsearch.MasterChecker
+}
+
+// New create a new instance of the MetricSet
+func New(base mb.BaseMetricSet) (mb.MetricSet, error) {
+	cfgwarn.Experimental("The elasticsearch shard metricset is experimental")
+
+	http := helper.NewHTTP(base)
+	master, err := elasticsearch.NewMasterChecker(http, base.HostData().SanitizedURI, defaultPath)
+	if err != nil {
+		return nil, err
+	}
+
+	return &MetricSet{
+		BaseMetricSet: base,
+		http:          http,
+		master:        master,
+	}, nil
+}
+
+// Fetch fetches the routing state of all the shards of the cluster. Only the
+// elected master reports them, so they are reported once per cluster.
+func (m *MetricSet) Fetch() ([]common.MapStr, error) {
+	isMaster, err := m.master.IsMaster()
+	if err != nil {
+		return nil, err
+	}
+	if !isMaster {
+		debugf("%s is not the elected master, skipping shards", m.Host())
+		return nil, nil
+	}
+
+	content, err := m.http.FetchContent()
+	if err != nil {
+		return nil, err
+	}
+
+	return eventsMapping(content)
+}
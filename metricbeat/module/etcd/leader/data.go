@@ -0,0 +1,53 @@
+package leader
+
+import (
+	"encoding/json"
+
+	"github.com/elastic/beats/libbeat/common"
+	s "github.com/elastic/beats/libbeat/common/schema"
+	c "github.com/elastic/beats/libbeat/common/schema/mapstriface"
+)
+
+type leaderStats struct {
+	Leader    string                            `json:"leader"`
+	Followers map[string]map[string]interface{} `json:"followers"`
+}
+
+var followerSchema = s.Schema{
+	"latency": c.Dict("latency", s.Schema{
+		"current":            s.Object{"ms": c.Float("current")},
+		"average":            s.Object{"ms": c.Float("average")},
+		"standard_deviation": s.Object{"ms": c.Float("standardDeviation")},
+		"minimum":            s.Object{"ms": c.Float("minimum")},
+		"maximum":            s.Object{"ms": c.Float("maximum")},
+	}),
+	"counts": c.Dict("counts", s.Schema{
+		"fail":    c.Int("fail"),
+		"success": c.Int("success"),
+	}),
+}
+
+func eventsMapping(content []byte) ([]common.MapStr, error) {
+	var stats leaderStats
+	if err := json.Unmarshal(content, &stats); err != nil {
+		return nil, err
+	}
+
+	events := []common.MapStr{}
+	errors := s.NewErrors()
+
+	for id, follower := range stats.Followers {
+		event, errs := followerSchema.Apply(follower)
+		event["id"] = id
+		events = append(events, common.MapStr{
+			"id":       stats.Leader,
+			"follower": event,
+		})
+		errors.AddErrors(errs)
+	}
+
+	if errors.HasRequiredErrors() {
+		return events, errors
+	}
+	return events, nil
+}
@@ -0,0 +1,83 @@
+package leader
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/elastic/beats/libbeat/common"
+	"github.com/elastic/beats/libbeat/common/cfgwarn"
+	"github.com/elastic/beats/libbeat/logp"
+	"github.com/elastic/beats/metricbeat/helper"
+	"github.com/elastic/beats/metricbeat/mb"
+	"github.com/elastic/beats/metricbeat/mb/parse"
+)
+
+const (
+	defaultScheme = "http"
+	defaultPath   = "/v2/stats/leader"
+)
+
+var (
+	debugf = logp.MakeDebug("etcd-leader")
+
+	hostParser = parse.URLHostParserBuilder{
+		DefaultScheme: defaultScheme,
+		DefaultPath:   defaultPath,
+	}.Build()
+)
+
+func init() {
+	if err := mb.Registry.AddMetricSet("etcd", "leader", New, hostParser); err != nil {
+		panic(err)
+	}
+}
+
+// MetricSet for fetching the statistics of the followers of an etcd leader.
+type MetricSet struct {
+	mb.BaseMetricSet
+	http *helper.HTTP
+}
+
+// New creates a new instance of the leader MetricSet.
+func New(base mb.BaseMetricSet) (mb.MetricSet, error) {
+	cfgwarn.Experimental("The etcd leader metricset is experimental")
+
+	http := helper.NewHTTP(base)
+	if http == nil {
+		return nil, errors.New("error creating HTTP client, check the ssl settings")
+	}
+
+	return &MetricSet{
+		BaseMetricSet: base,
+		http:          http,
+	}, nil
+}
+
+// Fetch returns an event for each follower of the member. Only the leader of
+// the cluster reports these statistics, no events are returned by the other
+// members.
+func (m *MetricSet) Fetch() ([]common.MapStr, error) {
+	resp, err := m.http.FetchResponse()
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusForbidden:
+		debugf("%s is not the leader of the cluster", m.Host())
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("HTTP error %d in %s: %s", resp.StatusCode, m.Name(), resp.Status)
+	}
+
+	content, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	return eventsMapping(content)
+}
@@ -0,0 +1,36 @@
+// +build integration
+
+package leader
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/elastic/beats/libbeat/tests/compose"
+	mbtest "github.com/elastic/beats/metricbeat/mb/testing"
+	"github.com/elastic/beats/metricbeat/module/etcd"
+)
+
+func TestFetch(t *testing.T) {
+	compose.EnsureUp(t, "etcd")
+
+	f := mbtest.NewEventsFetcher(t, getConfig())
+	events, err := f.Fetch()
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+
+	t.Logf("%s/%s events: %+v", f.Module().Name(), f.Name(), events)
+
+	// The test server is a single member cluster, so it has no followers.
+	assert.Empty(t, events)
+}
+
+func getConfig() map[string]interface{} {
+	return map[string]interface{}{
+		"module":     "etcd",
+		"metricsets": []string{"leader"},
+		"hosts":      []string{etcd.GetEtcdEnvHost() + ":" + etcd.GetEtcdEnvPort()},
+	}
+}
@@ -0,0 +1,85 @@
+// +build !integration
+
+package leader
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/elastic/beats/libbeat/common"
+	mbtest "github.com/elastic/beats/metricbeat/mb/testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFetchEventContents(t *testing.T) {
+	response, err := ioutil.ReadFile("../_meta/testdata/leader.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v2/stats/leader", r.URL.Path)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(200)
+		w.Write(response)
+	}))
+	defer server.Close()
+
+	f := mbtest.NewEventsFetcher(t, getConfig(server.URL))
+	events, err := f.Fetch()
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	if !assert.Len(t, events, 2) {
+		t.FailNow()
+	}
+
+	followers := map[string]common.MapStr{}
+	for _, event := range events {
+		assert.Equal(t, "8e9e05c52164694d", event["id"])
+		follower := event["follower"].(common.MapStr)
+		followers[follower["id"].(string)] = follower
+	}
+
+	follower := followers["fd422379fda50e48"]
+	if assert.NotNil(t, follower) {
+		t.Logf("%s/%s event: %+v", f.Module().Name(), f.Name(), follower.StringToPrint())
+
+		latency := follower["latency"].(common.MapStr)
+		assert.Equal(t, 0.00093, latency["current"].(common.MapStr)["ms"])
+		assert.Equal(t, 0.0021867916666666676, latency["average"].(common.MapStr)["ms"])
+		assert.Equal(t, 0.001953006290419474, latency["standard_deviation"].(common.MapStr)["ms"])
+		assert.Equal(t, 0.000669, latency["minimum"].(common.MapStr)["ms"])
+		assert.Equal(t, 0.016236, latency["maximum"].(common.MapStr)["ms"])
+
+		counts := follower["counts"].(common.MapStr)
+		assert.EqualValues(t, 2, counts["fail"])
+		assert.EqualValues(t, 264, counts["success"])
+	}
+	assert.Contains(t, followers, "91bc3c398fb3c146")
+}
+
+func TestFetchNotLeader(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(403)
+		w.Write([]byte(`{"message":"not current leader"}`))
+	}))
+	defer server.Close()
+
+	f := mbtest.NewEventsFetcher(t, getConfig(server.URL))
+	events, err := f.Fetch()
+	assert.NoError(t, err)
+	assert.Empty(t, events)
+}
+
+func getConfig(host string) map[string]interface{} {
+	return map[string]interface{}{
+		"module":     "etcd",
+		"metricsets": []string{"leader"},
+		"hosts":      []string{host},
+	}
+}
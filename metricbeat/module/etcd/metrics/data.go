@@ -0,0 +1,80 @@
+package metrics
+
+import (
+	"math"
+
+	"github.com/elastic/beats/libbeat/common"
+
+	dto "github.com/prometheus/client_model/go"
+)
+
+// valueMetrics are the counters and gauges reported, by the field they are
+// reported in. Values of metrics with several label sets are added up.
+var valueMetrics = map[string]string{
+	"etcd_server_has_leader":                "server.has_leader",
+	"etcd_server_leader_changes_seen_total": "server.leader_changes.count",
+	"etcd_server_proposals_committed_total": "server.proposals.committed.count",
+	"etcd_server_proposals_applied_total":   "server.proposals.applied.count",
+	"etcd_server_proposals_pending":         "server.proposals.pending.count",
+	"etcd_server_proposals_failed_total":    "server.proposals.failed.count",
+
+	// Metrics of the storage were renamed in etcd 3.3.
+	"etcd_debugging_mvcc_db_total_size_in_bytes": "mvcc.db_total_size.bytes",
+	"etcd_mvcc_db_total_size_in_bytes":           "mvcc.db_total_size.bytes",
+	"etcd_debugging_mvcc_keys_total":             "mvcc.keys.count",
+	"etcd_debugging_mvcc_watcher_total":          "mvcc.watchers.count",
+
+	"etcd_network_client_grpc_received_bytes_total": "network.client_grpc.received.bytes",
+	"etcd_network_client_grpc_sent_bytes_total":     "network.client_grpc.sent.bytes",
+	"etcd_network_peer_received_bytes_total":        "network.peer.received.bytes",
+	"etcd_network_peer_sent_bytes_total":            "network.peer.sent.bytes",
+}
+
+// histogramMetrics are the histograms reported, by the field their count and
+// sum are reported in.
+var histogramMetrics = map[string]string{
+	"etcd_disk_wal_fsync_duration_seconds":      "disk.wal_fsync_duration",
+	"etcd_disk_backend_commit_duration_seconds": "disk.backend_commit_duration",
+}
+
+func eventMapping(families []*dto.MetricFamily) common.MapStr {
+	event := common.MapStr{}
+
+	for _, family := range families {
+		name := family.GetName()
+
+		if field, found := valueMetrics[name]; found {
+			var total float64
+			for _, metric := range family.GetMetric() {
+				total += value(family.GetType(), metric)
+			}
+			event.Put(field, int64(math.Floor(total)))
+			continue
+		}
+
+		if field, found := histogramMetrics[name]; found {
+			var count uint64
+			var sum float64
+			for _, metric := range family.GetMetric() {
+				count += metric.GetHistogram().GetSampleCount()
+				sum += metric.GetHistogram().GetSampleSum()
+			}
+			event.Put(field+".count", int64(count))
+			event.Put(field+".sum.ms", sum*1000)
+		}
+	}
+
+	return event
+}
+
+// value returns the value of a counter, gauge or untyped metric.
+func value(metricType dto.MetricType, metric *dto.Metric) float64 {
+	switch metricType {
+	case dto.MetricType_COUNTER:
+		return metric.GetCounter().GetValue()
+	case dto.MetricType_GAUGE:
+		return metric.GetGauge().GetValue()
+	default:
+		return metric.GetUntyped().GetValue()
+	}
+}
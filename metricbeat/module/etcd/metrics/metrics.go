@@ -0,0 +1,60 @@
+package metrics
+
+import (
+	"errors"
+
+	"github.com/elastic/beats/libbeat/common"
+	"github.com/elastic/beats/libbeat/common/cfgwarn"
+	"github.com/elastic/beats/metricbeat/helper"
+	"github.com/elastic/beats/metricbeat/mb"
+	"github.com/elastic/beats/metricbeat/mb/parse"
+)
+
+const (
+	defaultScheme = "http"
+	defaultPath   = "/metrics"
+)
+
+var (
+	hostParser = parse.URLHostParserBuilder{
+		DefaultScheme: defaultScheme,
+		DefaultPath:   defaultPath,
+	}.Build()
+)
+
+func init() {
+	if err := mb.Registry.AddMetricSet("etcd", "metrics", New, hostParser); err != nil {
+		panic(err)
+	}
+}
+
+// MetricSet for fetching the Prometheus metrics of etcd v3.
+type MetricSet struct {
+	mb.BaseMetricSet
+	prometheus *helper.Prometheus
+}
+
+// New creates a new instance of the metrics MetricSet.
+func New(base mb.BaseMetricSet) (mb.MetricSet, error) {
+	cfgwarn.Experimental("The etcd metrics metricset is experimental")
+
+	prometheus := helper.NewPrometheusClient(base)
+	if prometheus == nil {
+		return nil, errors.New("error creating HTTP client, check the ssl settings")
+	}
+
+	return &MetricSet{
+		BaseMetricSet: base,
+		prometheus:    prometheus,
+	}, nil
+}
+
+// Fetch returns an event with the metrics of the member.
+func (m *MetricSet) Fetch() (common.MapStr, error) {
+	families, err := m.prometheus.GetFamilies()
+	if err != nil {
+		return nil, err
+	}
+
+	return eventMapping(families), nil
+}
@@ -0,0 +1,50 @@
+// +build integration
+
+package metrics
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/elastic/beats/libbeat/tests/compose"
+	mbtest "github.com/elastic/beats/metricbeat/mb/testing"
+	"github.com/elastic/beats/metricbeat/module/etcd"
+)
+
+func TestFetch(t *testing.T) {
+	compose.EnsureUp(t, "etcd")
+
+	f := mbtest.NewEventFetcher(t, getConfig())
+	event, err := f.Fetch()
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+
+	t.Logf("%s/%s event: %+v", f.Module().Name(), f.Name(), event)
+
+	hasLeader, err := event.GetValue("server.has_leader")
+	if assert.NoError(t, err) {
+		assert.Equal(t, int64(1), hasLeader)
+	}
+	assert.Contains(t, event, "mvcc")
+}
+
+func TestData(t *testing.T) {
+	compose.EnsureUp(t, "etcd")
+
+	f := mbtest.NewEventFetcher(t, getConfig())
+
+	err := mbtest.WriteEvent(f, t)
+	if err != nil {
+		t.Fatal("write", err)
+	}
+}
+
+func getConfig() map[string]interface{} {
+	return map[string]interface{}{
+		"module":     "etcd",
+		"metricsets": []string{"metrics"},
+		"hosts":      []string{etcd.GetEtcdEnvHost() + ":" + etcd.GetEtcdEnvPort()},
+	}
+}
@@ -0,0 +1,75 @@
+// +build !integration
+
+package metrics
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	mbtest "github.com/elastic/beats/metricbeat/mb/testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFetchEventContents(t *testing.T) {
+	response, err := ioutil.ReadFile("../_meta/testdata/metrics")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/metrics", r.URL.Path)
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+		w.WriteHeader(200)
+		w.Write(response)
+	}))
+	defer server.Close()
+
+	config := map[string]interface{}{
+		"module":     "etcd",
+		"metricsets": []string{"metrics"},
+		"hosts":      []string{server.URL},
+	}
+
+	f := mbtest.NewEventFetcher(t, config)
+	event, err := f.Fetch()
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+
+	t.Logf("%s/%s event: %+v", f.Module().Name(), f.Name(), event.StringToPrint())
+
+	expected := map[string]interface{}{
+		"server.has_leader":                  int64(1),
+		"server.leader_changes.count":        int64(1),
+		"server.proposals.committed.count":   int64(2073),
+		"server.proposals.applied.count":     int64(2073),
+		"server.proposals.pending.count":     int64(0),
+		"server.proposals.failed.count":      int64(0),
+		"mvcc.db_total_size.bytes":           int64(98304),
+		"mvcc.keys.count":                    int64(17),
+		"mvcc.watchers.count":                int64(2),
+		"network.client_grpc.received.bytes": int64(4183),
+		"network.client_grpc.sent.bytes":     int64(10732),
+		"network.peer.received.bytes":        int64(121440),
+		"network.peer.sent.bytes":            int64(1023972),
+		"disk.wal_fsync_duration.count":      int64(105),
+		"disk.backend_commit_duration.count": int64(67),
+	}
+	for field, value := range expected {
+		actual, err := event.GetValue(field)
+		if assert.NoError(t, err, field) {
+			assert.Equal(t, value, actual, field)
+		}
+	}
+
+	sum, err := event.GetValue("disk.wal_fsync_duration.sum.ms")
+	if assert.NoError(t, err) {
+		assert.InDelta(t, 286.335, sum, 0.001)
+	}
+
+	// Metrics not related to etcd are not reported.
+	assert.Len(t, event, 4)
+}
@@ -0,0 +1,100 @@
+package self
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/elastic/beats/libbeat/common"
+	s "github.com/elastic/beats/libbeat/common/schema"
+	c "github.com/elastic/beats/libbeat/common/schema/mapstriface"
+)
+
+var schema = s.Schema{
+	"id":         c.Str("id"),
+	"name":       c.Str("name"),
+	"state":      c.Str("state"),
+	"start_time": timestamp("startTime"),
+	"leader_info": c.Dict("leaderInfo", s.Schema{
+		"leader":     c.Str("leader"),
+		"start_time": timestamp("startTime", s.Optional),
+		"uptime": s.Object{
+			"sec": duration("uptime", s.Optional),
+		},
+	}),
+	"recv": s.Object{
+		"append_request": s.Object{
+			"count": c.Int("recvAppendRequestCnt"),
+		},
+		"pkg_rate":       c.Float("recvPkgRate", s.Optional),
+		"bandwidth_rate": c.Float("recvBandwidthRate", s.Optional),
+	},
+	"send": s.Object{
+		"append_request": s.Object{
+			"count": c.Int("sendAppendRequestCnt"),
+		},
+		"pkg_rate":       c.Float("sendPkgRate", s.Optional),
+		"bandwidth_rate": c.Float("sendBandwidthRate", s.Optional),
+	},
+}
+
+func eventMapping(content []byte) (common.MapStr, error) {
+	var data map[string]interface{}
+	if err := json.Unmarshal(content, &data); err != nil {
+		return nil, err
+	}
+
+	event, errs := schema.Apply(data)
+	if errs.HasRequiredErrors() {
+		return event, errs
+	}
+	return event, nil
+}
+
+// timestamp creates a Conv object for converting RFC 3339 timestamps.
+func timestamp(key string, opts ...s.SchemaOption) s.Conv {
+	return s.SetOptions(s.Conv{Key: key, Func: toTime}, opts)
+}
+
+func toTime(key string, data map[string]interface{}) (interface{}, error) {
+	value, err := toString(key, data)
+	if err != nil {
+		return nil, err
+	}
+
+	t, err := time.Parse(time.RFC3339Nano, value)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing time of key %s: %v", key, err)
+	}
+	return common.Time(t.UTC()), nil
+}
+
+// duration creates a Conv object for converting durations to seconds.
+func duration(key string, opts ...s.SchemaOption) s.Conv {
+	return s.SetOptions(s.Conv{Key: key, Func: toSeconds}, opts)
+}
+
+func toSeconds(key string, data map[string]interface{}) (interface{}, error) {
+	value, err := toString(key, data)
+	if err != nil {
+		return nil, err
+	}
+
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing duration of key %s: %v", key, err)
+	}
+	return int64(d.Seconds()), nil
+}
+
+func toString(key string, data map[string]interface{}) (string, error) {
+	value, found := data[key]
+	if !found {
+		return "", fmt.Errorf("Key %s not found", key)
+	}
+	str, ok := value.(string)
+	if !ok {
+		return "", fmt.Errorf("Expected string, found %T", value)
+	}
+	return str, nil
+}
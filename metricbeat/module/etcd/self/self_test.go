@@ -0,0 +1,86 @@
+// +build !integration
+
+package self
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/elastic/beats/libbeat/common"
+	mbtest "github.com/elastic/beats/metricbeat/mb/testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFetchLeader(t *testing.T) {
+	event := fetch(t, "../_meta/testdata/self.json")
+
+	assert.Equal(t, "8e9e05c52164694d", event["id"])
+	assert.Equal(t, "infra1", event["name"])
+	assert.Equal(t, "StateLeader", event["state"])
+	assert.Equal(t, common.Time(time.Date(2017, 11, 3, 9, 55, 44, 906063613, time.UTC)), event["start_time"])
+
+	leaderInfo := event["leader_info"].(common.MapStr)
+	assert.Equal(t, "8e9e05c52164694d", leaderInfo["leader"])
+	assert.Equal(t, common.Time(time.Date(2017, 11, 3, 9, 55, 45, 107069457, time.UTC)), leaderInfo["start_time"])
+	assert.EqualValues(t, 3922, leaderInfo["uptime"].(common.MapStr)["sec"])
+
+	send := event["send"].(common.MapStr)
+	assert.EqualValues(t, 528, send["append_request"].(common.MapStr)["count"])
+	assert.Equal(t, 9.984376560223197, send["pkg_rate"])
+	assert.Equal(t, 709.9092253118708, send["bandwidth_rate"])
+
+	recv := event["recv"].(common.MapStr)
+	assert.EqualValues(t, 0, recv["append_request"].(common.MapStr)["count"])
+	assert.NotContains(t, recv, "pkg_rate")
+	assert.NotContains(t, recv, "bandwidth_rate")
+}
+
+func TestFetchFollower(t *testing.T) {
+	event := fetch(t, "../_meta/testdata/self_follower.json")
+
+	assert.Equal(t, "91bc3c398fb3c146", event["id"])
+	assert.Equal(t, "StateFollower", event["state"])
+
+	recv := event["recv"].(common.MapStr)
+	assert.EqualValues(t, 264, recv["append_request"].(common.MapStr)["count"])
+	assert.Equal(t, 4.992175062378367, recv["pkg_rate"])
+	assert.Equal(t, 354.95486193509193, recv["bandwidth_rate"])
+
+	send := event["send"].(common.MapStr)
+	assert.EqualValues(t, 0, send["append_request"].(common.MapStr)["count"])
+	assert.NotContains(t, send, "pkg_rate")
+}
+
+func fetch(t *testing.T, path string) common.MapStr {
+	response, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v2/stats/self", r.URL.Path)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(200)
+		w.Write(response)
+	}))
+	defer server.Close()
+
+	config := map[string]interface{}{
+		"module":     "etcd",
+		"metricsets": []string{"self"},
+		"hosts":      []string{server.URL},
+	}
+
+	f := mbtest.NewEventFetcher(t, config)
+	event, err := f.Fetch()
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+
+	t.Logf("%s/%s event: %+v", f.Module().Name(), f.Name(), event.StringToPrint())
+	return event
+}
@@ -0,0 +1,48 @@
+// +build integration
+
+package store
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/elastic/beats/libbeat/tests/compose"
+	mbtest "github.com/elastic/beats/metricbeat/mb/testing"
+	"github.com/elastic/beats/metricbeat/module/etcd"
+)
+
+func TestFetch(t *testing.T) {
+	compose.EnsureUp(t, "etcd")
+
+	f := mbtest.NewEventFetcher(t, getConfig())
+	event, err := f.Fetch()
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+
+	t.Logf("%s/%s event: %+v", f.Module().Name(), f.Name(), event)
+
+	assert.Contains(t, event, "gets")
+	assert.Contains(t, event, "sets")
+	assert.Contains(t, event, "watchers")
+}
+
+func TestData(t *testing.T) {
+	compose.EnsureUp(t, "etcd")
+
+	f := mbtest.NewEventFetcher(t, getConfig())
+
+	err := mbtest.WriteEvent(f, t)
+	if err != nil {
+		t.Fatal("write", err)
+	}
+}
+
+func getConfig() map[string]interface{} {
+	return map[string]interface{}{
+		"module":     "etcd",
+		"metricsets": []string{"store"},
+		"hosts":      []string{etcd.GetEtcdEnvHost() + ":" + etcd.GetEtcdEnvPort()},
+	}
+}
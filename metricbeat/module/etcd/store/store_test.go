@@ -0,0 +1,117 @@
+// +build !integration
+
+package store
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/elastic/beats/libbeat/common"
+	mbtest "github.com/elastic/beats/metricbeat/mb/testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const certsPath = "../_meta/testdata/certs/"
+
+func TestFetchEventContents(t *testing.T) {
+	server := httptest.NewServer(handler(t))
+	defer server.Close()
+
+	f := mbtest.NewEventFetcher(t, getConfig(server.URL))
+	event, err := f.Fetch()
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+
+	t.Logf("%s/%s event: %+v", f.Module().Name(), f.Name(), event.StringToPrint())
+
+	assert.Equal(t, common.MapStr{
+		"gets":               common.MapStr{"success": int64(12), "fail": int64(4)},
+		"sets":               common.MapStr{"success": int64(7), "fail": int64(0)},
+		"delete":             common.MapStr{"success": int64(1), "fail": int64(0)},
+		"update":             common.MapStr{"success": int64(2), "fail": int64(1)},
+		"create":             common.MapStr{"success": int64(20), "fail": int64(0)},
+		"compare_and_swap":   common.MapStr{"success": int64(3), "fail": int64(1)},
+		"compare_and_delete": common.MapStr{"success": int64(0), "fail": int64(0)},
+		"expire":             common.MapStr{"count": int64(5)},
+		"watchers":           int64(2),
+	}, event)
+}
+
+func TestFetchTLSClientAuth(t *testing.T) {
+	server := httptest.NewUnstartedServer(handler(t))
+	server.TLS = serverTLSConfig(t)
+	server.StartTLS()
+	defer server.Close()
+
+	config := getConfig(server.URL)
+	config["ssl"] = map[string]interface{}{
+		"certificate_authorities": []string{certsPath + "ca.pem"},
+		"certificate":             certsPath + "client.pem",
+		"key":                     certsPath + "client-key.pem",
+	}
+
+	f := mbtest.NewEventFetcher(t, config)
+	event, err := f.Fetch()
+	if assert.NoError(t, err) {
+		assert.Equal(t, int64(2), event["watchers"])
+	}
+
+	// Connections without a client certificate are rejected.
+	config["ssl"] = map[string]interface{}{
+		"certificate_authorities": []string{certsPath + "ca.pem"},
+	}
+
+	f = mbtest.NewEventFetcher(t, config)
+	_, err = f.Fetch()
+	assert.Error(t, err)
+}
+
+func handler(t *testing.T) http.Handler {
+	response, err := ioutil.ReadFile("../_meta/testdata/store.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v2/stats/store", r.URL.Path)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(200)
+		w.Write(response)
+	})
+}
+
+// serverTLSConfig returns a TLS configuration that requires clients to
+// authenticate with a certificate signed by the test CA.
+func serverTLSConfig(t *testing.T) *tls.Config {
+	cert, err := tls.LoadX509KeyPair(certsPath+"server.pem", certsPath+"server-key.pem")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ca, err := ioutil.ReadFile(certsPath + "ca.pem")
+	if err != nil {
+		t.Fatal(err)
+	}
+	pool := x509.NewCertPool()
+	pool.AppendCertsFromPEM(ca)
+
+	return &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		ClientCAs:    pool,
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+	}
+}
+
+func getConfig(host string) map[string]interface{} {
+	return map[string]interface{}{
+		"module":     "etcd",
+		"metricsets": []string{"store"},
+		"hosts":      []string{host},
+	}
+}
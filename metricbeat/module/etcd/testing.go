@@ -0,0 +1,31 @@
+package etcd
+
+import (
+	"os"
+)
+
+// Helper functions for testing used in the etcd MetricSets.
+
+// GetEtcdEnvHost returns the hostname of the etcd server to use for testing.
+// It reads the value from the ETCD_HOST environment variable and returns
+// localhost if it is not set.
+func GetEtcdEnvHost() string {
+	host := os.Getenv("ETCD_HOST")
+
+	if len(host) == 0 {
+		host = "localhost"
+	}
+	return host
+}
+
+// GetEtcdEnvPort returns the port of the etcd server to use for testing.
+// It reads the value from the ETCD_PORT environment variable and returns
+// 2379 if it is not set.
+func GetEtcdEnvPort() string {
+	port := os.Getenv("ETCD_PORT")
+
+	if len(port) == 0 {
+		port = "2379"
+	}
+	return port
+}
@@ -10,6 +10,8 @@ import (
 	"net"
 	"strings"
 
+	"github.com/elastic/beats/metricbeat/helper"
+	"github.com/elastic/beats/metricbeat/mb"
 	"github.com/elastic/beats/metricbeat/mb/parse"
 
 	"github.com/gocarina/gocsv"
@@ -138,33 +140,56 @@ type Info struct {
 
 // Client is an instance of the HAProxy client
 type Client struct {
-	Address     string
-	ProtoScheme string
+	proto clientProto
+}
+
+// clientProto is the protocol used to get the stats from HAProxy, the stats
+// socket or the HTTP stats page.
+type clientProto interface {
+	// Stat returns the stats in CSV format.
+	Stat() (*bytes.Buffer, error)
+
+	// Info returns the process information in the format of the 'show info'
+	// command.
+	Info() (*bytes.Buffer, error)
 }
 
 // NewHaproxyClient returns a new instance of HaproxyClient
-func NewHaproxyClient(address string) (*Client, error) {
+func NewHaproxyClient(address string, base mb.BaseMetricSet) (*Client, error) {
 	parts := strings.Split(address, "://")
 	if len(parts) != 2 {
 		return nil, errors.New("must have protocol scheme and address")
 	}
 
-	if parts[0] != "tcp" && parts[0] != "unix" {
+	switch parts[0] {
+	case "tcp", "unix":
+		return &Client{&socketProto{
+			Address:     parts[1],
+			ProtoScheme: parts[0],
+		}}, nil
+	case "http", "https":
+		http := helper.NewHTTP(base)
+		if http == nil {
+			return nil, errors.New("failed creating HTTP client")
+		}
+		return &Client{&httpProto{HTTP: http, uri: address}}, nil
+	default:
 		return nil, errors.New("invalid protocol scheme")
 	}
+}
 
-	return &Client{
-		Address:     parts[1],
-		ProtoScheme: parts[0],
-	}, nil
+// socketProto gets the stats from the stats socket
+type socketProto struct {
+	Address     string
+	ProtoScheme string
 }
 
 // Run sends a designated command to the haproxy stats socket
-func (c *Client) run(cmd string) (*bytes.Buffer, error) {
+func (p *socketProto) run(cmd string) (*bytes.Buffer, error) {
 	var conn net.Conn
 	response := bytes.NewBuffer(nil)
 
-	conn, err := net.Dial(c.ProtoScheme, c.Address)
+	conn, err := net.Dial(p.ProtoScheme, p.Address)
 	if err != nil {
 		return response, err
 	}
@@ -188,9 +213,17 @@ func (c *Client) run(cmd string) (*bytes.Buffer, error) {
 	return response, nil
 }
 
+func (p *socketProto) Stat() (*bytes.Buffer, error) {
+	return p.run("show stat")
+}
+
+func (p *socketProto) Info() (*bytes.Buffer, error) {
+	return p.run("show info")
+}
+
 // GetStat returns the result from the 'show stat' command
 func (c *Client) GetStat() ([]*Stat, error) {
-	runResult, err := c.run("show stat")
+	runResult, err := c.proto.Stat()
 	if err != nil {
 		return nil, err
 	}
@@ -207,9 +240,9 @@ func (c *Client) GetStat() ([]*Stat, error) {
 	return statRes, nil
 }
 
-// GetInfo returns the result from the 'show stat' command
+// GetInfo returns the result from the 'show info' command
 func (c *Client) GetInfo() (*Info, error) {
-	res, err := c.run("show info")
+	res, err := c.proto.Info()
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,139 @@
+package haproxy
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"html"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/elastic/beats/metricbeat/helper"
+)
+
+// httpProto gets the stats from the HTTP stats page
+type httpProto struct {
+	*helper.HTTP
+	uri string
+}
+
+// pageURI returns the URI of the stats page, without the CSV export option.
+func (p *httpProto) pageURI() string {
+	return strings.TrimSuffix(p.uri, ";csv")
+}
+
+func (p *httpProto) fetch(uri string) (*bytes.Buffer, error) {
+	p.SetURI(uri)
+	content, err := p.FetchContent()
+	if err != nil {
+		return nil, err
+	}
+	return bytes.NewBuffer(content), nil
+}
+
+// Stat returns the CSV export of the stats page, in the same format as the
+// output of the 'show stat' command.
+func (p *httpProto) Stat() (*bytes.Buffer, error) {
+	return p.fetch(p.pageURI() + ";csv")
+}
+
+// Info returns the general process information of the stats page. The page
+// only contains a subset of the information reported by the 'show info'
+// command.
+func (p *httpProto) Info() (*bytes.Buffer, error) {
+	page, err := p.fetch(p.pageURI())
+	if err != nil {
+		return nil, err
+	}
+	return parseInfoPage(page.String())
+}
+
+var (
+	htmlTags = regexp.MustCompile(`<[^>]*>`)
+
+	infoVersion = regexp.MustCompile(`HAProxy version ([^,\s]+), released (\S+)`)
+	infoPid     = regexp.MustCompile(`pid = \s*(\d+) \(process #(\d+), nbproc = (\d+)\)`)
+	infoUptime  = regexp.MustCompile(`uptime = \s*((\d+)d (\d+)h(\d+)m(\d+)s)`)
+	infoMemMax  = regexp.MustCompile(`memmax = (unlimited|\d+)`)
+	infoPipes   = regexp.MustCompile(`current pipes = (\d+)/(\d+)`)
+	infoTasks   = regexp.MustCompile(`Running tasks: (\d+)/(\d+)`)
+
+	// infoValues are the values reported as is, by their 'show info' name.
+	infoValues = []struct {
+		name string
+		re   *regexp.Regexp
+	}{
+		{"Ulimit-n", regexp.MustCompile(`ulimit-n = (\d+)`)},
+		{"Maxsock", regexp.MustCompile(`maxsock = \s*(\d+)`)},
+		{"Maxconn", regexp.MustCompile(`maxconn = \s*(\d+)`)},
+		{"Maxpipes", regexp.MustCompile(`maxpipes = \s*(\d+)`)},
+		{"CurrConns", regexp.MustCompile(`current conns = (\d+)`)},
+		{"ConnRate", regexp.MustCompile(`conn rate = (\d+)/sec`)},
+		{"Idle_pct", regexp.MustCompile(`idle = (\d+) %`)},
+	}
+)
+
+// parseInfoPage extracts the general process information from the HTML stats
+// page, and returns it in the format of the 'show info' command.
+func parseInfoPage(page string) (*bytes.Buffer, error) {
+	text := html.UnescapeString(htmlTags.ReplaceAllString(page, ""))
+
+	info := bytes.NewBuffer(nil)
+	add := func(name, value string) {
+		fmt.Fprintf(info, "%s: %s\n", name, value)
+	}
+
+	m := infoPid.FindStringSubmatch(text)
+	if m == nil {
+		return nil, errors.New("general process information not found in stats page")
+	}
+	add("Pid", m[1])
+	add("Process_num", m[2])
+	add("Nbproc", m[3])
+
+	if m := infoVersion.FindStringSubmatch(text); m != nil {
+		add("Name", "HAProxy")
+		add("Version", m[1])
+		add("Release_date", m[2])
+	}
+
+	if m := infoUptime.FindStringSubmatch(text); m != nil {
+		var sec int
+		for i, unit := range []int{24 * 3600, 3600, 60, 1} {
+			v, _ := strconv.Atoi(m[i+2])
+			sec += v * unit
+		}
+		add("Uptime", m[1])
+		add("Uptime_sec", strconv.Itoa(sec))
+	}
+
+	if m := infoMemMax.FindStringSubmatch(text); m != nil {
+		// 'show info' reports 0 when there is no limit.
+		if m[1] == "unlimited" {
+			add("Memmax_MB", "0")
+		} else {
+			add("Memmax_MB", m[1])
+		}
+	}
+
+	if m := infoPipes.FindStringSubmatch(text); m != nil {
+		used, _ := strconv.Atoi(m[1])
+		total, _ := strconv.Atoi(m[2])
+		add("PipesUsed", m[1])
+		add("PipesFree", strconv.Itoa(total-used))
+	}
+
+	if m := infoTasks.FindStringSubmatch(text); m != nil {
+		add("Run_queue", m[1])
+		add("Tasks", m[2])
+	}
+
+	for _, v := range infoValues {
+		if m := v.re.FindStringSubmatch(text); m != nil {
+			add(v.name, m[1])
+		}
+	}
+
+	return info, nil
+}
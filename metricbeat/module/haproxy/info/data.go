@@ -12,6 +12,7 @@ import (
 )
 
 var (
+	// schema maps the output of the 'show info' command of the stats socket.
 	schema = s.Schema{
 		"processes":   c.Int("Nbproc"),
 		"process_num": c.Int("ProcessNum"),
@@ -111,10 +112,53 @@ var (
 			"pct": c.Float("IdlePct"),
 		},
 	}
+
+	// httpSchema maps the subset of the information available in the HTTP
+	// stats page.
+	httpSchema = s.Schema{
+		"processes":   c.Int("Nbproc"),
+		"process_num": c.Int("ProcessNum"),
+		"pid":         c.Int("Pid"),
+		"ulimit_n":    c.Int("UlimitN"),
+		"tasks":       c.Int("Tasks"),
+		"run_queue":   c.Int("RunQueue"),
+
+		"uptime": s.Object{
+			"sec": c.Int("UptimeSec"),
+		},
+
+		"memory": s.Object{
+			"max": s.Object{
+				"bytes": c.Int("MemMax"),
+			},
+		},
+
+		"connection": s.Object{
+			"rate": s.Object{
+				"value": c.Int("ConnRate"),
+			},
+			"current": c.Int("CurrConns"),
+			"max":     c.Int("Maxconn"),
+		},
+
+		"sockets": s.Object{
+			"max": c.Int("Maxsock"),
+		},
+
+		"pipes": s.Object{
+			"used": c.Int("PipesUsed"),
+			"free": c.Int("PipesFree"),
+			"max":  c.Int("Maxpipes"),
+		},
+
+		"idle": s.Object{
+			"pct": c.Float("IdlePct"),
+		},
+	}
 )
 
-// Map data to MapStr
-func eventMapping(info *haproxy.Info) (common.MapStr, error) {
+// Map data to MapStr using the schema of the protocol used to fetch it.
+func eventMapping(info *haproxy.Info, schema s.Schema) (common.MapStr, error) {
 	// Full mapping from info
 
 	st := reflect.ValueOf(info).Elem()
@@ -124,6 +168,11 @@ func eventMapping(info *haproxy.Info) (common.MapStr, error) {
 	for i := 0; i < st.NumField(); i++ {
 		f := st.Field(i)
 
+		// Values missing from the response are empty, the schema reports them.
+		if f.Interface().(string) == "" {
+			continue
+		}
+
 		if typeOfT.Field(i).Name == "IdlePct" {
 			// Convert this value to a float between 0.0 and 1.0
 			fval, err := strconv.ParseFloat(f.Interface().(string), 64)
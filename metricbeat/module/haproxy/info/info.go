@@ -1,7 +1,10 @@
 package info
 
 import (
+	"net/url"
+
 	"github.com/elastic/beats/libbeat/common"
+	s "github.com/elastic/beats/libbeat/common/schema"
 	"github.com/elastic/beats/libbeat/logp"
 	"github.com/elastic/beats/metricbeat/mb"
 	"github.com/elastic/beats/metricbeat/module/haproxy"
@@ -27,26 +30,34 @@ func init() {
 // MetricSet for haproxy info.
 type MetricSet struct {
 	mb.BaseMetricSet
+	client *haproxy.Client
+	schema s.Schema
 }
 
 // New creates a haproxy info MetricSet.
 func New(base mb.BaseMetricSet) (mb.MetricSet, error) {
-	return &MetricSet{BaseMetricSet: base}, nil
-}
-
-// Fetch fetches info stats from the haproxy service.
-func (m *MetricSet) Fetch() (common.MapStr, error) {
-	// haproxy doesn't accept a username or password so ignore them if they
-	// are in the URL.
-	hapc, err := haproxy.NewHaproxyClient(m.HostData().SanitizedURI)
+	// The stats socket doesn't accept a username or password so ignore them
+	// if they are in the URL, they are only used for basic auth with HTTP.
+	client, err := haproxy.NewHaproxyClient(base.HostData().SanitizedURI, base)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed creating haproxy client")
 	}
 
-	res, err := hapc.GetInfo()
+	m := &MetricSet{BaseMetricSet: base, client: client, schema: schema}
+
+	// The HTTP stats page only contains a subset of the information.
+	if u, err := url.Parse(base.HostData().SanitizedURI); err == nil && (u.Scheme == "http" || u.Scheme == "https") {
+		m.schema = httpSchema
+	}
+	return m, nil
+}
+
+// Fetch fetches info stats from the haproxy service.
+func (m *MetricSet) Fetch() (common.MapStr, error) {
+	res, err := m.client.GetInfo()
 	if err != nil {
 		return nil, errors.Wrap(err, "failed fetching haproxy info")
 	}
 
-	return eventMapping(res)
+	return eventMapping(res, m.schema)
 }
@@ -0,0 +1,140 @@
+// +build !integration
+
+package info
+
+import (
+	"bufio"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/elastic/beats/libbeat/common"
+	mbtest "github.com/elastic/beats/metricbeat/mb/testing"
+)
+
+func readFixture(t *testing.T, name string) []byte {
+	content, err := ioutil.ReadFile(filepath.Join("..", "_meta", "testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return content
+}
+
+// startSocketServer starts a stats socket answering the given command.
+func startSocketServer(t *testing.T, command string, response []byte) net.Listener {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			cmd, err := bufio.NewReader(conn).ReadString('\n')
+			if err == nil && cmd == command+"\n" {
+				conn.Write(response)
+			} else {
+				conn.Write([]byte("Unknown command.\n"))
+			}
+			conn.Close()
+		}
+	}()
+	return l
+}
+
+// startHTTPServer starts a stats page protected with basic auth.
+func startHTTPServer(t *testing.T, page []byte) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, pass, ok := r.BasicAuth()
+		if !ok || user != "admin" || pass != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.URL.Path != "/haproxy" || r.URL.RawQuery != "stats" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html")
+		w.Write(page)
+	}))
+}
+
+func fetchEvent(t *testing.T, config map[string]interface{}) common.MapStr {
+	config["module"] = "haproxy"
+	config["metricsets"] = []string{"info"}
+
+	f := mbtest.NewEventFetcher(t, config)
+	event, err := f.Fetch()
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	return event
+}
+
+// The stats page only contains the general process information.
+var httpFields = []string{
+	"pid",
+	"processes",
+	"process_num",
+	"uptime.sec",
+	"memory.max.bytes",
+	"ulimit_n",
+	"sockets.max",
+	"connection.max",
+	"connection.current",
+	"connection.rate.value",
+	"pipes.used",
+	"pipes.free",
+	"pipes.max",
+	"tasks",
+	"run_queue",
+	"idle.pct",
+}
+
+func TestFetchSocketAndHTTP(t *testing.T) {
+	socket := startSocketServer(t, "show info", readFixture(t, "info.txt"))
+	defer socket.Close()
+
+	server := startHTTPServer(t, readFixture(t, "stats.html"))
+	defer server.Close()
+
+	socketEvent := fetchEvent(t, map[string]interface{}{
+		"hosts": []string{"tcp://" + socket.Addr().String()},
+	})
+	httpEvent := fetchEvent(t, map[string]interface{}{
+		"hosts":    []string{server.URL + "/haproxy?stats"},
+		"username": "admin",
+		"password": "secret",
+	})
+
+	flatHTTPEvent := httpEvent.Flatten()
+	assert.Len(t, flatHTTPEvent, len(httpFields))
+	for _, field := range httpFields {
+		expected, err := socketEvent.GetValue(field)
+		if !assert.NoError(t, err, field) {
+			continue
+		}
+		assert.Equal(t, expected, flatHTTPEvent[field], field)
+	}
+
+	assert.Equal(t, int64(7), httpEvent["pid"])
+	assert.Equal(t, int64(5403), flatHTTPEvent["uptime.sec"])
+	assert.Equal(t, int64(9), httpEvent["tasks"])
+	assert.Equal(t, int64(1), httpEvent["run_queue"])
+
+	// Only the stats socket reports the rest of the information.
+	flatSocketEvent := socketEvent.Flatten()
+	assert.True(t, len(flatSocketEvent) > len(flatHTTPEvent))
+	for _, field := range []string{"requests.total", "connection.ssl.current", "session.rate.value", "zlib_mem_usage.max"} {
+		assert.Contains(t, flatSocketEvent, field)
+		assert.NotContains(t, flatHTTPEvent, field)
+	}
+}
@@ -27,23 +27,24 @@ func init() {
 // MetricSet for haproxy stats.
 type MetricSet struct {
 	mb.BaseMetricSet
+	client *haproxy.Client
 }
 
 // New creates a new haproxy stat MetricSet.
 func New(base mb.BaseMetricSet) (mb.MetricSet, error) {
-	return &MetricSet{BaseMetricSet: base}, nil
-}
-
-// Fetch methods returns a list of stats metrics.
-func (m *MetricSet) Fetch() ([]common.MapStr, error) {
-	// haproxy doesn't accept a username or password so ignore them if they
-	// are in the URL.
-	hapc, err := haproxy.NewHaproxyClient(m.HostData().SanitizedURI)
+	// The stats socket doesn't accept a username or password so ignore them
+	// if they are in the URL, they are only used for basic auth with HTTP.
+	client, err := haproxy.NewHaproxyClient(base.HostData().SanitizedURI, base)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed creating haproxy client")
 	}
 
-	res, err := hapc.GetStat()
+	return &MetricSet{BaseMetricSet: base, client: client}, nil
+}
+
+// Fetch methods returns a list of stats metrics.
+func (m *MetricSet) Fetch() ([]common.MapStr, error) {
+	res, err := m.client.GetStat()
 	if err != nil {
 		return nil, errors.Wrap(err, "failed fetching haproxy stat")
 	}
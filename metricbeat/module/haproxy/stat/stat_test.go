@@ -0,0 +1,121 @@
+// +build !integration
+
+package stat
+
+import (
+	"bufio"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/elastic/beats/libbeat/common"
+	mbtest "github.com/elastic/beats/metricbeat/mb/testing"
+)
+
+func readFixture(t *testing.T, name string) []byte {
+	content, err := ioutil.ReadFile(filepath.Join("..", "_meta", "testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return content
+}
+
+// startSocketServer starts a stats socket answering the given command.
+func startSocketServer(t *testing.T, command string, response []byte) net.Listener {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			cmd, err := bufio.NewReader(conn).ReadString('\n')
+			if err == nil && cmd == command+"\n" {
+				conn.Write(response)
+			} else {
+				conn.Write([]byte("Unknown command.\n"))
+			}
+			conn.Close()
+		}
+	}()
+	return l
+}
+
+// startHTTPServer starts a stats page protected with basic auth.
+func startHTTPServer(t *testing.T, csv []byte) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, pass, ok := r.BasicAuth()
+		if !ok || user != "admin" || pass != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.URL.Path != "/haproxy" || r.URL.RawQuery != "stats;csv" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain")
+		w.Write(csv)
+	}))
+}
+
+func fetchEvents(t *testing.T, config map[string]interface{}) []common.MapStr {
+	config["module"] = "haproxy"
+	config["metricsets"] = []string{"stat"}
+
+	f := mbtest.NewEventsFetcher(t, config)
+	events, err := f.Fetch()
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	return events
+}
+
+func TestFetchSocketAndHTTP(t *testing.T) {
+	csv := readFixture(t, "stat.csv")
+
+	socket := startSocketServer(t, "show stat", csv)
+	defer socket.Close()
+
+	server := startHTTPServer(t, csv)
+	defer server.Close()
+
+	socketEvents := fetchEvents(t, map[string]interface{}{
+		"hosts": []string{"tcp://" + socket.Addr().String()},
+	})
+	httpEvents := fetchEvents(t, map[string]interface{}{
+		"hosts":    []string{server.URL + "/haproxy?stats"},
+		"username": "admin",
+		"password": "secret",
+	})
+
+	assert.Len(t, socketEvents, 5)
+	assert.Equal(t, socketEvents, httpEvents)
+
+	server1 := socketEvents[3]
+	assert.Equal(t, "log1", server1["service_name"])
+	assert.Equal(t, "DOWN", server1["status"])
+	assert.Equal(t, "http-webservices", server1["proxy"].(common.MapStr)["name"])
+	assert.Equal(t, int64(4821), server1["downtime"])
+}
+
+func TestFetchHTTPUnauthorized(t *testing.T) {
+	server := startHTTPServer(t, readFixture(t, "stat.csv"))
+	defer server.Close()
+
+	f := mbtest.NewEventsFetcher(t, map[string]interface{}{
+		"module":     "haproxy",
+		"metricsets": []string{"stat"},
+		"hosts":      []string{server.URL + "/haproxy?stats"},
+	})
+	_, err := f.Fetch()
+	assert.Error(t, err)
+}
@@ -137,17 +137,14 @@ func (b *Broker) GetTopicsMetadata(topics ...string) ([]*sarama.TopicMetadata, e
 	return r.Topics, nil
 }
 
-// PartitionOffset fetches the available offset from a partition.
+// PartitionOffset fetches the available offset from a partition. The offsets
+// are the ones available to consumers in the leader of the partition.
 func (b *Broker) PartitionOffset(
-	replicaID int32,
 	topic string,
 	partition int32,
 	time int64,
 ) (int64, error) {
 	req := &sarama.OffsetRequest{}
-	if replicaID != noID {
-		req.SetReplicaID(replicaID)
-	}
 	req.AddBlock(topic, partition, time, 1)
 	resp, err := b.broker.GetAvailableOffsets(req)
 	if err != nil {
@@ -162,6 +159,101 @@ func (b *Broker) PartitionOffset(
 	return block.Offsets[0], nil
 }
 
+// FetchNewestOffsets fetches the newest offsets of the given topics
+// partitions. Offsets can only be queried from the leader of a partition, so
+// the requests are sent to the leaders found in the most recent cluster
+// metadata. Partitions whose offset can't be fetched, e.g. while they have no
+// leader or after a leadership change, are missing from the result.
+func (b *Broker) FetchNewestOffsets(
+	partitions map[string][]int32,
+) (map[string]map[int32]int64, error) {
+	topics := make([]string, 0, len(partitions))
+	for topic := range partitions {
+		topics = append(topics, topic)
+	}
+
+	meta, err := b.GetMetadata(topics...)
+	if err != nil {
+		return nil, err
+	}
+
+	leaders := map[int32]*sarama.Broker{}
+	for _, other := range meta.Brokers {
+		leaders[other.ID()] = other
+	}
+
+	requests := map[int32]*sarama.OffsetRequest{}
+	for _, topic := range meta.Topics {
+		if topic.Err != sarama.ErrNoError {
+			debugf("no metadata for topic %v: %v", topic.Name, topic.Err)
+			continue
+		}
+
+		for _, partition := range topic.Partitions {
+			if !hasPartition(partition.ID, partitions[topic.Name]) {
+				continue
+			}
+			if _, found := leaders[partition.Leader]; !found {
+				debugf("no leader for partition %v:%v", topic.Name, partition.ID)
+				continue
+			}
+
+			req := requests[partition.Leader]
+			if req == nil {
+				req = &sarama.OffsetRequest{}
+				requests[partition.Leader] = req
+			}
+			req.AddBlock(topic.Name, partition.ID, sarama.OffsetNewest, 1)
+		}
+	}
+
+	offsets := map[string]map[int32]int64{}
+	for id, req := range requests {
+		resp, err := b.leaderOffsets(leaders[id], req)
+		if err != nil {
+			debugf("failed to fetch offsets from broker %v: %v", id, err)
+			continue
+		}
+
+		for topic, blocks := range resp.Blocks {
+			for partition, block := range blocks {
+				// Partitions moved to another leader since the metadata was
+				// fetched report an error, they are queried again on next fetch.
+				if block.Err != sarama.ErrNoError || len(block.Offsets) == 0 {
+					debugf("failed to fetch offset of partition %v:%v: %v", topic, partition, block.Err)
+					continue
+				}
+
+				T := offsets[topic]
+				if T == nil {
+					T = map[int32]int64{}
+					offsets[topic] = T
+				}
+				T[partition] = block.Offsets[0]
+			}
+		}
+	}
+
+	return offsets, nil
+}
+
+// leaderOffsets sends an offsets request to the given leader, using the
+// current connection if the leader is this broker.
+func (b *Broker) leaderOffsets(
+	leader *sarama.Broker,
+	req *sarama.OffsetRequest,
+) (*sarama.OffsetResponse, error) {
+	if leader.ID() == b.ID() {
+		return b.broker.GetAvailableOffsets(req)
+	}
+
+	if err := leader.Open(b.cfg); err != nil {
+		return nil, err
+	}
+	defer closeBroker(leader)
+	return leader.GetAvailableOffsets(req)
+}
+
 // ListGroups lists all groups managed by the broker. Other consumer
 // groups might be managed by other brokers.
 func (b *Broker) ListGroups() ([]string, error) {
@@ -470,3 +562,12 @@ func indexOf(s string, lst []string) (int, bool) {
 	}
 	return -1, false
 }
+
+func hasPartition(id int32, partitions []int32) bool {
+	for _, other := range partitions {
+		if id == other {
+			return true
+		}
+	}
+	return false
+}
@@ -13,6 +13,8 @@ type mockClient struct {
 	listGroups        func() ([]string, error)
 	describeGroups    func(group []string) (map[string]kafka.GroupDescription, error)
 	fetchGroupOffsets func(group string) (*sarama.OffsetFetchResponse, error)
+
+	fetchNewestOffsets func(map[string][]int32) (map[string]map[int32]int64, error)
 }
 
 type mockState struct {
@@ -21,6 +23,9 @@ type mockState struct {
 
 	// groups->client->topic->partitions ids
 	groups map[string][]map[string][]int32 // group/client assignments to topics and partition IDs
+
+	// topics -> partitions -> newest offset
+	newest map[string][]int64 // newest offsets reported by partition leaders
 }
 
 func defaultMockClient(state mockState) *mockClient {
@@ -28,6 +33,8 @@ func defaultMockClient(state mockState) *mockClient {
 		listGroups:        makeListGroups(state),
 		describeGroups:    makeDescribeGroups(state),
 		fetchGroupOffsets: makeFetchGroupOffsets(state),
+
+		fetchNewestOffsets: makeFetchNewestOffsets(state),
 	}
 }
 
@@ -121,6 +128,39 @@ func makeFetchGroupOffsetsFail(
 	}
 }
 
+func makeFetchNewestOffsets(
+	state mockState,
+) func(map[string][]int32) (map[string]map[int32]int64, error) {
+	return func(partitions map[string][]int32) (map[string]map[int32]int64, error) {
+		offsets := map[string]map[int32]int64{}
+		for topic, ids := range partitions {
+			newest := state.newest[topic]
+			for _, id := range ids {
+				// partitions without leader are missing from the result
+				if int(id) >= len(newest) || newest[id] < 0 {
+					continue
+				}
+
+				T := offsets[topic]
+				if T == nil {
+					T = map[int32]int64{}
+					offsets[topic] = T
+				}
+				T[id] = newest[id]
+			}
+		}
+		return offsets, nil
+	}
+}
+
+func makeFetchNewestOffsetsFail(
+	err error,
+) func(map[string][]int32) (map[string]map[int32]int64, error) {
+	return func(_ map[string][]int32) (map[string]map[int32]int64, error) {
+		return nil, err
+	}
+}
+
 func (c *mockClient) ListGroups() ([]string, error) { return c.listGroups() }
 func (c *mockClient) DescribeGroups(groups []string) (map[string]kafka.GroupDescription, error) {
 	return c.describeGroups(groups)
@@ -128,3 +168,6 @@ func (c *mockClient) DescribeGroups(groups []string) (map[string]kafka.GroupDesc
 func (c *mockClient) FetchGroupOffsets(group string) (*sarama.OffsetFetchResponse, error) {
 	return c.fetchGroupOffsets(group)
 }
+func (c *mockClient) FetchNewestOffsets(partitions map[string][]int32) (map[string]map[int32]int64, error) {
+	return c.fetchNewestOffsets(partitions)
+}
@@ -14,6 +14,7 @@ type client interface {
 	ListGroups() ([]string, error)
 	DescribeGroups(group []string) (map[string]kafka.GroupDescription, error)
 	FetchGroupOffsets(group string) (*sarama.OffsetFetchResponse, error)
+	FetchNewestOffsets(partitions map[string][]int32) (map[string]map[int32]int64, error)
 }
 
 func fetchGroupInfo(
@@ -68,6 +69,7 @@ func fetchGroupInfo(
 		return err
 	}
 
+	var offsets []result
 	for ret := range results {
 		if err := ret.err; err != nil {
 			// wait for workers to stop and drop results
@@ -75,14 +77,29 @@ func fetchGroupInfo(
 			}
 			return err
 		}
+		offsets = append(offsets, ret)
+	}
+
+	partitions := map[string][]int32{}
+	for _, ret := range offsets {
+		for topic, blocks := range ret.off.Blocks {
+			for partition := range blocks {
+				partitions[topic] = append(partitions[topic], partition)
+			}
+		}
+	}
+	newest := fetchNewestOffsets(b, partitions)
 
+	for _, ret := range offsets {
 		asgnGroup := assignments[ret.group]
+		groupLag := lagTotals{}
 		for topic, partitions := range ret.off.Blocks {
 			var asgnTopic map[int32]groupAssignment
 			if asgnGroup != nil {
 				asgnTopic = asgnGroup[topic]
 			}
 
+			topicLag := lagTotals{}
 			for partition, info := range partitions {
 				event := common.MapStr{
 					"id":        ret.group,
@@ -95,6 +112,11 @@ func fetchGroupInfo(
 					},
 				}
 
+				if lag, ok := consumerLag(info.Offset, newest[topic], partition); ok {
+					event["consumer_lag"] = lag
+					topicLag.add(lag)
+				}
+
 				if asgnTopic != nil {
 					if assignment, found := asgnTopic[partition]; found {
 						event["client"] = common.MapStr{
@@ -107,12 +129,89 @@ func fetchGroupInfo(
 
 				emit(event)
 			}
+
+			if topicLag.partitions > 0 {
+				emit(common.MapStr{
+					"id":    ret.group,
+					"topic": topic,
+					"total": topicLag.toMapStr(),
+				})
+				groupLag.merge(topicLag)
+			}
+		}
+
+		if groupLag.partitions > 0 {
+			emit(common.MapStr{
+				"id":    ret.group,
+				"total": groupLag.toMapStr(),
+			})
 		}
 	}
 
 	return nil
 }
 
+// lagTotals aggregates the consumer lag of multiple partitions.
+type lagTotals struct {
+	lag        int64
+	partitions int
+}
+
+func (l *lagTotals) add(lag int64) {
+	l.lag += lag
+	l.partitions++
+}
+
+func (l *lagTotals) merge(other lagTotals) {
+	l.lag += other.lag
+	l.partitions += other.partitions
+}
+
+func (l *lagTotals) toMapStr() common.MapStr {
+	return common.MapStr{
+		"consumer_lag": l.lag,
+		"partitions":   l.partitions,
+	}
+}
+
+// fetchNewestOffsets fetches the newest offsets of the partitions the groups
+// have committed offsets for. Lag is not reported if they can't be fetched.
+func fetchNewestOffsets(
+	b client,
+	partitions map[string][]int32,
+) map[string]map[int32]int64 {
+	if len(partitions) == 0 {
+		return nil
+	}
+
+	newest, err := b.FetchNewestOffsets(partitions)
+	if err != nil {
+		logp.Err("failed to fetch newest partition offsets: %v", err)
+		return nil
+	}
+	return newest
+}
+
+// consumerLag returns the number of messages in a partition after the offset
+// committed by a consumer group. It is unknown if the group didn't commit an
+// offset or the newest offset of the partition couldn't be fetched.
+func consumerLag(committed int64, newest map[int32]int64, partition int32) (int64, bool) {
+	if committed < 0 {
+		return 0, false
+	}
+
+	offset, found := newest[partition]
+	if !found {
+		return 0, false
+	}
+
+	// The newest offset can be stale if it was fetched from a previous leader.
+	if offset < committed {
+		return 0, true
+	}
+	return offset - committed, true
+}
+
 func listGroups(b client, filter func(string) bool) ([]string, error) {
 	groups, err := b.ListGroups()
 	if err != nil {
@@ -120,6 +120,116 @@ func TestFetchGroupInfo(t *testing.T) {
 			},
 		},
 
+		{
+			name: "compute consumer lag",
+			client: defaultMockClient(mockState{
+				partitions: map[string]map[string][]int64{
+					"group1": {
+						"topic1": {10, 11, 12},
+						"topic2": {5},
+					},
+					"group2": {
+						"topic1": {20, 21, 22},
+					},
+				},
+				groups: map[string][]map[string][]int32{
+					"group1": {{"topic1": {0, 1, 2}, "topic2": {0}}},
+					"group2": {{"topic1": {0, 1, 2}}},
+				},
+				newest: map[string][]int64{
+					"topic1": {30, 31, 32},
+					"topic2": {5},
+				},
+			}),
+			expected: []common.MapStr{
+				testEvent("group1", "topic1", 0, common.MapStr{
+					"offset":       int64(10),
+					"consumer_lag": int64(20),
+				}),
+				testEvent("group1", "topic1", 2, common.MapStr{
+					"offset":       int64(12),
+					"consumer_lag": int64(20),
+				}),
+				testEvent("group1", "topic2", 0, common.MapStr{
+					"consumer_lag": int64(0),
+				}),
+				testEvent("group2", "topic1", 1, common.MapStr{
+					"offset":       int64(21),
+					"consumer_lag": int64(10),
+				}),
+				testTotalEvent("group1", "topic1", 60, 3),
+				testTotalEvent("group1", "topic2", 0, 1),
+				testTotalEvent("group1", "", 60, 4),
+				testTotalEvent("group2", "topic1", 30, 3),
+				testTotalEvent("group2", "", 30, 3),
+			},
+			validate: func(events []common.MapStr) {
+				assert.Len(t, events, 12)
+			},
+		},
+
+		{
+			name: "no lag for missing commits and partitions without leader",
+			client: defaultMockClient(mockState{
+				partitions: map[string]map[string][]int64{
+					"group1": {
+						// no offset committed for partition 1
+						"topic1": {10, -1, 12, 13},
+					},
+				},
+				groups: map[string][]map[string][]int32{
+					"group1": {{"topic1": {0, 1, 2, 3}}},
+				},
+				newest: map[string][]int64{
+					// partition 2 has no leader, the newest offset of
+					// partition 3 was fetched from a stale leader
+					"topic1": {15, 20, -1, 12},
+				},
+			}),
+			expected: []common.MapStr{
+				testEvent("group1", "topic1", 0, common.MapStr{
+					"consumer_lag": int64(5),
+				}),
+				testEvent("group1", "topic1", 3, common.MapStr{
+					"consumer_lag": int64(0),
+				}),
+				testTotalEvent("group1", "topic1", 5, 2),
+				testTotalEvent("group1", "", 5, 2),
+			},
+			validate: func(events []common.MapStr) {
+				assert.Len(t, events, 6)
+				for _, event := range events {
+					partition := event["partition"]
+					if partition == int32(1) || partition == int32(2) {
+						assert.NotContains(t, event, "consumer_lag")
+					}
+				}
+			},
+		},
+
+		{
+			name: "report offsets if newest offsets can't be fetched",
+			client: defaultMockClient(mockState{
+				partitions: map[string]map[string][]int64{
+					"group1": {"topic1": {1}},
+				},
+				groups: map[string][]map[string][]int32{
+					"group1": {{"topic1": {0}}},
+				},
+			}).with(func(c *mockClient) {
+				c.fetchNewestOffsets = makeFetchNewestOffsetsFail(io.EOF)
+			}),
+			expected: []common.MapStr{
+				testEvent("group1", "topic1", 0, common.MapStr{
+					"offset": int64(1),
+				}),
+			},
+			validate: func(events []common.MapStr) {
+				assert.Len(t, events, 1)
+				assert.NotContains(t, events[0], "consumer_lag")
+			},
+		},
+
 		{
 			name:     "no events on empty group",
 			client:   defaultMockClient(mockState{}),
@@ -259,6 +369,20 @@ func testEvent(
 	return event
 }
 
+func testTotalEvent(group, topic string, lag int64, partitions int) common.MapStr {
+	event := common.MapStr{
+		"id": group,
+		"total": common.MapStr{
+			"consumer_lag": lag,
+			"partitions":   partitions,
+		},
+	}
+	if topic != "" {
+		event["topic"] = topic
+	}
+	return event
+}
+
 func clientMeta(id int) common.MapStr {
 	return common.MapStr{
 		"id": fmt.Sprintf("consumer-%v", id),
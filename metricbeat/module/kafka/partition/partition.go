@@ -30,8 +30,6 @@ type MetricSet struct {
 	topics []string
 }
 
-const noID int32 = -1
-
 var errFailQueryOffset = errors.New("operation failed")
 
 var debugf = logp.MakeDebug("kafka")
@@ -117,22 +115,20 @@ func (m *MetricSet) Fetch() ([]common.MapStr, error) {
 				continue
 			}
 
-			// collect offsets for all replicas
-			for _, id := range partition.Replicas {
-
-				// Get oldest and newest available offsets
-				offOldest, offNewest, offOK, err := queryOffsetRange(b, id, topic.Name, partition.ID)
-
-				if !offOK {
-					if err == nil {
-						err = errFailQueryOffset
-					}
-
-					logp.Err("Failed to query kafka partition (%v:%v) offsets: %v",
-						topic.Name, partition.ID, err)
-					continue
+			// Get oldest and newest available offsets
+			offOldest, offNewest, offOK, err := queryOffsetRange(b, topic.Name, partition.ID)
+			if !offOK {
+				if err == nil {
+					err = errFailQueryOffset
 				}
 
+				logp.Err("Failed to query kafka partition (%v:%v) offsets: %v",
+					topic.Name, partition.ID, err)
+				continue
+			}
+
+			// report the offsets of the leader for all replicas
+			for _, id := range partition.Replicas {
 				partitionEvent := common.MapStr{
 					"id":             partition.ID,
 					"leader":         partition.Leader,
@@ -166,19 +162,18 @@ func (m *MetricSet) Fetch() ([]common.MapStr, error) {
 }
 
 // queryOffsetRange queries the broker for the oldest and the newest offsets in
-// a kafka topics partition for a given replica.
+// a kafka topics partition.
 func queryOffsetRange(
 	b *kafka.Broker,
-	replicaID int32,
 	topic string,
 	partition int32,
 ) (int64, int64, bool, error) {
-	oldest, err := b.PartitionOffset(replicaID, topic, partition, sarama.OffsetOldest)
+	oldest, err := b.PartitionOffset(topic, partition, sarama.OffsetOldest)
 	if err != nil {
 		return -1, -1, false, err
 	}
 
-	newest, err := b.PartitionOffset(replicaID, topic, partition, sarama.OffsetNewest)
+	newest, err := b.PartitionOffset(topic, partition, sarama.OffsetNewest)
 	if err != nil {
 		return -1, -1, false, err
 	}
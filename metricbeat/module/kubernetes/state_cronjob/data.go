@@ -0,0 +1,64 @@
+package state_cronjob
+
+import (
+	"github.com/elastic/beats/libbeat/common"
+	"github.com/elastic/beats/metricbeat/mb"
+	"github.com/elastic/beats/metricbeat/module/kubernetes/util"
+
+	dto "github.com/prometheus/client_model/go"
+)
+
+func eventMapping(families []*dto.MetricFamily) ([]common.MapStr, error) {
+	eventsMap := map[string]common.MapStr{}
+	for _, family := range families {
+		for _, metric := range family.GetMetric() {
+			cronjob := util.GetLabel(metric, "cronjob")
+			if cronjob == "" {
+				continue
+			}
+			namespace := util.GetLabel(metric, "namespace")
+			key := namespace + "/" + cronjob
+			event, ok := eventsMap[key]
+			if !ok {
+				event = common.MapStr{}
+				eventsMap[key] = event
+			}
+			switch family.GetName() {
+			case "kube_cronjob_info":
+				event.Put(mb.ModuleDataKey+".namespace", namespace)
+				event.Put(mb.NamespaceKey, "cronjob")
+				event.Put("name", cronjob)
+				event.Put("schedule", util.GetLabel(metric, "schedule"))
+				event.Put("concurrency", util.GetLabel(metric, "concurrency_policy"))
+
+			case "kube_cronjob_created":
+				event.Put("created", util.GetTime(metric))
+
+			case "kube_cronjob_status_active":
+				event.Put("active.count", metric.GetGauge().GetValue())
+
+			case "kube_cronjob_status_last_schedule_time":
+				event.Put("last_schedule", util.GetTime(metric))
+
+			case "kube_cronjob_next_schedule_time":
+				event.Put("next_schedule", util.GetTime(metric))
+
+			case "kube_cronjob_spec_suspend":
+				event.Put("is_suspended", metric.GetGauge().GetValue() == 1)
+
+			case "kube_cronjob_spec_starting_deadline_seconds":
+				event.Put("deadline.sec", metric.GetGauge().GetValue())
+
+			default:
+				// Ignore unknown metric
+				continue
+			}
+		}
+	}
+
+	var events []common.MapStr
+	for _, event := range eventsMap {
+		events = append(events, event)
+	}
+	return events, nil
+}
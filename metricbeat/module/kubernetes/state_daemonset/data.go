@@ -0,0 +1,65 @@
+package state_daemonset
+
+import (
+	"github.com/elastic/beats/libbeat/common"
+	"github.com/elastic/beats/metricbeat/mb"
+	"github.com/elastic/beats/metricbeat/module/kubernetes/util"
+
+	dto "github.com/prometheus/client_model/go"
+)
+
+func eventMapping(families []*dto.MetricFamily) ([]common.MapStr, error) {
+	eventsMap := map[string]common.MapStr{}
+	for _, family := range families {
+		for _, metric := range family.GetMetric() {
+			daemonset := util.GetLabel(metric, "daemonset")
+			if daemonset == "" {
+				continue
+			}
+			namespace := util.GetLabel(metric, "namespace")
+			key := namespace + "/" + daemonset
+			event, ok := eventsMap[key]
+			if !ok {
+				event = common.MapStr{}
+				eventsMap[key] = event
+			}
+			switch family.GetName() {
+			case "kube_daemonset_metadata_generation":
+				event.Put(mb.ModuleDataKey+".namespace", namespace)
+				event.Put(mb.NamespaceKey, "daemonset")
+				event.Put("name", daemonset)
+
+			case "kube_daemonset_status_desired_number_scheduled":
+				event.Put("replicas.desired", metric.GetGauge().GetValue())
+
+			case "kube_daemonset_status_current_number_scheduled":
+				event.Put("replicas.current", metric.GetGauge().GetValue())
+
+			case "kube_daemonset_status_number_ready":
+				event.Put("replicas.ready", metric.GetGauge().GetValue())
+
+			case "kube_daemonset_status_number_available":
+				event.Put("replicas.available", metric.GetGauge().GetValue())
+
+			case "kube_daemonset_status_number_unavailable":
+				event.Put("replicas.unavailable", metric.GetGauge().GetValue())
+
+			case "kube_daemonset_updated_number_scheduled":
+				event.Put("replicas.updated", metric.GetGauge().GetValue())
+
+			case "kube_daemonset_status_number_misscheduled":
+				event.Put("replicas.misscheduled", metric.GetGauge().GetValue())
+
+			default:
+				// Ignore unknown metric
+				continue
+			}
+		}
+	}
+
+	var events []common.MapStr
+	for _, event := range eventsMap {
+		events = append(events, event)
+	}
+	return events, nil
+}
@@ -0,0 +1,83 @@
+package state_job
+
+import (
+	"github.com/elastic/beats/libbeat/common"
+	"github.com/elastic/beats/metricbeat/mb"
+	"github.com/elastic/beats/metricbeat/module/kubernetes/util"
+
+	dto "github.com/prometheus/client_model/go"
+)
+
+func eventMapping(families []*dto.MetricFamily) ([]common.MapStr, error) {
+	eventsMap := map[string]common.MapStr{}
+	for _, family := range families {
+		for _, metric := range family.GetMetric() {
+			// kube-state-metrics versions before 1.2 label jobs with job
+			// instead of job_name.
+			job := util.GetLabel(metric, "job_name")
+			if job == "" {
+				job = util.GetLabel(metric, "job")
+			}
+			if job == "" {
+				continue
+			}
+			namespace := util.GetLabel(metric, "namespace")
+			key := namespace + "/" + job
+			event, ok := eventsMap[key]
+			if !ok {
+				event = common.MapStr{}
+				eventsMap[key] = event
+			}
+			switch family.GetName() {
+			case "kube_job_info":
+				event.Put(mb.ModuleDataKey+".namespace", namespace)
+				event.Put(mb.NamespaceKey, "job")
+				event.Put("name", job)
+
+			case "kube_job_created":
+				event.Put("time.created", util.GetTime(metric))
+
+			case "kube_job_status_start_time":
+				event.Put("time.start", util.GetTime(metric))
+
+			case "kube_job_status_completion_time":
+				event.Put("time.completion", util.GetTime(metric))
+
+			case "kube_job_spec_parallelism":
+				event.Put("parallelism", metric.GetGauge().GetValue())
+
+			case "kube_job_spec_completions":
+				event.Put("completions.desired", metric.GetGauge().GetValue())
+
+			case "kube_job_status_active":
+				event.Put("pods.active", metric.GetGauge().GetValue())
+
+			case "kube_job_status_succeeded":
+				event.Put("pods.succeeded", metric.GetGauge().GetValue())
+
+			case "kube_job_status_failed":
+				event.Put("pods.failed", metric.GetGauge().GetValue())
+
+			case "kube_job_complete":
+				if metric.GetGauge().GetValue() == 1 {
+					event.Put("status.complete", util.GetLabel(metric, "condition"))
+				}
+
+			case "kube_job_failed":
+				if metric.GetGauge().GetValue() == 1 {
+					event.Put("status.failed", util.GetLabel(metric, "condition"))
+				}
+
+			default:
+				// Ignore unknown metric
+				continue
+			}
+		}
+	}
+
+	var events []common.MapStr
+	for _, event := range eventsMap {
+		events = append(events, event)
+	}
+	return events, nil
+}
@@ -1,6 +1,13 @@
 package util
 
-import dto "github.com/prometheus/client_model/go"
+import (
+	"math"
+	"time"
+
+	"github.com/elastic/beats/libbeat/common"
+
+	dto "github.com/prometheus/client_model/go"
+)
 
 // GetLabel returns desired label from the given metric, or "" if not present
 func GetLabel(m *dto.Metric, label string) string {
@@ -11,3 +18,9 @@ func GetLabel(m *dto.Metric, label string) string {
 	}
 	return ""
 }
+
+// GetTime returns the unix timestamp value of the given gauge metric as a time
+func GetTime(m *dto.Metric) common.Time {
+	sec, frac := math.Modf(m.GetGauge().GetValue())
+	return common.Time(time.Unix(int64(sec), int64(frac*1e9)).UTC())
+}
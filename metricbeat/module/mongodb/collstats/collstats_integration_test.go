@@ -0,0 +1,53 @@
+// +build integration
+
+package collstats
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/elastic/beats/libbeat/tests/compose"
+	mbtest "github.com/elastic/beats/metricbeat/mb/testing"
+	"github.com/elastic/beats/metricbeat/module/mongodb"
+)
+
+func TestFetch(t *testing.T) {
+	compose.EnsureUp(t, "mongodb")
+
+	f := mbtest.NewEventsFetcher(t, getConfig())
+	events, err := f.Fetch()
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+
+	for _, event := range events {
+		t.Logf("%s/%s event: %+v", f.Module().Name(), f.Name(), event)
+
+		// Check a few event Fields
+		db := event["db"].(string)
+		assert.NotEqual(t, db, "")
+
+		count, err := event.GetValue("total.count")
+		assert.NoError(t, err)
+		assert.True(t, count.(int64) >= 0)
+	}
+}
+
+func TestData(t *testing.T) {
+	compose.EnsureUp(t, "mongodb")
+
+	f := mbtest.NewEventsFetcher(t, getConfig())
+	err := mbtest.WriteEvents(f, t)
+	if err != nil {
+		t.Fatal("write", err)
+	}
+}
+
+func getConfig() map[string]interface{} {
+	return map[string]interface{}{
+		"module":     "mongodb",
+		"metricsets": []string{"collstats"},
+		"hosts":      []string{mongodb.GetEnvHost() + ":" + mongodb.GetEnvPort()},
+	}
+}
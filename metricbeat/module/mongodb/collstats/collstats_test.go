@@ -0,0 +1,97 @@
+// +build !integration
+
+package collstats
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/mgo.v2/bson"
+
+	"github.com/elastic/beats/libbeat/common"
+)
+
+// loadResponse decodes a command response recorded in extended JSON the same
+// way the driver decodes the BSON documents received from the server.
+func loadResponse(t *testing.T, name string) map[string]interface{} {
+	data, err := ioutil.ReadFile(filepath.Join("_meta", "testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var doc bson.M
+	if err := bson.UnmarshalJSON(data, &doc); err != nil {
+		t.Fatal(err)
+	}
+	raw, err := bson.Marshal(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	result := map[string]interface{}{}
+	if err := bson.Unmarshal(raw, &result); err != nil {
+		t.Fatal(err)
+	}
+	return result
+}
+
+func TestEventsMapping(t *testing.T) {
+	events, err := eventsMapping(loadResponse(t, "top.json"))
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Len(t, events, 3)
+
+	testCases := map[string]interface{}{
+		"db":                 "shop",
+		"collection":         "users.sessions",
+		"total.time.us":      40210,
+		"total.count":        320,
+		"lock.read.time.us":  40210,
+		"lock.read.count":    320,
+		"lock.write.time.us": 0,
+		"lock.write.count":   0,
+		"queries.count":      320,
+		"commands.count":     0,
+	}
+	testEvent(t, events, "shop.users.sessions", testCases)
+
+	testCases = map[string]interface{}{
+		"db":                 "shop",
+		"collection":         "orders",
+		"total.time.us":      2934567,
+		"total.count":        18432,
+		"lock.read.time.us":  1053620,
+		"lock.read.count":    12288,
+		"lock.write.time.us": 1880947,
+		"lock.write.count":   6144,
+		"queries.time.us":    954210,
+		"getmore.count":      512,
+		"insert.count":       5120,
+		"update.count":       896,
+		"remove.count":       128,
+		"commands.time.us":   17070,
+	}
+	testEvent(t, events, "shop.orders", testCases)
+}
+
+func TestEventsMappingInvalid(t *testing.T) {
+	_, err := eventsMapping(map[string]interface{}{"ok": 1})
+	assert.Error(t, err)
+}
+
+func testEvent(t *testing.T, events []common.MapStr, name string, testCases map[string]interface{}) {
+	for _, event := range events {
+		if event["name"] != name {
+			continue
+		}
+		for k, v := range testCases {
+			data, err := event.GetValue(k)
+			assert.NoError(t, err, "Could not read field "+k)
+			assert.EqualValues(t, v, data, "Wrong value for field "+k)
+		}
+		return
+	}
+	t.Errorf("Event of collection %s not found", name)
+}
@@ -0,0 +1,68 @@
+package collstats
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/elastic/beats/libbeat/common"
+	s "github.com/elastic/beats/libbeat/common/schema"
+	c "github.com/elastic/beats/libbeat/common/schema/mapstriface"
+)
+
+func usage(key string) c.ConvMap {
+	return c.Dict(key, s.Schema{
+		"time": s.Object{
+			"us": c.Int("time"),
+		},
+		"count": c.Int("count"),
+	}, c.DictOptional)
+}
+
+var schema = s.Schema{
+	"total": usage("total"),
+	"lock": s.Object{
+		"read":  usage("readLock"),
+		"write": usage("writeLock"),
+	},
+	"queries":  usage("queries"),
+	"getmore":  usage("getmore"),
+	"insert":   usage("insert"),
+	"update":   usage("update"),
+	"remove":   usage("remove"),
+	"commands": usage("commands"),
+}
+
+// eventsMapping returns an event per collection from the result of the top
+// command. The times are cumulative and reported in microseconds.
+func eventsMapping(result map[string]interface{}) ([]common.MapStr, error) {
+	totals, ok := result["totals"].(map[string]interface{})
+	if !ok {
+		return nil, errors.New("error accessing collection totals in top result")
+	}
+
+	var events []common.MapStr
+	for ns, v := range totals {
+		// totals also contains a note about the units.
+		usage, ok := v.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		parts := strings.SplitN(ns, ".", 2)
+		if len(parts) != 2 {
+			continue
+		}
+
+		event := common.MapStr{
+			"db":         parts[0],
+			"collection": parts[1],
+			"name":       ns,
+		}
+		if _, errs := schema.ApplyTo(event, usage); errs.HasRequiredErrors() {
+			return nil, fmt.Errorf("error mapping usage of collection %s: %v", ns, errs)
+		}
+		events = append(events, event)
+	}
+	return events, nil
+}
@@ -0,0 +1,176 @@
+package replstatus
+
+import (
+	"strings"
+	"time"
+
+	"github.com/elastic/beats/libbeat/common"
+)
+
+// replSetStatus is the result of the replSetGetStatus command.
+type replSetStatus struct {
+	Set     string    `bson:"set"`
+	Date    time.Time `bson:"date"`
+	MyState int       `bson:"myState"`
+	Term    *int64    `bson:"term"`
+	Members []member  `bson:"members"`
+}
+
+type member struct {
+	ID           int       `bson:"_id"`
+	Name         string    `bson:"name"`
+	Health       float64   `bson:"health"`
+	State        int       `bson:"state"`
+	StateStr     string    `bson:"stateStr"`
+	Uptime       int64     `bson:"uptime"`
+	OptimeDate   time.Time `bson:"optimeDate"`
+	ElectionDate time.Time `bson:"electionDate"`
+	PingMs       *int64    `bson:"pingMs"`
+	SyncingTo    string    `bson:"syncingTo"`
+	Self         bool      `bson:"self"`
+}
+
+// Replica set member states,
+// see https://docs.mongodb.com/manual/reference/replica-states/
+const (
+	statePrimary   = 1
+	stateSecondary = 2
+	stateArbiter   = 7
+)
+
+func (m *member) healthy() bool {
+	// The health of the member running the command is not reported.
+	return m.Self || m.Health == 1
+}
+
+// eventsMapping returns an event with the status of the replica set and an
+// event per member.
+func eventsMapping(status replSetStatus, oplog *oplogInfo) []common.MapStr {
+	var primary *member
+	for i := range status.Members {
+		if status.Members[i].State == statePrimary {
+			primary = &status.Members[i]
+		}
+	}
+
+	event := common.MapStr{
+		"set_name":    status.Set,
+		"server_date": common.Time(status.Date.UTC()),
+		"my_state":    status.MyState,
+	}
+
+	members := common.MapStr{"count": len(status.Members)}
+	healthy, secondaries, arbiters := 0, 0, 0
+	var maxLag, minLag time.Duration
+	for i, m := range status.Members {
+		if m.healthy() {
+			healthy++
+		}
+		switch m.State {
+		case stateSecondary:
+			lag, ok := replicationLag(primary, &status.Members[i])
+			if !ok {
+				break
+			}
+			if secondaries == 0 || lag > maxLag {
+				maxLag = lag
+			}
+			if secondaries == 0 || lag < minLag {
+				minLag = lag
+			}
+			secondaries++
+		case stateArbiter:
+			arbiters++
+		}
+	}
+	members.Put("healthy.count", healthy)
+	members.Put("unhealthy.count", len(status.Members)-healthy)
+	members.Put("secondary.count", secondaries)
+	members.Put("arbiter.count", arbiters)
+	event["members"] = members
+
+	if secondaries > 0 {
+		event["lag"] = common.MapStr{
+			"max": common.MapStr{"sec": maxLag.Seconds()},
+			"min": common.MapStr{"sec": minLag.Seconds()},
+		}
+	}
+
+	if primary != nil {
+		members.Put("primary.host", primary.Name)
+
+		election := common.MapStr{
+			"date": common.Time(primary.ElectionDate.UTC()),
+		}
+		if status.Term != nil {
+			election["term"] = *status.Term
+		}
+		event["election"] = election
+	}
+
+	if oplog != nil {
+		event["oplog"] = common.MapStr{
+			"size": common.MapStr{
+				"allocated": oplog.allocated,
+				"used":      oplog.used,
+			},
+			"first": common.MapStr{"timestamp": common.Time(oplog.first)},
+			"last":  common.MapStr{"timestamp": common.Time(oplog.last)},
+			"window": common.MapStr{
+				"sec": oplog.window().Seconds(),
+			},
+		}
+	}
+
+	events := []common.MapStr{event}
+	for i := range status.Members {
+		events = append(events, memberMapping(status.Set, primary, &status.Members[i]))
+	}
+	return events
+}
+
+func memberMapping(set string, primary, m *member) common.MapStr {
+	data := common.MapStr{
+		"id":      m.ID,
+		"name":    m.Name,
+		"self":    m.Self,
+		"healthy": m.healthy(),
+		"state": common.MapStr{
+			"code": m.State,
+			"name": strings.ToLower(m.StateStr),
+		},
+		"uptime": common.MapStr{"sec": m.Uptime},
+	}
+	if m.State != stateArbiter {
+		data.Put("optime.date", common.Time(m.OptimeDate.UTC()))
+	}
+	if m.State == stateSecondary {
+		if lag, ok := replicationLag(primary, m); ok {
+			data.Put("lag.sec", lag.Seconds())
+		}
+	}
+	if m.PingMs != nil {
+		data.Put("ping.ms", *m.PingMs)
+	}
+	if m.SyncingTo != "" {
+		data["syncing_to"] = m.SyncingTo
+	}
+
+	return common.MapStr{
+		"set_name": set,
+		"member":   data,
+	}
+}
+
+// replicationLag returns how far behind the primary a secondary member is. It
+// can't be computed when there is no primary.
+func replicationLag(primary, secondary *member) (time.Duration, bool) {
+	if primary == nil {
+		return 0, false
+	}
+	lag := primary.OptimeDate.Sub(secondary.OptimeDate)
+	if lag < 0 {
+		lag = 0
+	}
+	return lag, true
+}
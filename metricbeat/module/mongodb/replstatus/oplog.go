@@ -0,0 +1,68 @@
+package replstatus
+
+import (
+	"time"
+
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+)
+
+const oplogCollection = "oplog.rs"
+
+// oplogInfo contains the size of the oplog and the time of its first and
+// last entries.
+type oplogInfo struct {
+	allocated int64
+	used      int64
+	first     time.Time
+	last      time.Time
+}
+
+// window returns the time span covered by the entries of the oplog.
+func (o *oplogInfo) window() time.Duration {
+	return o.last.Sub(o.first)
+}
+
+type oplogStats struct {
+	Size    int64 `bson:"size"`
+	MaxSize int64 `bson:"maxSize"`
+}
+
+type oplogEntry struct {
+	Timestamp bson.MongoTimestamp `bson:"ts"`
+}
+
+func getOplogInfo(session *mgo.Session) (*oplogInfo, error) {
+	db := session.DB("local")
+
+	var stats oplogStats
+	if err := db.Run(bson.D{{Name: "collStats", Value: oplogCollection}}, &stats); err != nil {
+		return nil, err
+	}
+
+	collection := db.C(oplogCollection)
+	var first, last oplogEntry
+	if err := collection.Find(nil).Sort("$natural").One(&first); err != nil {
+		return nil, err
+	}
+	if err := collection.Find(nil).Sort("-$natural").One(&last); err != nil {
+		return nil, err
+	}
+
+	return newOplogInfo(stats, first, last), nil
+}
+
+func newOplogInfo(stats oplogStats, first, last oplogEntry) *oplogInfo {
+	return &oplogInfo{
+		allocated: stats.MaxSize,
+		used:      stats.Size,
+		first:     timestampTime(first.Timestamp),
+		last:      timestampTime(last.Timestamp),
+	}
+}
+
+// timestampTime returns the time of a MongoDB timestamp, stored in its 32
+// most significant bits as seconds since the epoch.
+func timestampTime(ts bson.MongoTimestamp) time.Time {
+	return time.Unix(int64(uint64(ts)>>32), 0).UTC()
+}
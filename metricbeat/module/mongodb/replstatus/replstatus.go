@@ -0,0 +1,74 @@
+package replstatus
+
+import (
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+
+	"github.com/elastic/beats/libbeat/common"
+	"github.com/elastic/beats/libbeat/common/cfgwarn"
+	"github.com/elastic/beats/libbeat/logp"
+	"github.com/elastic/beats/metricbeat/mb"
+	"github.com/elastic/beats/metricbeat/module/mongodb"
+)
+
+var debugf = logp.MakeDebug("mongodb.replstatus")
+
+// init registers the MetricSet with the central registry.
+// The New method will be called after the setup of the module and before starting to fetch data
+func init() {
+	if err := mb.Registry.AddMetricSet("mongodb", "replstatus", New, mongodb.ParseURL); err != nil {
+		panic(err)
+	}
+}
+
+// MetricSet type defines all fields of the MetricSet
+// As a minimum it must inherit the mb.BaseMetricSet fields, but can be extended with
+// additional entries. These variables can be used to persist data or configuration between
+// multiple fetch calls.
+type MetricSet struct {
+	mb.BaseMetricSet
+	dialInfo *mgo.DialInfo
+}
+
+// New creates a new instance of the MetricSet
+// Part of new is also setting up the configuration by processing additional
+// configuration entries if needed.
+func New(base mb.BaseMetricSet) (mb.MetricSet, error) {
+	cfgwarn.Experimental("The %v %v metricset is experimental", base.Module().Name(), base.Name())
+
+	dialInfo, err := mgo.ParseURL(base.HostData().URI)
+	if err != nil {
+		return nil, err
+	}
+	dialInfo.Timeout = base.Module().Config().Timeout
+
+	return &MetricSet{
+		BaseMetricSet: base,
+		dialInfo:      dialInfo,
+	}, nil
+}
+
+// Fetch methods implements the data gathering and data conversion to the right format
+// It returns an event with the status of the replica set and an event per member
+// of the replica set.
+func (m *MetricSet) Fetch() ([]common.MapStr, error) {
+	mongoSession, err := mongodb.NewDirectSession(m.dialInfo)
+	if err != nil {
+		return nil, err
+	}
+	defer mongoSession.Close()
+
+	var status replSetStatus
+	if err := mongoSession.DB("admin").Run(bson.D{{Name: "replSetGetStatus", Value: 1}}, &status); err != nil {
+		return nil, err
+	}
+
+	// Reading the oplog requires access to the local database, report the
+	// status of the replica set even if it is not allowed.
+	oplog, err := getOplogInfo(mongoSession)
+	if err != nil {
+		debugf("Failed to read oplog info: %v", err)
+	}
+
+	return eventsMapping(status, oplog), nil
+}
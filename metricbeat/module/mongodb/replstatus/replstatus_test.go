@@ -0,0 +1,132 @@
+// +build !integration
+
+package replstatus
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/mgo.v2/bson"
+
+	"github.com/elastic/beats/libbeat/common"
+)
+
+// loadResponse decodes a command response recorded in extended JSON the same
+// way the driver decodes the BSON documents received from the server.
+func loadResponse(t *testing.T, name string, out interface{}) {
+	data, err := ioutil.ReadFile(filepath.Join("_meta", "testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var doc bson.M
+	if err := bson.UnmarshalJSON(data, &doc); err != nil {
+		t.Fatal(err)
+	}
+	raw, err := bson.Marshal(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := bson.Unmarshal(raw, out); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func loadOplogInfo(t *testing.T) *oplogInfo {
+	var stats oplogStats
+	var first, last oplogEntry
+	loadResponse(t, "oplogStats.json", &stats)
+	loadResponse(t, "oplogFirst.json", &first)
+	loadResponse(t, "oplogLast.json", &last)
+	return newOplogInfo(stats, first, last)
+}
+
+func TestEventsMapping(t *testing.T) {
+	var status replSetStatus
+	loadResponse(t, "replSetGetStatus.json", &status)
+
+	events := eventsMapping(status, loadOplogInfo(t))
+	if !assert.Len(t, events, 6) {
+		return
+	}
+
+	event := events[0]
+	testCases := map[string]interface{}{
+		"set_name":                "rs0",
+		"my_state":                1,
+		"members.count":           5,
+		"members.healthy.count":   4,
+		"members.unhealthy.count": 1,
+		"members.secondary.count": 2,
+		"members.arbiter.count":   1,
+		"members.primary.host":    "mongo1:27017",
+		"lag.max.sec":             120.0,
+		"lag.min.sec":             5.0,
+		"election.term":           int64(3),
+		"election.date":           common.Time(time.Date(2017, 10, 17, 13, 24, 35, 0, time.UTC)),
+		"oplog.size.allocated":    int64(1073741824),
+		"oplog.size.used":         int64(25165824),
+		"oplog.first.timestamp":   common.Time(time.Date(2017, 10, 16, 13, 24, 35, 0, time.UTC)),
+		"oplog.last.timestamp":    common.Time(time.Date(2017, 10, 18, 13, 24, 35, 0, time.UTC)),
+		"oplog.window.sec":        172800.0,
+	}
+	for k, v := range testCases {
+		testValue(t, event, k, v)
+	}
+
+	testCases = map[string]interface{}{
+		"set_name":           "rs0",
+		"member.id":          2,
+		"member.name":        "mongo3:27017",
+		"member.self":        false,
+		"member.healthy":     true,
+		"member.state.code":  2,
+		"member.state.name":  "secondary",
+		"member.uptime.sec":  int64(86390),
+		"member.lag.sec":     120.0,
+		"member.ping.ms":     int64(5),
+		"member.syncing_to":  "mongo2:27017",
+		"member.optime.date": common.Time(time.Date(2017, 10, 18, 13, 22, 35, 0, time.UTC)),
+	}
+	for k, v := range testCases {
+		testValue(t, events[3], k, v)
+	}
+
+	// The primary reports its own health.
+	testValue(t, events[1], "member.healthy", true)
+	testValue(t, events[1], "member.self", true)
+	testValue(t, events[4], "member.healthy", false)
+
+	// Arbiters don't have an optime.
+	_, err := events[5].GetValue("member.optime")
+	assert.Error(t, err)
+}
+
+func TestEventsMappingWithoutPrimary(t *testing.T) {
+	var status replSetStatus
+	loadResponse(t, "replSetGetStatus.json", &status)
+	status.Members[0].State = stateSecondary
+	status.Members[0].StateStr = "SECONDARY"
+
+	// Without access to the oplog, nor a primary to compare optimes with.
+	events := eventsMapping(status, nil)
+	if !assert.Len(t, events, 6) {
+		return
+	}
+
+	for _, key := range []string{"lag", "election", "oplog", "members.primary"} {
+		_, err := events[0].GetValue(key)
+		assert.Error(t, err, key)
+	}
+	_, err := events[2].GetValue("member.lag")
+	assert.Error(t, err)
+}
+
+func testValue(t *testing.T, event common.MapStr, field string, expected interface{}) {
+	data, err := event.GetValue(field)
+	assert.NoError(t, err, "Could not read field "+field)
+	assert.EqualValues(t, expected, data, "Wrong value for field "+field)
+}
@@ -0,0 +1 @@
+CREATE EXTENSION pg_stat_statements;
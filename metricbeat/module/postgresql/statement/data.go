@@ -0,0 +1,109 @@
+package statement
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/elastic/beats/libbeat/common"
+	s "github.com/elastic/beats/libbeat/common/schema"
+	c "github.com/elastic/beats/libbeat/common/schema/mapstrstr"
+	"github.com/elastic/beats/metricbeat/mb/module"
+)
+
+// Based on: https://www.postgresql.org/docs/9.5/static/pgstatstatements.html
+var schema = s.Schema{
+	"user": s.Object{
+		"id":   c.Int("userid"),
+		"name": c.Str("rolname", s.Optional),
+	},
+	"database": s.Object{
+		"oid":  c.Int("dbid"),
+		"name": c.Str("datname", s.Optional),
+	},
+	"query": s.Object{
+		"id":    c.Str("queryid"),
+		"text":  c.Str("query"),
+		"calls": c.Int("calls"),
+		"rows":  c.Int("rows"),
+		"time": s.Object{
+			"total":  s.Object{"ms": c.Float("total_time")},
+			"min":    s.Object{"ms": c.Float("min_time", s.Optional)},
+			"max":    s.Object{"ms": c.Float("max_time", s.Optional)},
+			"mean":   s.Object{"ms": c.Float("mean_time", s.Optional)},
+			"stddev": s.Object{"ms": c.Float("stddev_time", s.Optional)},
+		},
+		"memory": s.Object{
+			"shared": s.Object{
+				"hit":     c.Int("shared_blks_hit"),
+				"read":    c.Int("shared_blks_read"),
+				"dirtied": c.Int("shared_blks_dirtied"),
+				"written": c.Int("shared_blks_written"),
+			},
+			"local": s.Object{
+				"hit":     c.Int("local_blks_hit"),
+				"read":    c.Int("local_blks_read"),
+				"dirtied": c.Int("local_blks_dirtied"),
+				"written": c.Int("local_blks_written"),
+			},
+			"temp": s.Object{
+				"read":    c.Int("temp_blks_read"),
+				"written": c.Int("temp_blks_written"),
+			},
+		},
+	},
+}
+
+func eventsMapping(results []map[string]interface{}) ([]common.MapStr, error) {
+	var events []common.MapStr
+	errors := s.NewErrors()
+
+	for _, result := range results {
+		event, errs := schema.Apply(result)
+		errors.AddErrors(errs)
+		events = append(events, event)
+	}
+
+	if errors.HasRequiredErrors() {
+		return events, errors
+	}
+	return events, nil
+}
+
+// rateFields are the counters of the statements whose rates per second are
+// reported, and the fields of the rates.
+var rateFields = map[string]string{
+	"query.calls":              "query.rate.calls",
+	"query.rows":               "query.rate.rows",
+	"query.time.total.ms":      "query.rate.time.ms",
+	"query.memory.shared.hit":  "query.rate.memory.shared.hit",
+	"query.memory.shared.read": "query.rate.memory.shared.read",
+}
+
+// addRates adds the rates since the previous fetch to the events of the
+// statements. Statements are identified by the query id, the database and the
+// user.
+func addRates(rates *module.Rates, events []common.MapStr, now time.Time) {
+	for _, event := range events {
+		queryID, err := event.GetValue("query.id")
+		if err != nil || queryID == "" {
+			continue
+		}
+		dbID, _ := event.GetValue("database.oid")
+		userID, _ := event.GetValue("user.id")
+		key := fmt.Sprintf("%v:%v:%v", dbID, userID, queryID)
+
+		for field, rateField := range rateFields {
+			v, err := event.GetValue(field)
+			if err != nil {
+				continue
+			}
+			value, ok := module.ToFloat(v)
+			if !ok {
+				continue
+			}
+			if rate, ok := rates.Rate(key+"|"+field, value, now); ok {
+				event.Put(rateField, rate)
+			}
+		}
+	}
+}
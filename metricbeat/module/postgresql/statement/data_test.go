@@ -0,0 +1,141 @@
+// +build !integration
+
+package statement
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/elastic/beats/libbeat/common"
+	"github.com/elastic/beats/metricbeat/mb/module"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// statementResult returns a row of pg_stat_statements as returned by
+// postgresql.QueryStats.
+func statementResult(queryID, calls, rows, totalTime, hit, read string) map[string]interface{} {
+	return map[string]interface{}{
+		"userid":              "10",
+		"rolname":             "postgres",
+		"dbid":                "12641",
+		"datname":             "postgres",
+		"queryid":             queryID,
+		"query":               "SELECT * FROM pg_stat_activity WHERE état = ?",
+		"calls":               calls,
+		"rows":                rows,
+		"total_time":          totalTime,
+		"min_time":            "0.052",
+		"max_time":            "1.384",
+		"mean_time":           "0.1535",
+		"stddev_time":         "0.121",
+		"shared_blks_hit":     hit,
+		"shared_blks_read":    read,
+		"shared_blks_dirtied": "0",
+		"shared_blks_written": "0",
+		"local_blks_hit":      "0",
+		"local_blks_read":     "0",
+		"local_blks_dirtied":  "0",
+		"local_blks_written":  "0",
+		"temp_blks_read":      "0",
+		"temp_blks_written":   "0",
+		"blk_read_time":       "0",
+		"blk_write_time":      "0",
+	}
+}
+
+func TestEventsMapping(t *testing.T) {
+	results := []map[string]interface{}{
+		statementResult("1297843418", "20", "40", "3.07", "120", "4"),
+	}
+
+	events, err := eventsMapping(results)
+	assert.NoError(t, err)
+	if !assert.Len(t, events, 1) {
+		t.FailNow()
+	}
+	event := events[0]
+
+	expected := map[string]interface{}{
+		"user.id":                  int64(10),
+		"user.name":                "postgres",
+		"database.oid":             int64(12641),
+		"database.name":            "postgres",
+		"query.id":                 "1297843418",
+		"query.text":               "SELECT * FROM pg_stat_activity WHERE état = ?",
+		"query.calls":              int64(20),
+		"query.rows":               int64(40),
+		"query.time.total.ms":      3.07,
+		"query.time.mean.ms":       0.1535,
+		"query.memory.shared.hit":  int64(120),
+		"query.memory.shared.read": int64(4),
+		"query.memory.temp.read":   int64(0),
+	}
+	for key, value := range expected {
+		actual, err := event.GetValue(key)
+		if assert.NoError(t, err, key) {
+			assert.Equal(t, value, actual, key)
+		}
+	}
+}
+
+func TestEventsMappingMissingFields(t *testing.T) {
+	result := statementResult("1297843418", "20", "40", "3.07", "120", "4")
+	delete(result, "calls")
+
+	_, err := eventsMapping([]map[string]interface{}{result})
+	assert.Error(t, err)
+}
+
+func TestStatementsQuery(t *testing.T) {
+	query := statementsQuery(0, 0)
+	assert.Contains(t, query, "s.query,")
+	assert.NotContains(t, query, "LIMIT")
+
+	query = statementsQuery(20, 512)
+	assert.Contains(t, query, "left(s.query, 512) AS query,")
+	assert.True(t, strings.HasSuffix(query, " LIMIT 20"), query)
+}
+
+func TestAddRates(t *testing.T) {
+	rates := module.NewRates(10 * time.Second)
+	start := time.Now()
+
+	events, _ := eventsMapping([]map[string]interface{}{
+		statementResult("1", "20", "40", "3.5", "120", "4"),
+		statementResult("2", "10", "10", "1.0", "10", "0"),
+	})
+	addRates(rates, events, start)
+	for _, event := range events {
+		assert.NotContains(t, event["query"], "rate")
+	}
+
+	events, _ = eventsMapping([]map[string]interface{}{
+		statementResult("1", "40", "80", "5.5", "220", "14"),
+		statementResult("3", "10", "10", "1.0", "10", "0"),
+	})
+	addRates(rates, events, start.Add(10*time.Second))
+
+	rate, err := events[0].GetValue("query.rate")
+	if assert.NoError(t, err) {
+		assert.Equal(t, common.MapStr{
+			"calls": 2.0,
+			"rows":  4.0,
+			"time":  common.MapStr{"ms": 0.2},
+			"memory": common.MapStr{
+				"shared": common.MapStr{"hit": 10.0, "read": 1.0},
+			},
+		}, rate)
+	}
+
+	// Statement seen for the first time.
+	assert.NotContains(t, events[1]["query"], "rate")
+
+	// Statistics were reset.
+	events, _ = eventsMapping([]map[string]interface{}{
+		statementResult("1", "2", "4", "0.5", "10", "1"),
+	})
+	addRates(rates, events, start.Add(20*time.Second))
+	assert.NotContains(t, events[0]["query"], "rate")
+}
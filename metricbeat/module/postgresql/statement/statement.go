@@ -0,0 +1,99 @@
+package statement
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/elastic/beats/libbeat/common"
+	"github.com/elastic/beats/libbeat/common/cfgwarn"
+	"github.com/elastic/beats/metricbeat/mb"
+	"github.com/elastic/beats/metricbeat/mb/module"
+	"github.com/elastic/beats/metricbeat/module/postgresql"
+
+	// Register postgresql database/sql driver
+	_ "github.com/lib/pq"
+)
+
+// statementsQuery returns the query of the statistics of the statements.
+// Statements are sorted by total time, so the most expensive ones are reported
+// when a limit is set. Query texts are truncated by the server to the maximum
+// length, so long queries are not transferred.
+func statementsQuery(limit, queryMaxLength int) string {
+	queryText := "s.query"
+	if queryMaxLength > 0 {
+		queryText = fmt.Sprintf("left(s.query, %d) AS query", queryMaxLength)
+	}
+
+	query := `SELECT s.userid, s.dbid, s.queryid, ` + queryText + `,
+		s.calls, s.total_time, s.min_time, s.max_time, s.mean_time, s.stddev_time, s.rows,
+		s.shared_blks_hit, s.shared_blks_read, s.shared_blks_dirtied, s.shared_blks_written,
+		s.local_blks_hit, s.local_blks_read, s.local_blks_dirtied, s.local_blks_written,
+		s.temp_blks_read, s.temp_blks_written,
+		d.datname, r.rolname
+	FROM pg_stat_statements s
+	LEFT JOIN pg_database d ON d.oid = s.dbid
+	LEFT JOIN pg_roles r ON r.oid = s.userid
+	ORDER BY s.total_time DESC`
+	if limit > 0 {
+		query += fmt.Sprintf(" LIMIT %d", limit)
+	}
+	return query
+}
+
+// init registers the MetricSet with the central registry.
+// The New method will be called after the setup of the module and before starting to fetch data
+func init() {
+	if err := mb.Registry.AddMetricSet("postgresql", "statement", New, postgresql.ParseURL); err != nil {
+		panic(err)
+	}
+}
+
+// MetricSet type defines all fields of the MetricSet
+type MetricSet struct {
+	mb.BaseMetricSet
+	query string
+	rates *module.Rates
+}
+
+// New create a new instance of the postgresql statement MetricSet.
+func New(base mb.BaseMetricSet) (mb.MetricSet, error) {
+	cfgwarn.Experimental("The postgresql statement metricset is experimental")
+
+	config := struct {
+		Limit          int `config:"statement.limit" validate:"min=0"`
+		QueryMaxLength int `config:"statement.query_max_length" validate:"min=0"`
+	}{
+		Limit:          100,
+		QueryMaxLength: 1024,
+	}
+	if err := base.Module().UnpackConfig(&config); err != nil {
+		return nil, err
+	}
+
+	return &MetricSet{
+		BaseMetricSet: base,
+		query:         statementsQuery(config.Limit, config.QueryMaxLength),
+		rates:         module.NewRates(base.Module().Config().Period),
+	}, nil
+}
+
+// Fetch methods implements the data gathering and data conversion to the right format
+func (m *MetricSet) Fetch() ([]common.MapStr, error) {
+	db, err := sql.Open("postgres", m.HostData().URI)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	results, err := postgresql.QueryStats(db, m.query)
+	if err != nil {
+		return nil, errors.Wrap(err, "QueryStats")
+	}
+
+	events, err := eventsMapping(results)
+	addRates(m.rates, events, time.Now())
+	return events, err
+}
@@ -0,0 +1,88 @@
+// +build integration
+
+package statement
+
+import (
+	"testing"
+
+	"github.com/elastic/beats/libbeat/common"
+	"github.com/elastic/beats/libbeat/tests/compose"
+	mbtest "github.com/elastic/beats/metricbeat/mb/testing"
+	"github.com/elastic/beats/metricbeat/module/postgresql"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFetch(t *testing.T) {
+	compose.EnsureUp(t, "postgresql")
+
+	f := mbtest.NewEventsFetcher(t, getConfig())
+	events, err := f.Fetch()
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+
+	assert.True(t, len(events) > 0)
+	assert.True(t, len(events) <= 10)
+	event := events[0]
+
+	t.Logf("%s/%s event: %+v", f.Module().Name(), f.Name(), event)
+
+	assert.Contains(t, event, "user")
+	assert.Contains(t, event["user"].(common.MapStr), "id")
+
+	assert.Contains(t, event, "database")
+	assert.Contains(t, event["database"].(common.MapStr), "oid")
+
+	assert.Contains(t, event, "query")
+	query := event["query"].(common.MapStr)
+	assert.Contains(t, query, "id")
+	assert.Contains(t, query, "calls")
+	assert.Contains(t, query, "rows")
+	assert.True(t, len(query["text"].(string)) <= 100)
+
+	time := query["time"].(common.MapStr)
+	assert.Contains(t, time, "total")
+
+	memory := query["memory"].(common.MapStr)
+	assert.Contains(t, memory, "shared")
+	assert.Contains(t, memory, "local")
+	assert.Contains(t, memory, "temp")
+
+	// Rates are reported from the second fetch on, at least the query of the
+	// metricset itself is called between fetches.
+	events, err = f.Fetch()
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	hasRates := false
+	for _, event := range events {
+		if _, err := event.GetValue("query.rate.calls"); err == nil {
+			hasRates = true
+		}
+	}
+	assert.True(t, hasRates)
+}
+
+func TestData(t *testing.T) {
+	compose.EnsureUp(t, "postgresql")
+
+	f := mbtest.NewEventsFetcher(t, getConfig())
+
+	err := mbtest.WriteEvents(f, t)
+	if err != nil {
+		t.Fatal("write", err)
+	}
+}
+
+func getConfig() map[string]interface{} {
+	return map[string]interface{}{
+		"module":                     "postgresql",
+		"metricsets":                 []string{"statement"},
+		"hosts":                      []string{postgresql.GetEnvDSN()},
+		"username":                   postgresql.GetEnvUsername(),
+		"password":                   postgresql.GetEnvPassword(),
+		"statement.limit":            10,
+		"statement.query_max_length": 100,
+	}
+}
@@ -60,30 +60,9 @@ func (m *MetricSet) Fetch() ([]common.MapStr, error) {
 		return nil, fmt.Errorf("Unable to decode response from prometheus endpoint")
 	}
 
-	eventList := map[string]common.MapStr{}
-
-	for _, family := range families {
-		promEvents := GetPromEventsFromMetricFamily(family)
-
-		for _, promEvent := range promEvents {
-			if _, ok := eventList[promEvent.labelHash]; !ok {
-				eventList[promEvent.labelHash] = common.MapStr{}
-
-				// Add labels
-				if len(promEvent.labels) > 0 {
-					eventList[promEvent.labelHash]["label"] = promEvent.labels
-				}
-			}
-
-			eventList[promEvent.labelHash][promEvent.key] = promEvent.value
-		}
-	}
-
-	// Converts hash list to slice
-	events := []common.MapStr{}
-	for _, e := range eventList {
+	events := GetEventsFromMetricFamilies(families)
+	for _, e := range events {
 		e[mb.NamespaceKey] = m.namespace
-		events = append(events, e)
 	}
 
 	return events, err
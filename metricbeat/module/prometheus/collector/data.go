@@ -16,6 +16,36 @@ type PromEvent struct {
 	labelHash string
 }
 
+// GetEventsFromMetricFamilies converts the metric families to events. Metrics
+// with the same labels are reported in the same event.
+func GetEventsFromMetricFamilies(families []*dto.MetricFamily) []common.MapStr {
+	eventList := map[string]common.MapStr{}
+
+	for _, family := range families {
+		promEvents := GetPromEventsFromMetricFamily(family)
+
+		for _, promEvent := range promEvents {
+			if _, ok := eventList[promEvent.labelHash]; !ok {
+				eventList[promEvent.labelHash] = common.MapStr{}
+
+				// Add labels
+				if len(promEvent.labels) > 0 {
+					eventList[promEvent.labelHash]["label"] = promEvent.labels
+				}
+			}
+
+			eventList[promEvent.labelHash][promEvent.key] = promEvent.value
+		}
+	}
+
+	// Converts hash list to slice
+	events := []common.MapStr{}
+	for _, e := range eventList {
+		events = append(events, e)
+	}
+	return events
+}
+
 func GetPromEventsFromMetricFamily(mf *dto.MetricFamily) []PromEvent {
 	var events []PromEvent
 
@@ -0,0 +1,101 @@
+package remote_write
+
+import (
+	"math"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/snappy"
+	"github.com/pkg/errors"
+
+	"github.com/elastic/beats/libbeat/common"
+	"github.com/elastic/beats/metricbeat/module/prometheus/collector"
+
+	dto "github.com/prometheus/client_model/go"
+)
+
+// nameLabel is the label containing the name of the metric.
+const nameLabel = "__name__"
+
+// DecodeWriteRequest decodes the snappy compressed protobuf body of a remote
+// write request.
+func DecodeWriteRequest(body []byte) (*WriteRequest, error) {
+	data, err := snappy.Decode(nil, body)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decompress request")
+	}
+
+	req := &WriteRequest{}
+	if err := proto.Unmarshal(data, req); err != nil {
+		return nil, errors.Wrap(err, "failed to decode request")
+	}
+	return req, nil
+}
+
+// GetEventsFromWriteRequest converts the series of the request to events.
+// Samples are reported as gauges, as remote write doesn't include the type of
+// the metrics. Metrics with the same labels and timestamp are reported in the
+// same event. Stale markers and other NaN samples are ignored.
+func GetEventsFromWriteRequest(req *WriteRequest) []common.MapStr {
+	// Metric families by name, by timestamp of the samples.
+	families := map[int64]map[string]*dto.MetricFamily{}
+
+	for _, series := range req.Timeseries {
+		name := ""
+		var labels []*dto.LabelPair
+		for _, label := range series.Labels {
+			if label.Name == nameLabel {
+				name = label.Value
+				continue
+			}
+			labels = append(labels, &dto.LabelPair{
+				Name:  proto.String(label.Name),
+				Value: proto.String(label.Value),
+			})
+		}
+		if name == "" {
+			continue
+		}
+
+		for _, sample := range series.Samples {
+			if math.IsNaN(sample.Value) {
+				continue
+			}
+
+			byName, found := families[sample.Timestamp]
+			if !found {
+				byName = map[string]*dto.MetricFamily{}
+				families[sample.Timestamp] = byName
+			}
+
+			family, found := byName[name]
+			if !found {
+				family = &dto.MetricFamily{
+					Name: proto.String(name),
+					Type: dto.MetricType_GAUGE.Enum(),
+				}
+				byName[name] = family
+			}
+
+			family.Metric = append(family.Metric, &dto.Metric{
+				Label: labels,
+				Gauge: &dto.Gauge{Value: proto.Float64(sample.Value)},
+			})
+		}
+	}
+
+	var events []common.MapStr
+	for timestamp, byName := range families {
+		list := make([]*dto.MetricFamily, 0, len(byName))
+		for _, family := range byName {
+			list = append(list, family)
+		}
+
+		ts := common.Time(time.Unix(0, timestamp*int64(time.Millisecond)).UTC())
+		for _, event := range collector.GetEventsFromMetricFamilies(list) {
+			event["@timestamp"] = ts
+			events = append(events, event)
+		}
+	}
+	return events
+}
@@ -0,0 +1,109 @@
+// +build !integration
+
+package remote_write
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/snappy"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/elastic/beats/libbeat/common"
+)
+
+func testWriteRequest() *WriteRequest {
+	return &WriteRequest{
+		Timeseries: []*TimeSeries{
+			{
+				Labels: []*Label{
+					{Name: "__name__", Value: "http_requests_total"},
+					{Name: "job", Value: "api"},
+				},
+				Samples: []*Sample{
+					{Value: 10, Timestamp: 1500000000000},
+					{Value: 12, Timestamp: 1500000015000},
+				},
+			},
+			{
+				Labels: []*Label{
+					{Name: "__name__", Value: "up"},
+					{Name: "job", Value: "api"},
+				},
+				Samples: []*Sample{
+					{Value: 1, Timestamp: 1500000000000},
+					{Value: math.NaN(), Timestamp: 1500000015000},
+				},
+			},
+			{
+				Labels: []*Label{
+					{Name: "__name__", Value: "up"},
+				},
+				Samples: []*Sample{
+					{Value: 0, Timestamp: 1500000000000},
+				},
+			},
+			{
+				// Series without name are ignored.
+				Labels:  []*Label{{Name: "job", Value: "api"}},
+				Samples: []*Sample{{Value: 3, Timestamp: 1500000000000}},
+			},
+		},
+	}
+}
+
+func encodeWriteRequest(t testing.TB, req *WriteRequest) []byte {
+	data, err := proto.Marshal(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return snappy.Encode(nil, data)
+}
+
+func TestDecodeWriteRequest(t *testing.T) {
+	req, err := DecodeWriteRequest(encodeWriteRequest(t, testWriteRequest()))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	if assert.Len(t, req.Timeseries, 4) {
+		assert.Equal(t, "http_requests_total", req.Timeseries[0].Labels[0].Value)
+		assert.Equal(t, int64(1500000015000), req.Timeseries[0].Samples[1].Timestamp)
+	}
+
+	_, err = DecodeWriteRequest([]byte("not snappy"))
+	assert.Error(t, err)
+}
+
+func TestGetEventsFromWriteRequest(t *testing.T) {
+	events := GetEventsFromWriteRequest(testWriteRequest())
+	if !assert.Len(t, events, 3) {
+		return
+	}
+
+	first := common.Time(time.Unix(1500000000, 0).UTC())
+	second := common.Time(time.Unix(1500000015, 0).UTC())
+
+	expected := []common.MapStr{
+		{
+			"@timestamp":          first,
+			"label":               common.MapStr{"job": "api"},
+			"http_requests_total": common.MapStr{"value": 10.0},
+			"up":                  common.MapStr{"value": 1.0},
+		},
+		{
+			"@timestamp": first,
+			"up":         common.MapStr{"value": 0.0},
+		},
+		{
+			"@timestamp":          second,
+			"label":               common.MapStr{"job": "api"},
+			"http_requests_total": common.MapStr{"value": 12.0},
+		},
+	}
+	for _, e := range expected {
+		assert.Contains(t, events, e)
+	}
+}
@@ -0,0 +1,71 @@
+package remote_write
+
+import (
+	"github.com/elastic/beats/libbeat/common/cfgwarn"
+	serverhelper "github.com/elastic/beats/metricbeat/helper/server"
+	"github.com/elastic/beats/metricbeat/helper/server/http"
+	"github.com/elastic/beats/metricbeat/mb"
+)
+
+// init registers the MetricSet with the central registry.
+// The New method will be called after the setup of the module and before starting to fetch data
+func init() {
+	if err := mb.Registry.AddMetricSet("prometheus", "remote_write", New); err != nil {
+		panic(err)
+	}
+}
+
+// MetricSet receives the metrics sent by Prometheus remote write.
+type MetricSet struct {
+	mb.BaseMetricSet
+	server serverhelper.Server
+}
+
+// New create a new instance of the MetricSet
+// Part of new is also setting up the configuration by processing additional
+// configuration entries if needed.
+func New(base mb.BaseMetricSet) (mb.MetricSet, error) {
+	cfgwarn.Experimental("The prometheus remote_write metricset is experimental")
+
+	svc, err := http.NewHttpServerWithConfig(base, http.HttpConfig{
+		Host: "localhost",
+		Port: 9201,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &MetricSet{
+		BaseMetricSet: base,
+		server:        svc,
+	}, nil
+}
+
+// Run decodes the write requests received and reports their samples.
+func (m *MetricSet) Run(reporter mb.PushReporter) {
+	// Start event watcher
+	m.server.Start()
+
+	for {
+		select {
+		case <-reporter.Done():
+			m.server.Stop()
+			return
+		case msg := <-m.server.GetEvents():
+			body, ok := msg.GetEvent()[serverhelper.EventDataKey].([]byte)
+			if !ok {
+				continue
+			}
+
+			req, err := DecodeWriteRequest(body)
+			if err != nil {
+				reporter.Error(err)
+				continue
+			}
+
+			for _, event := range GetEventsFromWriteRequest(req) {
+				reporter.Event(event)
+			}
+		}
+	}
+}
@@ -0,0 +1,47 @@
+// +build !integration
+
+package remote_write
+
+import (
+	"bytes"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/elastic/beats/libbeat/common"
+	mbtest "github.com/elastic/beats/metricbeat/mb/testing"
+)
+
+func TestRun(t *testing.T) {
+	ms := mbtest.NewPushMetricSet(t, map[string]interface{}{
+		"module":     "prometheus",
+		"metricsets": []string{"remote_write"},
+		"host":       "127.0.0.1",
+		"port":       19201,
+	})
+
+	body := encodeWriteRequest(t, testWriteRequest())
+	go func() {
+		// Wait for the server to be listening.
+		time.Sleep(100 * time.Millisecond)
+
+		for _, data := range [][]byte{body, []byte("invalid")} {
+			resp, err := http.Post("http://127.0.0.1:19201/write", "application/x-protobuf", bytes.NewReader(data))
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			resp.Body.Close()
+		}
+	}()
+
+	events, errs := mbtest.RunPushMetricSet(500*time.Millisecond, ms)
+	assert.Len(t, errs, 1)
+	if assert.Len(t, events, 3) {
+		for _, event := range events {
+			assert.IsType(t, common.Time{}, event["@timestamp"])
+		}
+	}
+}
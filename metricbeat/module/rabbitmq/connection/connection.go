@@ -0,0 +1,64 @@
+package connection
+
+import (
+	"github.com/elastic/beats/libbeat/common"
+	"github.com/elastic/beats/libbeat/common/cfgwarn"
+	"github.com/elastic/beats/metricbeat/helper"
+	"github.com/elastic/beats/metricbeat/mb"
+	"github.com/elastic/beats/metricbeat/mb/parse"
+	"github.com/elastic/beats/metricbeat/module/rabbitmq"
+)
+
+const (
+	defaultScheme = "http"
+	defaultPath   = "/api/connections"
+)
+
+var (
+	hostParser = parse.URLHostParserBuilder{
+		DefaultScheme: defaultScheme,
+		DefaultPath:   defaultPath,
+	}.Build()
+)
+
+func init() {
+	if err := mb.Registry.AddMetricSet("rabbitmq", "connection", New, hostParser); err != nil {
+		panic(err)
+	}
+}
+
+// MetricSet for fetching RabbitMQ connections.
+type MetricSet struct {
+	mb.BaseMetricSet
+	*helper.HTTP
+	vhosts rabbitmq.VHostFilter
+}
+
+// New creates a new instance of the connection MetricSet.
+func New(base mb.BaseMetricSet) (mb.MetricSet, error) {
+	cfgwarn.Experimental("The rabbitmq connection metricset is experimental")
+
+	vhosts, err := rabbitmq.NewVHostFilter(base)
+	if err != nil {
+		return nil, err
+	}
+
+	http := helper.NewHTTP(base)
+	http.SetHeader("Accept", "application/json")
+
+	return &MetricSet{
+		base,
+		http,
+		vhosts,
+	}, nil
+}
+
+// Fetch returns an event for each connection open in the RabbitMQ server.
+func (m *MetricSet) Fetch() ([]common.MapStr, error) {
+	content, err := m.HTTP.FetchContent()
+	if err != nil {
+		return nil, err
+	}
+
+	return eventsMapping(content, m.vhosts)
+}
@@ -0,0 +1,123 @@
+// +build !integration
+
+package connection
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/elastic/beats/libbeat/common"
+	mbtest "github.com/elastic/beats/metricbeat/mb/testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFetchEventContents(t *testing.T) {
+	server := testServer(t)
+	defer server.Close()
+
+	config := map[string]interface{}{
+		"module":     "rabbitmq",
+		"metricsets": []string{"connection"},
+		"hosts":      []string{server.URL},
+	}
+
+	f := mbtest.NewEventsFetcher(t, config)
+	events, err := f.Fetch()
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	if !assert.Len(t, events, 2) {
+		t.FailNow()
+	}
+
+	event := events[0]
+	t.Logf("%s/%s event: %+v", f.Module().Name(), f.Name(), event.StringToPrint())
+
+	assert.EqualValues(t, "[::1]:60938 -> [::1]:5672", event["name"])
+	assert.EqualValues(t, "/", event["vhost"])
+	assert.EqualValues(t, "guest", event["user"])
+	assert.EqualValues(t, "rabbit@e2b1ae6390fd", event["node"])
+	assert.EqualValues(t, "running", event["state"])
+	assert.EqualValues(t, "network", event["type"])
+	assert.EqualValues(t, "AMQP 0-9-1", event["protocol"])
+	assert.EqualValues(t, 8, event["channels"])
+	assert.EqualValues(t, 65535, event["channel_max"])
+	assert.EqualValues(t, 131072, event["frame_max"])
+	assert.EqualValues(t, "::1", event["host"])
+	assert.EqualValues(t, 5672, event["port"])
+
+	peer := event["peer"].(common.MapStr)
+	assert.EqualValues(t, "::1", peer["host"])
+	assert.EqualValues(t, 60938, peer["port"])
+
+	octets := event["octet_count"].(common.MapStr)
+	assert.EqualValues(t, 3324, octets["received"])
+	assert.EqualValues(t, 9745, octets["sent"])
+
+	packets := event["packet_count"].(common.MapStr)
+	assert.EqualValues(t, 376, packets["received"])
+	assert.EqualValues(t, 376, packets["sent"])
+	assert.EqualValues(t, 0, packets["pending"])
+
+	client := event["client_properties"].(common.MapStr)
+	assert.EqualValues(t, "https://github.com/streadway/amqp", client["product"])
+	assert.EqualValues(t, "β", client["version"])
+	assert.NotContains(t, client, "platform")
+	assert.NotContains(t, client, "connection_name")
+
+	event = events[1]
+	assert.EqualValues(t, "orders", event["vhost"])
+	assert.EqualValues(t, "blocked", event["state"])
+
+	client = event["client_properties"].(common.MapStr)
+	assert.EqualValues(t, "Java", client["platform"])
+	assert.EqualValues(t, "orders-service", client["connection_name"])
+}
+
+func TestFetchVHostFilter(t *testing.T) {
+	server := testServer(t)
+	defer server.Close()
+
+	config := map[string]interface{}{
+		"module":     "rabbitmq",
+		"metricsets": []string{"connection"},
+		"hosts":      []string{server.URL},
+		"vhosts":     []string{"orders"},
+	}
+
+	f := mbtest.NewEventsFetcher(t, config)
+	events, err := f.Fetch()
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	if assert.Len(t, events, 1) {
+		assert.EqualValues(t, "orders", events[0]["vhost"])
+		assert.EqualValues(t, "172.17.0.1:41254 -> 172.17.0.2:5672", events[0]["name"])
+	}
+}
+
+func testServer(t *testing.T) *httptest.Server {
+	absPath, err := filepath.Abs("../_meta/testdata/")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	response, err := ioutil.ReadFile(absPath + "/connection_sample_response.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/connections" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json;")
+		w.WriteHeader(200)
+		w.Write(response)
+	}))
+}
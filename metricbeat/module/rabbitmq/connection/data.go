@@ -0,0 +1,76 @@
+package connection
+
+import (
+	"encoding/json"
+
+	"github.com/elastic/beats/libbeat/common"
+	s "github.com/elastic/beats/libbeat/common/schema"
+	c "github.com/elastic/beats/libbeat/common/schema/mapstriface"
+	"github.com/elastic/beats/metricbeat/module/rabbitmq"
+)
+
+var (
+	schema = s.Schema{
+		"name":        c.Str("name"),
+		"vhost":       c.Str("vhost"),
+		"user":        c.Str("user"),
+		"node":        c.Str("node"),
+		"state":       c.Str("state"),
+		"type":        c.Str("type"),
+		"protocol":    c.Str("protocol", s.Optional),
+		"channels":    c.Int("channels"),
+		"channel_max": c.Int("channel_max", s.Optional),
+		"frame_max":   c.Int("frame_max", s.Optional),
+		"host":        c.Str("host", s.Optional),
+		"port":        c.Int("port", s.Optional),
+		"peer": s.Object{
+			"host": c.Str("peer_host", s.Optional),
+			"port": c.Int("peer_port", s.Optional),
+		},
+		"octet_count": s.Object{
+			"received": c.Int("recv_oct", s.Optional),
+			"sent":     c.Int("send_oct", s.Optional),
+		},
+		"packet_count": s.Object{
+			"received": c.Int("recv_cnt", s.Optional),
+			"sent":     c.Int("send_cnt", s.Optional),
+			"pending":  c.Int("send_pend", s.Optional),
+		},
+		"client_properties": c.Dict("client_properties", s.Schema{
+			"product":         c.Str("product", s.Optional),
+			"platform":        c.Str("platform", s.Optional),
+			"version":         c.Str("version", s.Optional),
+			"connection_name": c.Str("connection_name", s.Optional),
+		}, c.DictOptional),
+	}
+)
+
+func eventsMapping(content []byte, vhosts rabbitmq.VHostFilter) ([]common.MapStr, error) {
+	var connections []map[string]interface{}
+	err := json.Unmarshal(content, &connections)
+	if err != nil {
+		return nil, err
+	}
+
+	events := []common.MapStr{}
+	errors := s.NewErrors()
+
+	for _, connection := range connections {
+		if vhost, _ := connection["vhost"].(string); !vhosts.Match(vhost) {
+			continue
+		}
+
+		event, errs := eventMapping(connection)
+		events = append(events, event)
+		errors.AddErrors(errs)
+	}
+
+	if errors.HasRequiredErrors() {
+		return events, errors
+	}
+	return events, nil
+}
+
+func eventMapping(connection map[string]interface{}) (common.MapStr, *s.Errors) {
+	return schema.Apply(connection)
+}
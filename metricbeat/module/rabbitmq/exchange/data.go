@@ -0,0 +1,67 @@
+package exchange
+
+import (
+	"encoding/json"
+
+	"github.com/elastic/beats/libbeat/common"
+	s "github.com/elastic/beats/libbeat/common/schema"
+	c "github.com/elastic/beats/libbeat/common/schema/mapstriface"
+	"github.com/elastic/beats/metricbeat/module/rabbitmq"
+)
+
+var (
+	schema = s.Schema{
+		"name":        c.Str("name"),
+		"vhost":       c.Str("vhost"),
+		"type":        c.Str("type"),
+		"durable":     c.Bool("durable"),
+		"auto_delete": c.Bool("auto_delete"),
+		"internal":    c.Bool("internal"),
+		"user":        c.Str("user_who_performed_action", s.Optional),
+		"messages": c.Dict("message_stats", s.Schema{
+			"publish_in":  rateCounter("publish_in"),
+			"publish_out": rateCounter("publish_out"),
+		}, c.DictOptional),
+	}
+)
+
+// rateCounter maps a message stats counter of the management API together
+// with its rate details.
+func rateCounter(key string) s.Object {
+	return s.Object{
+		"count": c.Int(key, s.Optional),
+		"details": c.Dict(key+"_details", s.Schema{
+			"rate": c.Float("rate"),
+		}, c.DictOptional),
+	}
+}
+
+func eventsMapping(content []byte, vhosts rabbitmq.VHostFilter) ([]common.MapStr, error) {
+	var exchanges []map[string]interface{}
+	err := json.Unmarshal(content, &exchanges)
+	if err != nil {
+		return nil, err
+	}
+
+	events := []common.MapStr{}
+	errors := s.NewErrors()
+
+	for _, exchange := range exchanges {
+		if vhost, _ := exchange["vhost"].(string); !vhosts.Match(vhost) {
+			continue
+		}
+
+		event, errs := eventMapping(exchange)
+		events = append(events, event)
+		errors.AddErrors(errs)
+	}
+
+	if errors.HasRequiredErrors() {
+		return events, errors
+	}
+	return events, nil
+}
+
+func eventMapping(exchange map[string]interface{}) (common.MapStr, *s.Errors) {
+	return schema.Apply(exchange)
+}
@@ -0,0 +1,107 @@
+// +build !integration
+
+package exchange
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/elastic/beats/libbeat/common"
+	mbtest "github.com/elastic/beats/metricbeat/mb/testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFetchEventContents(t *testing.T) {
+	server := testServer(t)
+	defer server.Close()
+
+	config := map[string]interface{}{
+		"module":     "rabbitmq",
+		"metricsets": []string{"exchange"},
+		"hosts":      []string{server.URL},
+	}
+
+	f := mbtest.NewEventsFetcher(t, config)
+	events, err := f.Fetch()
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	if !assert.Len(t, events, 3) {
+		t.FailNow()
+	}
+
+	// The default exchange has no message stats.
+	event := events[0]
+	assert.EqualValues(t, "", event["name"])
+	assert.EqualValues(t, "direct", event["type"])
+	assert.NotContains(t, event, "messages")
+
+	event = events[1]
+	t.Logf("%s/%s event: %+v", f.Module().Name(), f.Name(), event.StringToPrint())
+
+	assert.EqualValues(t, "exchange.metricbeat", event["name"])
+	assert.EqualValues(t, "/", event["vhost"])
+	assert.EqualValues(t, "fanout", event["type"])
+	assert.EqualValues(t, true, event["durable"])
+	assert.EqualValues(t, false, event["auto_delete"])
+	assert.EqualValues(t, false, event["internal"])
+	assert.EqualValues(t, "guest", event["user"])
+
+	messages := event["messages"].(common.MapStr)
+	publishIn := messages["publish_in"].(common.MapStr)
+	publishInDetails := publishIn["details"].(common.MapStr)
+	publishOut := messages["publish_out"].(common.MapStr)
+	publishOutDetails := publishOut["details"].(common.MapStr)
+	assert.EqualValues(t, 100, publishIn["count"])
+	assert.EqualValues(t, 0.8, publishInDetails["rate"])
+	assert.EqualValues(t, 99, publishOut["count"])
+	assert.EqualValues(t, 0.4, publishOutDetails["rate"])
+}
+
+func TestFetchVHostFilter(t *testing.T) {
+	server := testServer(t)
+	defer server.Close()
+
+	config := map[string]interface{}{
+		"module":     "rabbitmq",
+		"metricsets": []string{"exchange"},
+		"hosts":      []string{server.URL},
+		"vhosts":     []string{"orders"},
+	}
+
+	f := mbtest.NewEventsFetcher(t, config)
+	events, err := f.Fetch()
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	if assert.Len(t, events, 1) {
+		assert.EqualValues(t, "orders.events", events[0]["name"])
+		assert.EqualValues(t, "orders", events[0]["vhost"])
+	}
+}
+
+func testServer(t *testing.T) *httptest.Server {
+	absPath, err := filepath.Abs("../_meta/testdata/")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	response, err := ioutil.ReadFile(absPath + "/exchange_sample_response.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/exchanges" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json;")
+		w.WriteHeader(200)
+		w.Write(response)
+	}))
+}
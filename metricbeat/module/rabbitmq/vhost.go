@@ -0,0 +1,35 @@
+package rabbitmq
+
+import (
+	"github.com/elastic/beats/metricbeat/mb"
+)
+
+// VHostFilter selects the virtual hosts whose objects are reported. An empty
+// filter matches every virtual host.
+type VHostFilter map[string]struct{}
+
+// NewVHostFilter builds a VHostFilter from the `vhosts` setting of the
+// module configuration.
+func NewVHostFilter(base mb.BaseMetricSet) (VHostFilter, error) {
+	config := struct {
+		VHosts []string `config:"vhosts"`
+	}{}
+	if err := base.Module().UnpackConfig(&config); err != nil {
+		return nil, err
+	}
+
+	filter := VHostFilter{}
+	for _, vhost := range config.VHosts {
+		filter[vhost] = struct{}{}
+	}
+	return filter, nil
+}
+
+// Match returns true if objects of the given virtual host have to be reported.
+func (f VHostFilter) Match(vhost string) bool {
+	if len(f) == 0 {
+		return true
+	}
+	_, found := f[vhost]
+	return found
+}
@@ -0,0 +1,183 @@
+package key
+
+import (
+	"fmt"
+
+	"github.com/elastic/beats/libbeat/common"
+	"github.com/elastic/beats/libbeat/common/cfgwarn"
+	"github.com/elastic/beats/libbeat/logp"
+	"github.com/elastic/beats/metricbeat/mb"
+	"github.com/elastic/beats/metricbeat/mb/parse"
+	"github.com/elastic/beats/metricbeat/module/redis"
+
+	rd "github.com/garyburd/redigo/redis"
+)
+
+var (
+	debugf = logp.MakeDebug("redis-key")
+)
+
+func init() {
+	if err := mb.Registry.AddMetricSet("redis", "key", New, parse.PassThruHostParser); err != nil {
+		panic(err)
+	}
+}
+
+// MetricSet for fetching information about Redis keys.
+type MetricSet struct {
+	*redis.MetricSet
+	patterns []KeyPattern
+}
+
+// KeyPattern configures the keys to be monitored.
+type KeyPattern struct {
+	Keyspace uint   `config:"keyspace"`
+	Pattern  string `config:"pattern" validate:"required"`
+	Limit    uint   `config:"limit"`
+}
+
+// New creates new instance of MetricSet
+func New(base mb.BaseMetricSet) (mb.MetricSet, error) {
+	cfgwarn.Experimental("The redis key metricset is experimental")
+
+	config := struct {
+		KeyPatterns []KeyPattern `config:"key.patterns" validate:"required"`
+	}{}
+	err := base.Module().UnpackConfig(&config)
+	if err != nil {
+		return nil, err
+	}
+
+	ms, err := redis.NewMetricSet(base)
+	if err != nil {
+		return nil, err
+	}
+
+	return &MetricSet{
+		MetricSet: ms,
+		patterns:  config.KeyPatterns,
+	}, nil
+}
+
+// Fetch returns an event for each key matching the configured patterns.
+func (m *MetricSet) Fetch() ([]common.MapStr, error) {
+	conn := m.Connection()
+	defer conn.Close()
+
+	events := []common.MapStr{}
+	for _, p := range m.patterns {
+		if err := selectKeyspace(conn, p.Keyspace); err != nil {
+			return events, err
+		}
+
+		keys, err := fetchKeys(conn, p.Pattern, p.Limit)
+		if err != nil {
+			return events, err
+		}
+		debugf("Pattern '%s' matches %d keys in keyspace %d", p.Pattern, len(keys), p.Keyspace)
+
+		for _, key := range keys {
+			event, err := fetchKeyInfo(conn, key)
+			if err != nil {
+				return events, err
+			}
+			if event == nil {
+				// The key expired or was removed after the scan.
+				continue
+			}
+			event["id"] = fmt.Sprintf("db%d:%s", p.Keyspace, key)
+			event["keyspace"] = fmt.Sprintf("db%d", p.Keyspace)
+			events = append(events, event)
+		}
+	}
+
+	return events, nil
+}
+
+// selectKeyspace changes the keyspace used by the connection.
+func selectKeyspace(c rd.Conn, keyspace uint) error {
+	_, err := c.Do("SELECT", keyspace)
+	if err != nil {
+		return fmt.Errorf("error selecting keyspace %d: %v", keyspace, err)
+	}
+	return nil
+}
+
+// fetchKeys iterates over the keys matching the pattern with SCAN, it stops
+// once limit keys are found if limit is greater than zero.
+func fetchKeys(c rd.Conn, pattern string, limit uint) ([]string, error) {
+	keys := []string{}
+	cursor := 0
+	for {
+		values, err := rd.Values(c.Do("SCAN", cursor, "MATCH", pattern))
+		if err != nil {
+			return nil, fmt.Errorf("error scanning keys matching '%s': %v", pattern, err)
+		}
+
+		var page []string
+		_, err = rd.Scan(values, &cursor, &page)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing scan reply: %v", err)
+		}
+		keys = append(keys, page...)
+
+		if limit > 0 && uint(len(keys)) >= limit {
+			if uint(len(keys)) > limit || cursor != 0 {
+				debugf("Only the first %d keys matching '%s' are collected", limit, pattern)
+			}
+			return keys[:limit], nil
+		}
+		if cursor == 0 {
+			return keys, nil
+		}
+	}
+}
+
+// lengthCommands are the commands used to get the length of the keys of
+// each type.
+var lengthCommands = map[string]string{
+	"string": "STRLEN",
+	"list":   "LLEN",
+	"set":    "SCARD",
+	"zset":   "ZCARD",
+	"hash":   "HLEN",
+	"stream": "XLEN",
+}
+
+// fetchKeyInfo returns the type, length and TTL of a key. It returns nil if
+// the key doesn't exist.
+func fetchKeyInfo(c rd.Conn, key string) (common.MapStr, error) {
+	keyType, err := rd.String(c.Do("TYPE", key))
+	if err != nil {
+		return nil, fmt.Errorf("error getting type of key '%s': %v", key, err)
+	}
+	if keyType == "none" {
+		return nil, nil
+	}
+
+	ttl, err := rd.Int64(c.Do("TTL", key))
+	if err != nil {
+		return nil, fmt.Errorf("error getting TTL of key '%s': %v", key, err)
+	}
+	if ttl == -2 {
+		return nil, nil
+	}
+
+	event := common.MapStr{
+		"name": key,
+		"type": keyType,
+		"expire": common.MapStr{
+			"ttl": ttl,
+		},
+	}
+
+	if command, found := lengthCommands[keyType]; found {
+		length, err := rd.Int64(c.Do(command, key))
+		if err != nil {
+			return nil, fmt.Errorf("error getting length of key '%s': %v", key, err)
+		}
+		event["length"] = length
+	}
+
+	return event, nil
+}
@@ -0,0 +1,83 @@
+// +build integration
+
+package key
+
+import (
+	"testing"
+
+	"github.com/elastic/beats/libbeat/common"
+	"github.com/elastic/beats/libbeat/tests/compose"
+	mbtest "github.com/elastic/beats/metricbeat/mb/testing"
+	"github.com/elastic/beats/metricbeat/module/redis"
+
+	rd "github.com/garyburd/redigo/redis"
+	"github.com/stretchr/testify/assert"
+)
+
+var host = redis.GetRedisEnvHost() + ":" + redis.GetRedisEnvPort()
+
+func TestFetch(t *testing.T) {
+	compose.EnsureUp(t, "redis")
+
+	addEntry(t)
+
+	f := mbtest.NewEventsFetcher(t, getConfig())
+	events, err := f.Fetch()
+	if err != nil {
+		t.Fatal("fetch", err)
+	}
+
+	t.Logf("%s/%s event: %+v", f.Module().Name(), f.Name(), events)
+
+	if assert.Len(t, events, 1) {
+		key := events[0]
+		assert.Equal(t, "db0:list-key", key["id"])
+		assert.Equal(t, "list-key", key["name"])
+		assert.Equal(t, "list", key["type"])
+		assert.Equal(t, int64(1), key["length"])
+		assert.Equal(t, int64(-1), key["expire"].(common.MapStr)["ttl"])
+	}
+}
+
+func TestData(t *testing.T) {
+	compose.EnsureUp(t, "redis")
+
+	addEntry(t)
+
+	f := mbtest.NewEventsFetcher(t, getConfig())
+
+	err := mbtest.WriteEvents(f, t)
+	if err != nil {
+		t.Fatal("write", err)
+	}
+}
+
+// addEntry adds a list to redis
+func addEntry(t *testing.T) {
+	c, err := rd.Dial("tcp", host)
+	if err != nil {
+		t.Fatal("connect", err)
+	}
+	defer c.Close()
+	_, err = c.Do("DEL", "list-key")
+	if err != nil {
+		t.Fatal("DEL", err)
+	}
+	_, err = c.Do("LPUSH", "list-key", "one")
+	if err != nil {
+		t.Fatal("LPUSH", err)
+	}
+}
+
+func getConfig() map[string]interface{} {
+	return map[string]interface{}{
+		"module":     "redis",
+		"metricsets": []string{"key"},
+		"hosts":      []string{host},
+		"key.patterns": []map[string]interface{}{
+			{
+				"pattern": "list-key",
+			},
+		},
+	}
+}
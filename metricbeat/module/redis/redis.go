@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/elastic/beats/libbeat/logp"
+	"github.com/elastic/beats/metricbeat/mb"
 
 	rd "github.com/garyburd/redigo/redis"
 )
@@ -75,3 +76,42 @@ func CreatePool(
 		},
 	}
 }
+
+// MetricSet is the base for the Redis metricsets, it holds the pool of
+// connections to the Redis server.
+type MetricSet struct {
+	mb.BaseMetricSet
+	pool *rd.Pool
+}
+
+// NewMetricSet unpacks the connection options of the module and creates the
+// base MetricSet with a connection pool for the host.
+func NewMetricSet(base mb.BaseMetricSet) (*MetricSet, error) {
+	// Unpack additional configuration options.
+	config := struct {
+		IdleTimeout time.Duration `config:"idle_timeout"`
+		Network     string        `config:"network"`
+		MaxConn     int           `config:"maxconn" validate:"min=1"`
+		Password    string        `config:"password"`
+	}{
+		Network:  "tcp",
+		MaxConn:  10,
+		Password: "",
+	}
+	err := base.Module().UnpackConfig(&config)
+	if err != nil {
+		return nil, err
+	}
+
+	return &MetricSet{
+		BaseMetricSet: base,
+		pool: CreatePool(base.Host(), config.Password, config.Network,
+			config.MaxConn, config.IdleTimeout, base.Module().Config().Timeout),
+	}, nil
+}
+
+// Connection returns a connection from the pool, it has to be closed by the
+// caller to return it to the pool.
+func (m *MetricSet) Connection() rd.Conn {
+	return m.pool.Get()
+}
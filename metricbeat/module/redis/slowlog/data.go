@@ -0,0 +1,121 @@
+package slowlog
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/elastic/beats/libbeat/common"
+
+	rd "github.com/garyburd/redigo/redis"
+)
+
+// entry is an entry of the slow log as returned by SLOWLOG GET.
+type entry struct {
+	id         int64
+	time       time.Time
+	duration   int64 // In microseconds.
+	args       []string
+	clientAddr string // Only available since Redis 4.0.
+	clientName string // Only available since Redis 4.0.
+}
+
+// parseEntries parses the reply of SLOWLOG GET, entries are sorted from the
+// newest to the oldest one.
+func parseEntries(reply []interface{}) ([]entry, error) {
+	entries := make([]entry, 0, len(reply))
+	for _, item := range reply {
+		values, err := rd.Values(item, nil)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing slow log entry: %v", err)
+		}
+
+		var e entry
+		var timestamp int64
+		rest, err := rd.Scan(values, &e.id, &timestamp, &e.duration, &e.args)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing slow log entry: %v", err)
+		}
+		e.time = time.Unix(timestamp, 0).UTC()
+
+		if len(rest) >= 2 {
+			if _, err := rd.Scan(rest, &e.clientAddr, &e.clientName); err != nil {
+				return nil, fmt.Errorf("error parsing slow log entry client: %v", err)
+			}
+		}
+
+		entries = append(entries, e)
+	}
+	return entries, nil
+}
+
+// tracker keeps the ID of the newest entry reported, so each entry of the
+// slow log is only reported once.
+type tracker struct {
+	lastID  int64
+	started bool
+}
+
+// newEntries returns the entries that haven't been reported yet, from the
+// oldest to the newest one. All entries are returned on the first call. IDs
+// are only reset when Redis restarts, so if the newest ID is lower than the
+// last one reported, all entries are considered new.
+func (t *tracker) newEntries(entries []entry) []entry {
+	if len(entries) == 0 {
+		return nil
+	}
+
+	previous := t.lastID
+	reportAll := !t.started || entries[0].id < previous
+	t.lastID = entries[0].id
+	t.started = true
+
+	var result []entry
+	for i := len(entries) - 1; i >= 0; i-- {
+		if reportAll || entries[i].id > previous {
+			result = append(result, entries[i])
+		}
+	}
+	return result
+}
+
+func eventsMapping(entries []entry) []common.MapStr {
+	events := make([]common.MapStr, 0, len(entries))
+	for _, e := range entries {
+		events = append(events, eventMapping(e))
+	}
+	return events
+}
+
+func eventMapping(e entry) common.MapStr {
+	event := common.MapStr{
+		"@timestamp": common.Time(e.time),
+		"id":         e.id,
+		"duration": common.MapStr{
+			"us": e.duration,
+		},
+	}
+
+	if len(e.args) > 0 {
+		event["cmd"] = strings.ToUpper(e.args[0])
+	}
+	if len(e.args) > 1 {
+		event["key"] = e.args[1]
+	}
+	if len(e.args) > 2 {
+		event["args"] = e.args[2:]
+	}
+
+	client := common.MapStr{}
+	if e.clientAddr != "" {
+		client["address"] = e.clientAddr
+	}
+	if e.clientName != "" {
+		client["name"] = e.clientName
+	}
+	if len(client) > 0 {
+		event["client"] = client
+	}
+
+	return event
+}
@@ -0,0 +1,102 @@
+// +build !integration
+
+package slowlog
+
+import (
+	"testing"
+	"time"
+
+	"github.com/elastic/beats/libbeat/common"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// slowlogReply builds an entry as it is returned by the redis client.
+func slowlogReply(id, timestamp, duration int64, args ...string) []interface{} {
+	values := make([]interface{}, len(args))
+	for i, arg := range args {
+		values[i] = []byte(arg)
+	}
+	return []interface{}{id, timestamp, duration, values}
+}
+
+func TestParseEntries(t *testing.T) {
+	reply := []interface{}{
+		append(slowlogReply(14, 1509716100, 12041, "zunionstore", "out", "2", "a", "b"),
+			[]byte("127.0.0.1:58344"), []byte("worker")),
+		slowlogReply(13, 1509716095, 10253, "keys", "*"),
+	}
+
+	entries, err := parseEntries(reply)
+	if !assert.NoError(t, err) || !assert.Len(t, entries, 2) {
+		t.FailNow()
+	}
+
+	assert.Equal(t, entry{
+		id:         14,
+		time:       time.Unix(1509716100, 0).UTC(),
+		duration:   12041,
+		args:       []string{"zunionstore", "out", "2", "a", "b"},
+		clientAddr: "127.0.0.1:58344",
+		clientName: "worker",
+	}, entries[0])
+
+	event := eventMapping(entries[0])
+	assert.Equal(t, common.MapStr{
+		"@timestamp": common.Time(time.Unix(1509716100, 0).UTC()),
+		"id":         int64(14),
+		"cmd":        "ZUNIONSTORE",
+		"key":        "out",
+		"args":       []string{"2", "a", "b"},
+		"duration":   common.MapStr{"us": int64(12041)},
+		"client":     common.MapStr{"address": "127.0.0.1:58344", "name": "worker"},
+	}, event)
+
+	event = eventMapping(entries[1])
+	assert.Equal(t, "KEYS", event["cmd"])
+	assert.Equal(t, "*", event["key"])
+	assert.NotContains(t, event, "args")
+	assert.NotContains(t, event, "client")
+}
+
+func TestParseEntriesInvalid(t *testing.T) {
+	_, err := parseEntries([]interface{}{[]interface{}{int64(1), int64(1509716100)}})
+	assert.Error(t, err)
+}
+
+func TestTrackerNewEntries(t *testing.T) {
+	ids := func(entries []entry) []int64 {
+		var result []int64
+		for _, e := range entries {
+			result = append(result, e.id)
+		}
+		return result
+	}
+	log := func(ids ...int64) []entry {
+		var entries []entry
+		for _, id := range ids {
+			entries = append(entries, entry{id: id})
+		}
+		return entries
+	}
+
+	tr := &tracker{}
+
+	assert.Empty(t, tr.newEntries(nil))
+
+	// All entries are reported the first time, from the oldest one.
+	assert.Equal(t, []int64{0, 1, 2}, ids(tr.newEntries(log(2, 1, 0))))
+
+	// Nothing new.
+	assert.Empty(t, tr.newEntries(log(2, 1, 0)))
+
+	// Only the new entries are reported.
+	assert.Equal(t, []int64{3, 4}, ids(tr.newEntries(log(4, 3, 2, 1))))
+
+	// The log was reset, nothing new was added.
+	assert.Empty(t, tr.newEntries(nil))
+	assert.Equal(t, []int64{5}, ids(tr.newEntries(log(5))))
+
+	// Redis restarted and IDs started again.
+	assert.Equal(t, []int64{0, 1}, ids(tr.newEntries(log(1, 0))))
+}
@@ -0,0 +1,66 @@
+package slowlog
+
+import (
+	"github.com/elastic/beats/libbeat/common"
+	"github.com/elastic/beats/libbeat/common/cfgwarn"
+	"github.com/elastic/beats/libbeat/logp"
+	"github.com/elastic/beats/metricbeat/mb"
+	"github.com/elastic/beats/metricbeat/mb/parse"
+	"github.com/elastic/beats/metricbeat/module/redis"
+
+	rd "github.com/garyburd/redigo/redis"
+)
+
+var (
+	debugf = logp.MakeDebug("redis-slowlog")
+)
+
+func init() {
+	if err := mb.Registry.AddMetricSet("redis", "slowlog", New, parse.PassThruHostParser); err != nil {
+		panic(err)
+	}
+}
+
+// MetricSet for fetching the entries of the Redis slow log.
+type MetricSet struct {
+	*redis.MetricSet
+	tracker *tracker
+}
+
+// New creates new instance of MetricSet
+func New(base mb.BaseMetricSet) (mb.MetricSet, error) {
+	cfgwarn.Experimental("The redis slowlog metricset is experimental")
+
+	ms, err := redis.NewMetricSet(base)
+	if err != nil {
+		return nil, err
+	}
+
+	return &MetricSet{
+		MetricSet: ms,
+		tracker:   &tracker{},
+	}, nil
+}
+
+// Fetch returns an event for each entry added to the slow log since the
+// previous fetch.
+func (m *MetricSet) Fetch() ([]common.MapStr, error) {
+	conn := m.Connection()
+	defer conn.Close()
+
+	// A negative count returns all the entries in the log.
+	reply, err := rd.Values(conn.Do("SLOWLOG", "GET", -1))
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := parseEntries(reply)
+	if err != nil {
+		return nil, err
+	}
+
+	entries = m.tracker.newEntries(entries)
+	debugf("%d new entries in the slow log of %s", len(entries), m.Host())
+
+	return eventsMapping(entries), nil
+}
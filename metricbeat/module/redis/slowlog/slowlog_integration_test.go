@@ -0,0 +1,93 @@
+// +build integration
+
+package slowlog
+
+import (
+	"testing"
+
+	"github.com/elastic/beats/libbeat/tests/compose"
+	mbtest "github.com/elastic/beats/metricbeat/mb/testing"
+	"github.com/elastic/beats/metricbeat/module/redis"
+
+	rd "github.com/garyburd/redigo/redis"
+	"github.com/stretchr/testify/assert"
+)
+
+var host = redis.GetRedisEnvHost() + ":" + redis.GetRedisEnvPort()
+
+func TestFetch(t *testing.T) {
+	compose.EnsureUp(t, "redis")
+
+	f := mbtest.NewEventsFetcher(t, getConfig())
+
+	addEntry(t)
+	events, err := f.Fetch()
+	if err != nil {
+		t.Fatal("fetch", err)
+	}
+
+	t.Logf("%s/%s event: %+v", f.Module().Name(), f.Name(), events)
+	if assert.NotEmpty(t, events) {
+		assert.Equal(t, "KEYS", events[len(events)-1]["cmd"])
+	}
+
+	// Only new entries are reported in following fetches.
+	addEntry(t)
+	events, err = f.Fetch()
+	if err != nil {
+		t.Fatal("fetch", err)
+	}
+	// The CONFIG SET command enabling the log is also logged.
+	if assert.Len(t, events, 2) {
+		assert.Equal(t, "CONFIG", events[0]["cmd"])
+		assert.Equal(t, "KEYS", events[1]["cmd"])
+		assert.Equal(t, "*", events[1]["key"])
+	}
+}
+
+func TestData(t *testing.T) {
+	compose.EnsureUp(t, "redis")
+
+	addEntry(t)
+
+	f := mbtest.NewEventsFetcher(t, getConfig())
+
+	err := mbtest.WriteEvents(f, t)
+	if err != nil {
+		t.Fatal("write", err)
+	}
+}
+
+// addEntry adds a command to the slow log, logging all commands while it runs
+func addEntry(t *testing.T) {
+	c, err := rd.Dial("tcp", host)
+	if err != nil {
+		t.Fatal("connect", err)
+	}
+	defer c.Close()
+
+	threshold, err := rd.Strings(c.Do("CONFIG", "GET", "slowlog-log-slower-than"))
+	if err != nil {
+		t.Fatal("CONFIG GET", err)
+	}
+	_, err = c.Do("CONFIG", "SET", "slowlog-log-slower-than", 0)
+	if err != nil {
+		t.Fatal("CONFIG SET", err)
+	}
+	_, err = c.Do("KEYS", "*")
+	if err != nil {
+		t.Fatal("KEYS", err)
+	}
+	_, err = c.Do("CONFIG", "SET", "slowlog-log-slower-than", threshold[1])
+	if err != nil {
+		t.Fatal("CONFIG SET", err)
+	}
+}
+
+func getConfig() map[string]interface{} {
+	return map[string]interface{}{
+		"module":     "redis",
+		"metricsets": []string{"slowlog"},
+		"hosts":      []string{host},
+	}
+}
@@ -0,0 +1,5 @@
+/*
+Package sql is a Metricbeat module that runs custom queries against SQL
+databases.
+*/
+package sql
@@ -0,0 +1,54 @@
+package query
+
+import (
+	"fmt"
+)
+
+// Response formats of a query.
+const (
+	// tableFormat reports an event per row, with a field per column.
+	tableFormat = "table"
+
+	// variablesFormat reports a single event for all rows. Rows must have two
+	// columns, the first one is used as field name and the second as value.
+	variablesFormat = "variables"
+)
+
+// Types a column or variable can be converted to.
+var fieldTypes = map[string]bool{
+	"long":    true,
+	"double":  true,
+	"boolean": true,
+	"string":  true,
+}
+
+type config struct {
+	Driver  string        `config:"driver"`
+	Queries []queryConfig `config:"queries" validate:"required"`
+}
+
+type queryConfig struct {
+	Name           string            `config:"name"`
+	Query          string            `config:"query"    validate:"required"`
+	ResponseFormat string            `config:"response_format"`
+	Fields         map[string]string `config:"fields"`
+}
+
+func (c *queryConfig) Validate() error {
+	switch c.ResponseFormat {
+	case "":
+		c.ResponseFormat = tableFormat
+	case tableFormat, variablesFormat:
+	default:
+		return fmt.Errorf("invalid response_format '%s', must be %s or %s",
+			c.ResponseFormat, tableFormat, variablesFormat)
+	}
+
+	for field, typ := range c.Fields {
+		if !fieldTypes[typ] {
+			return fmt.Errorf("invalid type '%s' for field '%s', must be long, double, boolean or string",
+				typ, field)
+		}
+	}
+	return nil
+}
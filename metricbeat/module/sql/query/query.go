@@ -0,0 +1,237 @@
+package query
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/elastic/beats/libbeat/common"
+	"github.com/elastic/beats/libbeat/common/cfgwarn"
+	"github.com/elastic/beats/metricbeat/mb"
+	sqlmodule "github.com/elastic/beats/metricbeat/module/sql"
+)
+
+// init registers the MetricSet with the central registry.
+// The New method will be called after the setup of the module and before starting to fetch data
+func init() {
+	if err := mb.Registry.AddMetricSet("sql", "query", New, sqlmodule.ParseHost); err != nil {
+		panic(err)
+	}
+}
+
+// MetricSet runs the configured queries and reports their results.
+type MetricSet struct {
+	mb.BaseMetricSet
+	driver  string
+	queries []queryConfig
+	db      *sql.DB
+}
+
+// New creates a new instance of the MetricSet. The database handle is opened
+// once and reused by all fetches.
+func New(base mb.BaseMetricSet) (mb.MetricSet, error) {
+	cfgwarn.Experimental("The sql query metricset is experimental")
+
+	config := config{}
+	if err := base.Module().UnpackConfig(&config); err != nil {
+		return nil, err
+	}
+
+	db, err := sql.Open(config.Driver, base.HostData().URI)
+	if err != nil {
+		return nil, errors.Wrap(err, "sql open failed")
+	}
+
+	return &MetricSet{
+		BaseMetricSet: base,
+		driver:        config.Driver,
+		queries:       config.Queries,
+		db:            db,
+	}, nil
+}
+
+// Fetch runs all queries. The failure of a query is reported as an error
+// event and doesn't prevent running the following ones.
+func (m *MetricSet) Fetch(ctx context.Context, r mb.Reporter) {
+	for _, q := range m.queries {
+		if ctx.Err() != nil {
+			return
+		}
+
+		events, err := m.query(ctx, q)
+		if err != nil {
+			r.ErrorWith(errors.Wrapf(err, "query '%s' failed", q.Query), m.queryInfo(q))
+			continue
+		}
+
+		for _, event := range events {
+			if !r.Event(event) {
+				return
+			}
+		}
+	}
+}
+
+// Close closes the database handle.
+func (m *MetricSet) Close() error {
+	return m.db.Close()
+}
+
+func (m *MetricSet) query(ctx context.Context, q queryConfig) ([]common.MapStr, error) {
+	rows, err := m.db.QueryContext(ctx, q.Query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+	if q.ResponseFormat == variablesFormat && len(columns) != 2 {
+		return nil, fmt.Errorf("variables response format requires 2 columns, got %d", len(columns))
+	}
+
+	values := make([]interface{}, len(columns))
+	dest := make([]interface{}, len(columns))
+	for i := range values {
+		dest[i] = &values[i]
+	}
+
+	var events []common.MapStr
+	variables := common.MapStr{}
+	for rows.Next() {
+		if err := rows.Scan(dest...); err != nil {
+			return nil, err
+		}
+
+		if q.ResponseFormat == variablesFormat {
+			name := fmt.Sprint(toString(values[0]))
+			if err := setField(variables, q.Fields, name, values[1]); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		metrics := common.MapStr{}
+		for i, column := range columns {
+			if err := setField(metrics, q.Fields, column, values[i]); err != nil {
+				return nil, err
+			}
+		}
+		events = append(events, m.event(q, metrics))
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if q.ResponseFormat == variablesFormat {
+		events = append(events, m.event(q, variables))
+	}
+	return events, nil
+}
+
+func (m *MetricSet) queryInfo(q queryConfig) common.MapStr {
+	info := common.MapStr{
+		"driver": m.driver,
+		"query":  q.Query,
+	}
+	if q.Name != "" {
+		info["name"] = q.Name
+	}
+	return info
+}
+
+func (m *MetricSet) event(q queryConfig, metrics common.MapStr) common.MapStr {
+	event := m.queryInfo(q)
+	event["metrics"] = metrics
+	return event
+}
+
+// setField converts the value to the type configured for the field and adds
+// it to the metrics. NULL values are omitted.
+func setField(metrics common.MapStr, types map[string]string, name string, value interface{}) error {
+	if value == nil {
+		return nil
+	}
+
+	value, err := convert(value, types[name])
+	if err != nil {
+		return errors.Wrapf(err, "failed to convert field '%s'", name)
+	}
+	metrics[name] = value
+	return nil
+}
+
+// convert converts a value scanned by the driver to the given type. Values
+// are kept as returned by the driver when no type is given, except for bytes
+// and times that are reported as strings and timestamps.
+func convert(value interface{}, typ string) (interface{}, error) {
+	switch typ {
+	case "":
+		switch v := value.(type) {
+		case []byte:
+			return string(v), nil
+		case time.Time:
+			return common.Time(v), nil
+		}
+		return value, nil
+	case "string":
+		return fmt.Sprint(toString(value)), nil
+	case "long":
+		switch v := value.(type) {
+		case int64:
+			return v, nil
+		case float64:
+			return int64(v), nil
+		case bool:
+			if v {
+				return int64(1), nil
+			}
+			return int64(0), nil
+		}
+		return strconv.ParseInt(strings.TrimSpace(fmt.Sprint(toString(value))), 10, 64)
+	case "double":
+		switch v := value.(type) {
+		case int64:
+			return float64(v), nil
+		case float64:
+			return v, nil
+		}
+		return strconv.ParseFloat(strings.TrimSpace(fmt.Sprint(toString(value))), 64)
+	case "boolean":
+		switch v := value.(type) {
+		case bool:
+			return v, nil
+		case int64:
+			return v != 0, nil
+		}
+		return parseBool(fmt.Sprint(toString(value)))
+	}
+	return nil, fmt.Errorf("unknown type '%s'", typ)
+}
+
+// toString returns bytes as a string, other values are returned as is.
+func toString(value interface{}) interface{} {
+	if b, ok := value.([]byte); ok {
+		return string(b)
+	}
+	return value
+}
+
+// parseBool parses a boolean, accepting the ON/OFF and YES/NO values used by
+// server variables.
+func parseBool(s string) (bool, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "on", "yes":
+		return true, nil
+	case "off", "no":
+		return false, nil
+	}
+	return strconv.ParseBool(strings.TrimSpace(s))
+}
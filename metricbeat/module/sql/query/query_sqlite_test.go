@@ -0,0 +1,133 @@
+// +build !integration,sqlite
+
+package query
+
+import (
+	"context"
+	"database/sql"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/elastic/beats/libbeat/common"
+	mbtest "github.com/elastic/beats/metricbeat/mb/testing"
+)
+
+func TestFetchTable(t *testing.T) {
+	path := createTestDB(t)
+	defer os.RemoveAll(filepath.Dir(path))
+
+	f := mbtest.NewReportingMetricSetWithContext(t, getConfig(path, []map[string]interface{}{
+		{
+			"name":  "orders",
+			"query": "SELECT status, COUNT(*) AS count, SUM(amount) AS amount FROM orders GROUP BY status ORDER BY status",
+		},
+	}))
+	defer f.(*MetricSet).Close()
+
+	events, errs := mbtest.ReportingFetchWithContext(context.Background(), f)
+	if !assert.Empty(t, errs) || !assert.Len(t, events, 2) {
+		return
+	}
+
+	assert.Equal(t, "orders", events[0]["name"])
+	assert.Equal(t, "sqlite3", events[0]["driver"])
+	assert.Equal(t, common.MapStr{"status": "paid", "count": int64(2), "amount": 30.5}, events[0]["metrics"])
+	assert.Equal(t, common.MapStr{"status": "pending", "count": int64(1), "amount": 5.0}, events[1]["metrics"])
+}
+
+func TestFetchVariables(t *testing.T) {
+	path := createTestDB(t)
+	defer os.RemoveAll(filepath.Dir(path))
+
+	f := mbtest.NewReportingMetricSetWithContext(t, getConfig(path, []map[string]interface{}{
+		{
+			"query":           "SELECT name, value FROM settings",
+			"response_format": "variables",
+			"fields": map[string]interface{}{
+				"max_connections": "long",
+				"read_only":       "boolean",
+			},
+		},
+	}))
+	defer f.(*MetricSet).Close()
+
+	events, errs := mbtest.ReportingFetchWithContext(context.Background(), f)
+	if !assert.Empty(t, errs) || !assert.Len(t, events, 1) {
+		return
+	}
+
+	assert.NotContains(t, events[0], "name")
+	assert.Equal(t, common.MapStr{
+		"max_connections": int64(100),
+		"read_only":       false,
+		"version":         "1.2",
+	}, events[0]["metrics"])
+}
+
+func TestFetchQueryError(t *testing.T) {
+	path := createTestDB(t)
+	defer os.RemoveAll(filepath.Dir(path))
+
+	f := mbtest.NewReportingMetricSetWithContext(t, getConfig(path, []map[string]interface{}{
+		{"query": "SELECT * FROM missing"},
+		{"query": "SELECT name, value, 1 FROM settings", "response_format": "variables"},
+		{"query": "SELECT name FROM settings", "fields": map[string]interface{}{"name": "long"}},
+		{"query": "SELECT COUNT(*) AS count FROM orders"},
+	}))
+	defer f.(*MetricSet).Close()
+
+	// Errors are reported with the query that failed.
+	events, errs := mbtest.ReportingFetchWithContext(context.Background(), f)
+	assert.Len(t, errs, 3)
+	if !assert.Len(t, events, 4) {
+		return
+	}
+	for i, event := range events[:3] {
+		assert.Equal(t, f.(*MetricSet).queries[i].Query, event["query"])
+		assert.NotContains(t, event, "metrics")
+	}
+
+	// Failing queries don't prevent running the following ones.
+	assert.Equal(t, common.MapStr{"count": int64(3)}, events[3]["metrics"])
+}
+
+func createTestDB(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "sql-query-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "test.db")
+
+	db, err := sql.Open("sqlite3", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	statements := []string{
+		"CREATE TABLE orders (id INTEGER, status TEXT, amount REAL)",
+		"INSERT INTO orders VALUES (1, 'paid', 10.5), (2, 'paid', 20), (3, 'pending', 5)",
+		"CREATE TABLE settings (name TEXT, value TEXT)",
+		"INSERT INTO settings VALUES ('max_connections', '100'), ('read_only', 'OFF'), ('version', '1.2'), ('unset', NULL)",
+	}
+	for _, statement := range statements {
+		if _, err := db.Exec(statement); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return path
+}
+
+func getConfig(path string, queries []map[string]interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		"module":     "sql",
+		"metricsets": []string{"query"},
+		"hosts":      []string{path},
+		"driver":     "sqlite3",
+		"queries":    queries,
+	}
+}
@@ -0,0 +1,61 @@
+// +build !integration
+
+package query
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/elastic/beats/libbeat/common"
+)
+
+func TestInvalidConfig(t *testing.T) {
+	configs := []map[string]interface{}{
+		{"query": "SELECT 1", "response_format": "rows"},
+		{"query": "SELECT 1", "fields": map[string]interface{}{"a": "int"}},
+		{"response_format": "table"},
+	}
+
+	for _, query := range configs {
+		cfg, err := common.NewConfigFrom(map[string]interface{}{
+			"queries": []interface{}{query},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		c := config{}
+		assert.Error(t, cfg.Unpack(&c), "%v", query)
+	}
+}
+
+func TestConvert(t *testing.T) {
+	tests := []struct {
+		value    interface{}
+		typ      string
+		expected interface{}
+	}{
+		{[]byte("text"), "", "text"},
+		{int64(42), "", int64(42)},
+		{[]byte(" 42 "), "long", int64(42)},
+		{12.9, "long", int64(12)},
+		{true, "long", int64(1)},
+		{[]byte("1.5"), "double", 1.5},
+		{int64(2), "double", 2.0},
+		{[]byte("ON"), "boolean", true},
+		{[]byte("no"), "boolean", false},
+		{int64(0), "boolean", false},
+		{int64(42), "string", "42"},
+	}
+
+	for _, test := range tests {
+		v, err := convert(test.value, test.typ)
+		if assert.NoError(t, err, "%v to %s", test.value, test.typ) {
+			assert.Equal(t, test.expected, v, "%v to %s", test.value, test.typ)
+		}
+	}
+
+	_, err := convert([]byte("abc"), "long")
+	assert.Error(t, err)
+}
@@ -0,0 +1,129 @@
+package sql
+
+import (
+	dbsql "database/sql"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/elastic/beats/metricbeat/mb"
+	"github.com/elastic/beats/metricbeat/module/mysql"
+	"github.com/elastic/beats/metricbeat/module/postgresql"
+)
+
+// Supported database/sql drivers.
+const (
+	MySQLDriver    = "mysql"
+	PostgresDriver = "postgres"
+	SQLiteDriver   = "sqlite3"
+)
+
+func init() {
+	// Register the ModuleFactory function for the "sql" module.
+	if err := mb.Registry.AddModule("sql", NewModule); err != nil {
+		panic(err)
+	}
+}
+
+func NewModule(base mb.BaseModule) (mb.Module, error) {
+	// Validate that at least one host and a supported driver have been
+	// specified.
+	config := struct {
+		Hosts  []string `config:"hosts"    validate:"nonzero,required"`
+		Driver string   `config:"driver"   validate:"required"`
+	}{}
+	if err := base.UnpackConfig(&config); err != nil {
+		return nil, err
+	}
+
+	if _, err := hostParser(config.Driver); err != nil {
+		return nil, err
+	}
+
+	return &base, nil
+}
+
+// ParseHost parses the host as a DSN (data source name) of the configured
+// driver. The DSN format depends on the driver:
+//
+//   mysql:    [username[:password]@][protocol[(address)]]/[dbname]
+//   postgres: [postgres://][user:pass@]host[:port][/dbname][?options]
+//   sqlite3:  [file:]path[?options]
+//
+// Credentials are removed from the SanitizedURI of the returned HostData.
+func ParseHost(mod mb.Module, host string) (mb.HostData, error) {
+	c := struct {
+		Driver string `config:"driver"`
+	}{}
+	if err := mod.UnpackConfig(&c); err != nil {
+		return mb.HostData{}, err
+	}
+
+	parse, err := hostParser(c.Driver)
+	if err != nil {
+		return mb.HostData{}, err
+	}
+	return parse(mod, host)
+}
+
+func hostParser(driver string) (mb.HostParser, error) {
+	switch driver {
+	case MySQLDriver:
+		return mysql.ParseDSN, nil
+	case PostgresDriver:
+		return postgresql.ParseURL, nil
+	case SQLiteDriver:
+		if !driverRegistered(SQLiteDriver) {
+			return nil, fmt.Errorf("driver '%s' is not available, Metricbeat must be built with the sqlite build tag", driver)
+		}
+		return parseSQLiteDSN, nil
+	default:
+		return nil, fmt.Errorf("unsupported driver '%s', supported drivers are %s, %s and %s",
+			driver, MySQLDriver, PostgresDriver, SQLiteDriver)
+	}
+}
+
+// parseSQLiteDSN parses a sqlite DSN. The credentials of databases using
+// user authentication are passed in the _auth_user and _auth_pass options.
+func parseSQLiteDSN(_ mb.Module, host string) (mb.HostData, error) {
+	path, rawQuery := host, ""
+	if i := strings.IndexByte(host, '?'); i >= 0 {
+		path, rawQuery = host[:i], host[i+1:]
+	}
+
+	options, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		return mb.HostData{}, errors.Wrap(err, "error parsing sqlite options")
+	}
+
+	user, password := options.Get("_auth_user"), options.Get("_auth_pass")
+	options.Del("_auth_user")
+	options.Del("_auth_pass")
+
+	sanitized := path
+	if len(options) > 0 {
+		sanitized += "?" + options.Encode()
+	}
+
+	return mb.HostData{
+		URI:          host,
+		SanitizedURI: sanitized,
+		Host:         strings.TrimPrefix(path, "file:"),
+		User:         user,
+		Password:     password,
+	}, nil
+}
+
+// driverRegistered checks if the database/sql driver is registered. The mysql
+// and postgres drivers are registered by their modules, the sqlite driver only
+// in builds with the sqlite tag, as it requires cgo.
+func driverRegistered(name string) bool {
+	for _, driver := range dbsql.Drivers() {
+		if driver == name {
+			return true
+		}
+	}
+	return false
+}
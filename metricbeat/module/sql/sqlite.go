@@ -0,0 +1,9 @@
+// +build sqlite
+
+package sql
+
+import (
+	// Register sqlite database/sql driver. It is only included in builds with
+	// the sqlite tag, as it requires cgo.
+	_ "github.com/mattn/go-sqlite3"
+)
@@ -0,0 +1,24 @@
+// +build !integration,sqlite
+
+package sql
+
+import (
+	"testing"
+)
+
+func TestParseHostSQLite(t *testing.T) {
+	testParseHost(t, []parseHostTest{
+		{
+			driver:    "sqlite3",
+			host:      "file:/var/lib/shop.db?_auth_user=root&_auth_pass=secret&cache=shared",
+			sanitized: "file:/var/lib/shop.db?cache=shared",
+			user:      "root",
+			password:  "secret",
+		},
+		{
+			driver:    "sqlite3",
+			host:      "/var/lib/shop.db",
+			sanitized: "/var/lib/shop.db",
+		},
+	})
+}
@@ -0,0 +1,32 @@
+package server
+
+import (
+	"fmt"
+)
+
+type StatsdServerConfig struct {
+	Percentiles            []float64 `config:"percentiles"`
+	GaugeExpirationPeriods int       `config:"gauge_expiration_periods" validate:"min=0"`
+	MaxGauges              int       `config:"max_gauges" validate:"min=0"`
+	MaxSetSize             int       `config:"max_set_size" validate:"min=0"`
+	MaxTimerSamples        int       `config:"max_timer_samples" validate:"min=0"`
+}
+
+func DefaultStatsdServerConfig() StatsdServerConfig {
+	return StatsdServerConfig{
+		Percentiles:            []float64{50, 75, 90, 95, 99},
+		GaugeExpirationPeriods: 10,
+		MaxGauges:              10000,
+		MaxSetSize:             10000,
+		MaxTimerSamples:        10000,
+	}
+}
+
+func (c StatsdServerConfig) Validate() error {
+	for _, p := range c.Percentiles {
+		if p <= 0 || p > 100 {
+			return fmt.Errorf("invalid percentile %v, percentiles must be greater than 0 and at most 100", p)
+		}
+	}
+	return nil
+}
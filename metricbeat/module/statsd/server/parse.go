@@ -0,0 +1,109 @@
+package server
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/joeshaw/multierror"
+)
+
+// StatsD metric types.
+const (
+	counterType      = "c"
+	gaugeType        = "g"
+	timerType        = "ms"
+	histogramType    = "h"
+	distributionType = "d" // DogStatsD distributions are aggregated as timers.
+	setType          = "s"
+)
+
+// metric is a single metric received in a StatsD packet.
+//
+//   Format: <name>:<value>|<type>[|@<sample rate>][|#<tag>[:<value>],...]
+type metric struct {
+	name       string
+	value      string
+	typ        string
+	sampleRate float64
+	tags       map[string]string
+}
+
+// parsePacket parses the metrics of a packet, one per line. Invalid lines are
+// skipped and reported in the returned errors.
+func parsePacket(packet string) ([]metric, multierror.Errors) {
+	var metrics []metric
+	var errs multierror.Errors
+	for _, line := range strings.Split(packet, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		m, err := parseLine(line)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		metrics = append(metrics, m)
+	}
+	return metrics, errs
+}
+
+func parseLine(line string) (metric, error) {
+	colon := strings.IndexByte(line, ':')
+	if colon <= 0 {
+		return metric{}, fmt.Errorf("invalid metric '%s': missing name or value", line)
+	}
+
+	m := metric{
+		name:       line[:colon],
+		sampleRate: 1,
+	}
+
+	parts := strings.Split(line[colon+1:], "|")
+	if len(parts) < 2 || parts[0] == "" {
+		return metric{}, fmt.Errorf("invalid metric '%s': missing value or type", line)
+	}
+	m.value, m.typ = parts[0], parts[1]
+
+	switch m.typ {
+	case counterType, gaugeType, timerType, histogramType, distributionType, setType:
+	default:
+		return metric{}, fmt.Errorf("invalid metric '%s': unknown type '%s'", line, m.typ)
+	}
+
+	for _, part := range parts[2:] {
+		switch {
+		case strings.HasPrefix(part, "@"):
+			rate, err := strconv.ParseFloat(part[1:], 64)
+			if err != nil || rate <= 0 || rate > 1 {
+				return metric{}, fmt.Errorf("invalid metric '%s': invalid sample rate '%s'", line, part[1:])
+			}
+			m.sampleRate = rate
+		case strings.HasPrefix(part, "#"):
+			m.tags = parseTags(part[1:])
+		default:
+			return metric{}, fmt.Errorf("invalid metric '%s': unknown field '%s'", line, part)
+		}
+	}
+
+	return m, nil
+}
+
+// parseTags parses DogStatsD tags. Tags without value are set to an empty
+// string.
+func parseTags(s string) map[string]string {
+	tags := map[string]string{}
+	for _, tag := range strings.Split(s, ",") {
+		if tag == "" {
+			continue
+		}
+		if i := strings.IndexByte(tag, ':'); i >= 0 {
+			tags[tag[:i]] = tag[i+1:]
+		} else {
+			tags[tag] = ""
+		}
+	}
+	return tags
+}
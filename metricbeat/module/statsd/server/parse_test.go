@@ -0,0 +1,81 @@
+// +build !integration
+
+package server
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseLine(t *testing.T) {
+	tests := []struct {
+		line     string
+		expected metric
+	}{
+		{
+			line:     "requests:1|c",
+			expected: metric{name: "requests", value: "1", typ: "c", sampleRate: 1},
+		},
+		{
+			line:     "requests:2|c|@0.1",
+			expected: metric{name: "requests", value: "2", typ: "c", sampleRate: 0.1},
+		},
+		{
+			line:     "temperature:-1.5|g",
+			expected: metric{name: "temperature", value: "-1.5", typ: "g", sampleRate: 1},
+		},
+		{
+			line:     "db.query:320|ms|@0.5|#env:prod,region:eu,canary",
+			expected: metric{
+				name:       "db.query",
+				value:      "320",
+				typ:        "ms",
+				sampleRate: 0.5,
+				tags:       map[string]string{"env": "prod", "region": "eu", "canary": ""},
+			},
+		},
+		{
+			line:     "users:alice|s",
+			expected: metric{name: "users", value: "alice", typ: "s", sampleRate: 1},
+		},
+		{
+			line:     "size:128|h",
+			expected: metric{name: "size", value: "128", typ: "h", sampleRate: 1},
+		},
+	}
+
+	for _, test := range tests {
+		m, err := parseLine(test.line)
+		if assert.NoError(t, err, test.line) {
+			assert.Equal(t, test.expected, m, test.line)
+		}
+	}
+}
+
+func TestParseLineErrors(t *testing.T) {
+	lines := []string{
+		"requests",
+		":1|c",
+		"requests:1",
+		"requests:|c",
+		"requests:1|x",
+		"requests:1|c|@2",
+		"requests:1|c|@abc",
+		"requests:1|c|foo",
+	}
+
+	for _, line := range lines {
+		_, err := parseLine(line)
+		assert.Error(t, err, line)
+	}
+}
+
+func TestParsePacket(t *testing.T) {
+	metrics, errs := parsePacket("requests:1|c\n\ninvalid\nlatency:10|ms\n")
+	assert.Len(t, errs, 1)
+	if assert.Len(t, metrics, 2) {
+		assert.Equal(t, "requests", metrics[0].name)
+		assert.Equal(t, "latency", metrics[1].name)
+	}
+}
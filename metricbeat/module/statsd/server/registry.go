@@ -0,0 +1,273 @@
+package server
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/elastic/beats/libbeat/common"
+	"github.com/elastic/beats/libbeat/logp"
+)
+
+// registry aggregates the metrics received during a flush period. Metrics are
+// grouped by their tags, one event is reported per group.
+type registry struct {
+	config StatsdServerConfig
+	groups map[string]*metricGroup
+	rand   *rand.Rand // Used to sample the values of timers.
+
+	// gauges keeps the last value of the gauges, so relative updates can be
+	// applied in following periods. Gauges not updated for the configured
+	// number of periods are removed.
+	gauges map[string]*gaugeValue
+	period int
+
+	// Number of gauges and set values dropped in the current period because
+	// of the configured limits.
+	droppedGauges    int
+	droppedSetValues int
+}
+
+type gaugeValue struct {
+	value   float64
+	updated int // Period of the last update.
+}
+
+type metricGroup struct {
+	tags     common.MapStr
+	counters map[string]float64
+	gauges   map[string]float64
+	timers   map[string]*timer
+	sets     map[string]map[string]struct{}
+}
+
+// timer keeps the statistics of the values received for a timer. The count,
+// min, max, mean and standard deviation take all the values into account, the
+// percentiles are computed from a uniform sample of at most the configured
+// number of values.
+type timer struct {
+	count   float64 // Number of values, taking the sample rate into account.
+	n       int     // Number of values received.
+	min     float64
+	max     float64
+	mean    float64
+	m2      float64 // Sum of the squared differences to the mean.
+	samples []float64
+}
+
+func newRegistry(config StatsdServerConfig) *registry {
+	return &registry{
+		config: config,
+		groups: map[string]*metricGroup{},
+		rand:   rand.New(rand.NewSource(time.Now().UnixNano())),
+		gauges: map[string]*gaugeValue{},
+	}
+}
+
+// add aggregates the metric with the ones received in the current period.
+func (r *registry) add(m metric) error {
+	group := r.group(m.tags)
+
+	if m.typ == setType {
+		set, found := group.sets[m.name]
+		if !found {
+			set = map[string]struct{}{}
+			group.sets[m.name] = set
+		}
+		if _, found := set[m.value]; !found && r.config.MaxSetSize > 0 && len(set) >= r.config.MaxSetSize {
+			r.droppedSetValues++
+			return nil
+		}
+		set[m.value] = struct{}{}
+		return nil
+	}
+
+	value, err := strconv.ParseFloat(m.value, 64)
+	if err != nil {
+		return fmt.Errorf("invalid value '%s' for metric '%s'", m.value, m.name)
+	}
+
+	switch m.typ {
+	case counterType:
+		group.counters[m.name] += value / m.sampleRate
+	case gaugeType:
+		group.gauges[m.name] = r.updateGauge(m.name+tagsKey(group.tags), m.value, value)
+	case timerType, histogramType, distributionType:
+		t, found := group.timers[m.name]
+		if !found {
+			t = &timer{}
+			group.timers[m.name] = t
+		}
+		r.addTimerValue(t, value)
+		t.count += 1 / m.sampleRate
+	}
+	return nil
+}
+
+// addTimerValue updates the statistics of the timer with the value. Once the
+// timer has the maximum number of samples, the value replaces a random sample
+// so all the values received have the same probability of being sampled.
+func (r *registry) addTimerValue(t *timer, value float64) {
+	t.n++
+	if t.n == 1 || value < t.min {
+		t.min = value
+	}
+	if t.n == 1 || value > t.max {
+		t.max = value
+	}
+	delta := value - t.mean
+	t.mean += delta / float64(t.n)
+	t.m2 += delta * (value - t.mean)
+
+	if r.config.MaxTimerSamples <= 0 || len(t.samples) < r.config.MaxTimerSamples {
+		t.samples = append(t.samples, value)
+		return
+	}
+	if i := r.rand.Intn(t.n); i < len(t.samples) {
+		t.samples[i] = value
+	}
+}
+
+// updateGauge stores the new value of the gauge and returns it. Signed values
+// update the previous value of the gauge.
+func (r *registry) updateGauge(key, raw string, value float64) float64 {
+	gauge, found := r.gauges[key]
+	if !found {
+		gauge = &gaugeValue{}
+		if r.config.MaxGauges <= 0 || len(r.gauges) < r.config.MaxGauges {
+			r.gauges[key] = gauge
+		} else {
+			r.droppedGauges++
+		}
+	}
+
+	if strings.HasPrefix(raw, "+") || strings.HasPrefix(raw, "-") {
+		value += gauge.value
+	}
+	gauge.value = value
+	gauge.updated = r.period
+	return value
+}
+
+func (r *registry) group(tags map[string]string) *metricGroup {
+	tagsMap := common.MapStr{}
+	for k, v := range tags {
+		tagsMap[k] = v
+	}
+
+	key := tagsKey(tagsMap)
+	group, found := r.groups[key]
+	if !found {
+		group = &metricGroup{
+			tags:     tagsMap,
+			counters: map[string]float64{},
+			gauges:   map[string]float64{},
+			timers:   map[string]*timer{},
+			sets:     map[string]map[string]struct{}{},
+		}
+		r.groups[key] = group
+	}
+	return group
+}
+
+// flush returns the events for the metrics received since the previous flush
+// and resets them.
+func (r *registry) flush() []common.MapStr {
+	var events []common.MapStr
+	for _, group := range r.groups {
+		metrics := common.MapStr{}
+		for name, value := range group.counters {
+			metrics[name] = common.MapStr{"value": value}
+		}
+		for name, value := range group.gauges {
+			metrics[name] = common.MapStr{"value": value}
+		}
+		for name, t := range group.timers {
+			metrics[name] = r.timerStats(t)
+		}
+		for name, set := range group.sets {
+			metrics[name] = common.MapStr{"count": len(set)}
+		}
+
+		event := common.MapStr{"metrics": metrics}
+		if len(group.tags) > 0 {
+			event["tags"] = group.tags
+		}
+		events = append(events, event)
+	}
+
+	r.groups = map[string]*metricGroup{}
+	r.expireGauges()
+
+	if r.droppedGauges > 0 {
+		logp.Warn("statsd: the values of %d gauges were not kept for relative updates, the limit of %d gauges was reached", r.droppedGauges, r.config.MaxGauges)
+	}
+	if r.droppedSetValues > 0 {
+		logp.Warn("statsd: %d set values were ignored, the limit of %d values per set was reached", r.droppedSetValues, r.config.MaxSetSize)
+	}
+	r.droppedGauges, r.droppedSetValues = 0, 0
+
+	return events
+}
+
+// expireGauges ends the current period and removes the gauges that haven't
+// been updated during the last configured number of periods.
+func (r *registry) expireGauges() {
+	r.period++
+	if r.config.GaugeExpirationPeriods <= 0 {
+		return
+	}
+	for key, gauge := range r.gauges {
+		if r.period-gauge.updated > r.config.GaugeExpirationPeriods {
+			delete(r.gauges, key)
+		}
+	}
+}
+
+func (r *registry) timerStats(t *timer) common.MapStr {
+	stats := common.MapStr{
+		"count":  t.count,
+		"min":    t.min,
+		"max":    t.max,
+		"mean":   t.mean,
+		"stddev": math.Sqrt(t.m2 / float64(t.n)),
+	}
+
+	if len(r.config.Percentiles) > 0 {
+		sort.Float64s(t.samples)
+		percentiles := common.MapStr{}
+		for _, p := range r.config.Percentiles {
+			percentiles[percentileKey(p)] = percentile(t.samples, p)
+		}
+		stats["percentile"] = percentiles
+	}
+	return stats
+}
+
+// percentileKey returns the key of a percentile in the events. Keys can't
+// contain dots, as they would be mapped as objects, so 99.9 is reported as
+// p99_9.
+func percentileKey(p float64) string {
+	return "p" + strings.Replace(strconv.FormatFloat(p, 'f', -1, 64), ".", "_", -1)
+}
+
+// percentile returns the nearest-rank percentile of the sorted values.
+func percentile(sorted []float64, p float64) float64 {
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
+
+// tagsKey returns a key identifying the tags, independent of their order.
+func tagsKey(tags common.MapStr) string {
+	if len(tags) == 0 {
+		return "#"
+	}
+	return tags.String()
+}
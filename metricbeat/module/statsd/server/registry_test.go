@@ -0,0 +1,214 @@
+// +build !integration
+
+package server
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/elastic/beats/libbeat/common"
+)
+
+func addAll(t *testing.T, r *registry, packet string) {
+	metrics, errs := parsePacket(packet)
+	if !assert.Empty(t, errs) {
+		return
+	}
+	for _, m := range metrics {
+		assert.NoError(t, r.add(m))
+	}
+}
+
+func TestRegistryCounters(t *testing.T) {
+	r := newRegistry(StatsdServerConfig{})
+	addAll(t, r, "requests:1|c\nrequests:2|c\nrequests:1|c|@0.25")
+
+	events := r.flush()
+	if assert.Len(t, events, 1) {
+		assert.Equal(t, common.MapStr{"value": 7.0}, events[0]["metrics"].(common.MapStr)["requests"])
+	}
+
+	// Metrics are reset after each flush.
+	assert.Empty(t, r.flush())
+}
+
+func TestRegistryGauges(t *testing.T) {
+	r := newRegistry(StatsdServerConfig{})
+	addAll(t, r, "temperature:20|g\ntemperature:+2|g\ntemperature:-0.5|g")
+
+	events := r.flush()
+	if assert.Len(t, events, 1) {
+		assert.Equal(t, common.MapStr{"value": 21.5}, events[0]["metrics"].(common.MapStr)["temperature"])
+	}
+
+	// Relative updates apply to the value of the previous period.
+	addAll(t, r, "temperature:+1|g")
+	events = r.flush()
+	if assert.Len(t, events, 1) {
+		assert.Equal(t, common.MapStr{"value": 22.5}, events[0]["metrics"].(common.MapStr)["temperature"])
+	}
+}
+
+func TestRegistryGaugesExpiration(t *testing.T) {
+	r := newRegistry(StatsdServerConfig{GaugeExpirationPeriods: 2})
+	addAll(t, r, "temperature:20|g|#room:kitchen\ntemperature:18|g|#room:hall")
+	r.flush()
+
+	// The kitchen gauge is updated, the hall one isn't.
+	addAll(t, r, "temperature:+1|g|#room:kitchen")
+	r.flush()
+	assert.Len(t, r.gauges, 2)
+
+	// The hall gauge hasn't been updated for two periods.
+	r.flush()
+	assert.Len(t, r.gauges, 1)
+
+	// Relative updates of expired gauges start from zero.
+	addAll(t, r, "temperature:+1|g|#room:hall\ntemperature:+1|g|#room:kitchen")
+	events := r.flush()
+	if !assert.Len(t, events, 2) {
+		return
+	}
+	for _, event := range events {
+		tags := event["tags"].(common.MapStr)
+		expected := 1.0
+		if tags["room"] == "kitchen" {
+			expected = 22.0
+		}
+		assert.Equal(t, common.MapStr{"value": expected}, event["metrics"].(common.MapStr)["temperature"], "room %v", tags["room"])
+	}
+}
+
+func TestRegistryMaxGauges(t *testing.T) {
+	r := newRegistry(StatsdServerConfig{MaxGauges: 2})
+	addAll(t, r, "a:1|g\nb:2|g\nc:3|g")
+
+	// Gauges over the limit are reported, but their values are not kept.
+	events := r.flush()
+	if assert.Len(t, events, 1) {
+		assert.Equal(t, common.MapStr{"value": 3.0}, events[0]["metrics"].(common.MapStr)["c"])
+	}
+	assert.Len(t, r.gauges, 2)
+	assert.NotContains(t, r.gauges, "c#")
+
+	addAll(t, r, "a:+1|g")
+	events = r.flush()
+	if assert.Len(t, events, 1) {
+		assert.Equal(t, common.MapStr{"value": 2.0}, events[0]["metrics"].(common.MapStr)["a"])
+	}
+}
+
+func TestRegistryTimers(t *testing.T) {
+	r := newRegistry(StatsdServerConfig{Percentiles: []float64{50, 90, 99.9}})
+	for i := 1; i <= 10; i++ {
+		addAll(t, r, "latency:"+strconv.Itoa(i)+"|ms")
+	}
+	addAll(t, r, "latency:10|ms|@0.5")
+
+	events := r.flush()
+	if !assert.Len(t, events, 1) {
+		return
+	}
+
+	stats := events[0]["metrics"].(common.MapStr)["latency"].(common.MapStr)
+	assert.Equal(t, 12.0, stats["count"])
+	assert.Equal(t, 1.0, stats["min"])
+	assert.Equal(t, 10.0, stats["max"])
+	assert.InDelta(t, 65.0/11, stats["mean"], 0.0001)
+	assert.Equal(t, common.MapStr{"p50": 6.0, "p90": 10.0, "p99_9": 10.0}, stats["percentile"])
+}
+
+func TestRegistryMaxTimerSamples(t *testing.T) {
+	r := newRegistry(StatsdServerConfig{Percentiles: []float64{50}, MaxTimerSamples: 10})
+	for i := 1; i <= 1000; i++ {
+		addAll(t, r, "latency:"+strconv.Itoa(i)+"|ms")
+	}
+	assert.Len(t, r.groups["#"].timers["latency"].samples, 10)
+
+	events := r.flush()
+	if !assert.Len(t, events, 1) {
+		return
+	}
+
+	// Only the percentiles are computed from the samples.
+	stats := events[0]["metrics"].(common.MapStr)["latency"].(common.MapStr)
+	assert.Equal(t, 1000.0, stats["count"])
+	assert.Equal(t, 1.0, stats["min"])
+	assert.Equal(t, 1000.0, stats["max"])
+	assert.InDelta(t, 500.5, stats["mean"], 0.0001)
+	assert.InDelta(t, 288.675, stats["stddev"], 0.001)
+	p50 := stats["percentile"].(common.MapStr)["p50"].(float64)
+	assert.True(t, p50 >= 1 && p50 <= 1000, "p50 %v", p50)
+}
+
+func TestRegistryReservedNames(t *testing.T) {
+	r := newRegistry(StatsdServerConfig{})
+	addAll(t, r, "tags:1|c|#env:prod")
+
+	// Metrics are reported in their own object, so their names can't
+	// collide with the tags.
+	events := r.flush()
+	if assert.Len(t, events, 1) {
+		assert.Equal(t, common.MapStr{"env": "prod"}, events[0]["tags"])
+		assert.Equal(t, common.MapStr{"value": 1.0}, events[0]["metrics"].(common.MapStr)["tags"])
+	}
+}
+
+func TestRegistrySets(t *testing.T) {
+	r := newRegistry(StatsdServerConfig{})
+	addAll(t, r, "users:alice|s\nusers:bob|s\nusers:alice|s")
+
+	events := r.flush()
+	if assert.Len(t, events, 1) {
+		assert.Equal(t, common.MapStr{"count": 2}, events[0]["metrics"].(common.MapStr)["users"])
+	}
+}
+
+func TestRegistryMaxSetSize(t *testing.T) {
+	r := newRegistry(StatsdServerConfig{MaxSetSize: 2})
+	addAll(t, r, "users:alice|s\nusers:bob|s\nusers:carol|s\nusers:alice|s")
+
+	events := r.flush()
+	if assert.Len(t, events, 1) {
+		assert.Equal(t, common.MapStr{"count": 2}, events[0]["metrics"].(common.MapStr)["users"])
+	}
+}
+
+func TestPercentileKey(t *testing.T) {
+	assert.Equal(t, "p99", percentileKey(99))
+	assert.Equal(t, "p99_9", percentileKey(99.9))
+	assert.Equal(t, "p0_5", percentileKey(0.5))
+}
+
+func TestRegistryGroupsByTags(t *testing.T) {
+	r := newRegistry(StatsdServerConfig{})
+	addAll(t, r, "requests:1|c|#env:prod,region:eu\nerrors:1|c|#region:eu,env:prod\nrequests:1|c|#env:dev\nrequests:1|c")
+
+	events := r.flush()
+	if !assert.Len(t, events, 3) {
+		return
+	}
+
+	byTags := map[string]common.MapStr{}
+	for _, event := range events {
+		tags, _ := event["tags"].(common.MapStr)
+		byTags[tagsKey(tags)] = event
+	}
+
+	prod := byTags[tagsKey(common.MapStr{"env": "prod", "region": "eu"})]
+	if assert.NotNil(t, prod) {
+		assert.Contains(t, prod["metrics"], "requests")
+		assert.Contains(t, prod["metrics"], "errors")
+	}
+	assert.NotNil(t, byTags[tagsKey(common.MapStr{"env": "dev"})])
+	if untagged := byTags["#"]; assert.NotNil(t, untagged) {
+		assert.NotContains(t, untagged, "tags")
+	}
+}
+
+func TestRegistryInvalidValue(t *testing.T) {
+	r := newRegistry(StatsdServerConfig{})
+	assert.Error(t, r.add(metric{name: "requests", value: "abc", typ: counterType, sampleRate: 1}))
+}
@@ -0,0 +1,101 @@
+package server
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/elastic/beats/libbeat/common/cfgwarn"
+	"github.com/elastic/beats/libbeat/logp"
+	serverhelper "github.com/elastic/beats/metricbeat/helper/server"
+	"github.com/elastic/beats/metricbeat/helper/server/udp"
+	"github.com/elastic/beats/metricbeat/mb"
+)
+
+// init registers the MetricSet with the central registry.
+// The New method will be called after the setup of the module and before starting to fetch data
+func init() {
+	if err := mb.Registry.AddMetricSet("statsd", "server", New); err != nil {
+		panic(err)
+	}
+}
+
+// MetricSet receives StatsD metrics over UDP and reports them aggregated by
+// flush period.
+type MetricSet struct {
+	mb.BaseMetricSet
+	server   serverhelper.Server
+	registry *registry
+}
+
+// New create a new instance of the MetricSet
+// Part of new is also setting up the configuration by processing additional
+// configuration entries if needed.
+func New(base mb.BaseMetricSet) (mb.MetricSet, error) {
+	cfgwarn.Experimental("The statsd server metricset is experimental")
+
+	config := DefaultStatsdServerConfig()
+	if err := base.Module().UnpackConfig(&config); err != nil {
+		return nil, err
+	}
+
+	s, err := udp.NewUdpServerWithConfig(base, udp.UdpConfig{
+		Host:              "localhost",
+		Port:              8125,
+		ReceiveBufferSize: 65535,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &MetricSet{
+		BaseMetricSet: base,
+		server:        s,
+		registry:      newRegistry(config),
+	}, nil
+}
+
+// Run aggregates the received metrics and reports them on every period.
+func (m *MetricSet) Run(reporter mb.PushReporter) {
+	// Start event watcher
+	if err := m.server.Start(); err != nil {
+		err = errors.Wrap(err, "failed to start statsd server")
+		logp.Err("%v", err)
+		reporter.Error(err)
+		return
+	}
+
+	ticker := time.NewTicker(m.Module().Config().Period)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-reporter.Done():
+			m.server.Stop()
+			return
+		case <-ticker.C:
+			for _, event := range m.registry.flush() {
+				reporter.Event(event)
+			}
+		case msg := <-m.server.GetEvents():
+			bytes, ok := msg.GetEvent()[serverhelper.EventDataKey].([]byte)
+			if !ok || len(bytes) == 0 {
+				continue
+			}
+			if err := m.process(string(bytes)); err != nil {
+				reporter.Error(err)
+			}
+		}
+	}
+}
+
+// process adds the metrics of a packet to the registry.
+func (m *MetricSet) process(packet string) error {
+	metrics, errs := parsePacket(packet)
+	for _, metric := range metrics {
+		if err := m.registry.add(metric); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errs.Err()
+}
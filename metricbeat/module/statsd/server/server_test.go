@@ -0,0 +1,45 @@
+// +build !integration
+
+package server
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/elastic/beats/libbeat/common"
+	mbtest "github.com/elastic/beats/metricbeat/mb/testing"
+)
+
+func TestRun(t *testing.T) {
+	ms := mbtest.NewPushMetricSet(t, map[string]interface{}{
+		"module":     "statsd",
+		"metricsets": []string{"server"},
+		"host":       "127.0.0.1",
+		"port":       18125,
+		"period":     "100ms",
+	})
+
+	go func() {
+		// Wait for the server to be listening.
+		time.Sleep(50 * time.Millisecond)
+
+		conn, err := net.Dial("udp", "127.0.0.1:18125")
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		defer conn.Close()
+
+		conn.Write([]byte("requests:1|c\nrequests:2|c"))
+		conn.Write([]byte("invalid"))
+	}()
+
+	events, errs := mbtest.RunPushMetricSet(300*time.Millisecond, ms)
+	assert.Len(t, errs, 1)
+	if assert.Len(t, events, 1) {
+		assert.Equal(t, common.MapStr{"value": 3.0}, events[0]["metrics"].(common.MapStr)["requests"])
+	}
+}
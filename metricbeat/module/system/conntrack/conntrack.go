@@ -0,0 +1,72 @@
+// +build linux
+
+package conntrack
+
+import (
+	"path/filepath"
+
+	"github.com/pkg/errors"
+
+	"github.com/elastic/beats/libbeat/common"
+	"github.com/elastic/beats/libbeat/common/cfgwarn"
+	"github.com/elastic/beats/metricbeat/mb"
+	"github.com/elastic/beats/metricbeat/mb/parse"
+	"github.com/elastic/beats/metricbeat/module/system"
+)
+
+// init registers the MetricSet with the central registry.
+// The New method will be called after the setup of the module and before starting to fetch data
+func init() {
+	if err := mb.Registry.AddMetricSet("system", "conntrack", New, parse.EmptyHostParser); err != nil {
+		panic(err)
+	}
+}
+
+// MetricSet type defines all fields of the MetricSet
+type MetricSet struct {
+	mb.BaseMetricSet
+	procfs string
+}
+
+// New create a new instance of the MetricSet
+func New(base mb.BaseMetricSet) (mb.MetricSet, error) {
+	cfgwarn.Experimental("The system conntrack metricset is experimental")
+
+	systemModule, ok := base.Module().(*system.Module)
+	if !ok {
+		return nil, errors.New("unexpected module type")
+	}
+
+	return &MetricSet{
+		BaseMetricSet: base,
+		procfs:        systemModule.ProcFS,
+	}, nil
+}
+
+// Fetch reads the usage of the connection tracking table and its statistics.
+func (m *MetricSet) Fetch() (common.MapStr, error) {
+	count, err := readUint(filepath.Join(m.procfs, "sys/net/netfilter/nf_conntrack_count"))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed reading the conntrack table size, is the nf_conntrack module loaded?")
+	}
+	max, err := readUint(filepath.Join(m.procfs, "sys/net/netfilter/nf_conntrack_max"))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed reading the conntrack table limit")
+	}
+	stats, err := readStats(filepath.Join(m.procfs, "net/stat/nf_conntrack"))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed reading the conntrack statistics")
+	}
+
+	event := common.MapStr{
+		"entries": count,
+		"max":     max,
+		"stats":   stats,
+	}
+	if max > 0 {
+		event["used"] = common.MapStr{
+			"pct": system.Round(float64(count) / float64(max)),
+		}
+	}
+	return event, nil
+}
@@ -0,0 +1,65 @@
+// +build linux
+
+package conntrack
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	mbtest "github.com/elastic/beats/metricbeat/mb/testing"
+)
+
+func TestData(t *testing.T) {
+	f := mbtest.NewEventFetcher(t, getConfig("./_meta/testdata/proc"))
+	if err := mbtest.WriteEvent(f, t); err != nil {
+		t.Fatal("write", err)
+	}
+}
+
+func TestFetch(t *testing.T) {
+	f := mbtest.NewEventFetcher(t, getConfig("./_meta/testdata/proc"))
+	event, err := f.Fetch()
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Equal(t, uint64(436), event["entries"])
+	assert.Equal(t, uint64(262144), event["max"])
+
+	used, _ := event.GetValue("used.pct")
+	assert.Equal(t, 0.0017, used)
+
+	stats, _ := event.GetValue("stats")
+	assert.Contains(t, stats, "search_restart")
+	assert.NotContains(t, stats, "entries")
+
+	for field, expected := range map[string]uint64{
+		"invalid":        15,
+		"ignore":         0x3ac3 + 0x2fcc,
+		"insert_failed":  1,
+		"drop":           2,
+		"search_restart": 16,
+	} {
+		v, err := event.GetValue("stats." + field)
+		if assert.NoError(t, err, field) {
+			assert.Equal(t, expected, v, field)
+		}
+	}
+}
+
+func TestFetchNotLoaded(t *testing.T) {
+	f := mbtest.NewEventFetcher(t, getConfig("./_meta/testdata/missing/proc"))
+	_, err := f.Fetch()
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "nf_conntrack module loaded")
+	}
+}
+
+func getConfig(procfs string) map[string]interface{} {
+	return map[string]interface{}{
+		"module":     "system",
+		"metricsets": []string{"conntrack"},
+		"procfs":     procfs,
+	}
+}
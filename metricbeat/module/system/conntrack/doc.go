@@ -0,0 +1,5 @@
+/*
+Package conntrack collects the usage and the statistics of the netfilter
+connection tracking table. It is implemented on linux.
+*/
+package conntrack
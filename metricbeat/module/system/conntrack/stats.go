@@ -0,0 +1,72 @@
+// +build linux
+
+package conntrack
+
+import (
+	"bufio"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/elastic/beats/libbeat/common"
+)
+
+// readUint reads a file containing a single number, like
+// /proc/sys/net/netfilter/nf_conntrack_count.
+func readUint(path string) (uint64, error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseUint(strings.TrimSpace(string(content)), 10, 64)
+}
+
+// readStats reads the per CPU statistics of /proc/net/stat/nf_conntrack and
+// returns their sum. The columns depend on the kernel version, they are
+// named after the header. The entries column is skipped, it is the same for
+// all the CPUs and it is already reported by nf_conntrack_count.
+func readStats(path string) (common.MapStr, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	if !scanner.Scan() {
+		if err := scanner.Err(); err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("empty file %s", path)
+	}
+	header := strings.Fields(scanner.Text())
+
+	sums := make([]uint64, len(header))
+	for scanner.Scan() {
+		values := strings.Fields(scanner.Text())
+		if len(values) != len(header) {
+			return nil, fmt.Errorf("expected %d values per CPU, got %d", len(header), len(values))
+		}
+		for i, value := range values {
+			v, err := strconv.ParseUint(value, 16, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid value '%s' for %s", value, header[i])
+			}
+			sums[i] += v
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	stats := common.MapStr{}
+	for i, name := range header {
+		if name == "entries" {
+			continue
+		}
+		stats[name] = sums[i]
+	}
+	return stats, nil
+}
@@ -0,0 +1,5 @@
+/*
+Package socket_summary collects the number of sockets per TCP state and
+protocol. It is implemented on linux.
+*/
+package socket_summary
@@ -0,0 +1,68 @@
+// +build linux
+
+package socket_summary
+
+import (
+	"github.com/pkg/errors"
+
+	"github.com/elastic/beats/libbeat/common"
+	"github.com/elastic/beats/libbeat/common/cfgwarn"
+	"github.com/elastic/beats/metricbeat/mb"
+	"github.com/elastic/beats/metricbeat/mb/parse"
+	"github.com/elastic/beats/metricbeat/module/system"
+)
+
+// init registers the MetricSet with the central registry.
+// The New method will be called after the setup of the module and before starting to fetch data
+func init() {
+	if err := mb.Registry.AddMetricSet("system", "socket_summary", New, parse.EmptyHostParser); err != nil {
+		panic(err)
+	}
+}
+
+// MetricSet type defines all fields of the MetricSet
+type MetricSet struct {
+	mb.BaseMetricSet
+	procfs string
+}
+
+// New create a new instance of the MetricSet
+func New(base mb.BaseMetricSet) (mb.MetricSet, error) {
+	cfgwarn.Experimental("The system socket_summary metricset is experimental")
+
+	systemModule, ok := base.Module().(*system.Module)
+	if !ok {
+		return nil, errors.New("unexpected module type")
+	}
+
+	return &MetricSet{
+		BaseMetricSet: base,
+		procfs:        systemModule.ProcFS,
+	}, nil
+}
+
+// Fetch reads the sockets tables of the host. It returns an event with the
+// summary of the sockets and an event per listening TCP port with the number
+// of connections established to it.
+func (m *MetricSet) Fetch() ([]common.MapStr, error) {
+	summary, err := readSummary(m.procfs)
+	if err != nil {
+		return nil, err
+	}
+
+	event := summary.toMapStr()
+	event[mb.NamespaceKey] = "socket.summary"
+	events := []common.MapStr{event}
+
+	for _, port := range summary.listeningPorts() {
+		events = append(events, common.MapStr{
+			"listening_port": common.MapStr{
+				"port":        port,
+				"connections": summary.connections[port],
+			},
+			mb.NamespaceKey: "socket.summary",
+		})
+	}
+
+	return events, nil
+}
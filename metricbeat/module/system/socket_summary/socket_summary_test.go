@@ -0,0 +1,81 @@
+// +build linux
+
+package socket_summary
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/elastic/beats/libbeat/common"
+	mbtest "github.com/elastic/beats/metricbeat/mb/testing"
+)
+
+func TestData(t *testing.T) {
+	f := mbtest.NewEventsFetcher(t, getConfig("./_meta/testdata/proc"))
+	if err := mbtest.WriteEvents(f, t); err != nil {
+		t.Fatal("write", err)
+	}
+}
+
+func TestFetch(t *testing.T) {
+	f := mbtest.NewEventsFetcher(t, getConfig("./_meta/testdata/proc"))
+	events, err := f.Fetch()
+	if !assert.NoError(t, err) {
+		return
+	}
+	if !assert.Len(t, events, 3) {
+		return
+	}
+
+	summary := events[0]
+	assertValue(t, summary, 9, "tcp.all.count")
+	assertValue(t, summary, 3, "tcp.all.listen")
+	assertValue(t, summary, 4, "tcp.all.established")
+	assertValue(t, summary, 1, "tcp.all.time_wait")
+	assertValue(t, summary, 1, "tcp.all.close_wait")
+	assertValue(t, summary, 0, "tcp.all.syn_sent")
+	assertValue(t, summary, 7, "tcp.ipv4.count")
+	assertValue(t, summary, 2, "tcp.ipv6.count")
+	assertValue(t, summary, 1, "tcp.ipv6.established")
+	assertValue(t, summary, 2, "udp.all.count")
+	assertValue(t, summary, 0, "udp.ipv6.count")
+
+	// Listening ports, the connections to port 443 are outgoing ones.
+	assert.Equal(t, common.MapStr{"port": uint16(22), "connections": 3}, events[1]["listening_port"])
+	assert.Equal(t, common.MapStr{"port": uint16(8080), "connections": 0}, events[2]["listening_port"])
+}
+
+func TestFetchMissingTables(t *testing.T) {
+	f := mbtest.NewEventsFetcher(t, getConfig("./_meta/testdata/missing/proc"))
+	_, err := f.Fetch()
+	assert.Error(t, err)
+}
+
+func TestLocalPort(t *testing.T) {
+	port, err := localPort("0100007F:1F90")
+	assert.NoError(t, err)
+	assert.Equal(t, uint16(8080), port)
+
+	port, err = localPort("00000000000000000000000000000000:0016")
+	assert.NoError(t, err)
+	assert.Equal(t, uint16(22), port)
+
+	_, err = localPort("0100007F")
+	assert.Error(t, err)
+}
+
+func assertValue(t *testing.T, event common.MapStr, expected interface{}, field string) {
+	v, err := event.GetValue(field)
+	if assert.NoError(t, err, field) {
+		assert.Equal(t, expected, v, field)
+	}
+}
+
+func getConfig(procfs string) map[string]interface{} {
+	return map[string]interface{}{
+		"module":     "system",
+		"metricsets": []string{"socket_summary"},
+		"procfs":     procfs,
+	}
+}
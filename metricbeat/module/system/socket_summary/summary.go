@@ -0,0 +1,191 @@
+// +build linux
+
+package socket_summary
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/elastic/beats/libbeat/common"
+)
+
+// tcpStates are the names of the TCP states by their value in the sockets
+// tables, see include/net/tcp_states.h in the Linux sources.
+var tcpStates = map[uint64]string{
+	0x01: "established",
+	0x02: "syn_sent",
+	0x03: "syn_recv",
+	0x04: "fin_wait1",
+	0x05: "fin_wait2",
+	0x06: "time_wait",
+	0x07: "close",
+	0x08: "close_wait",
+	0x09: "last_ack",
+	0x0A: "listen",
+	0x0B: "closing",
+}
+
+const (
+	tcpEstablished = 0x01
+	tcpListen      = 0x0A
+)
+
+// counts holds the number of sockets of a protocol and IP version.
+type counts struct {
+	total  int
+	states map[string]int
+}
+
+// summary holds the number of sockets per protocol and IP version, and the
+// number of established connections per listening TCP port.
+type summary struct {
+	tcp4, tcp6, udp4, udp6 counts
+
+	listening   map[uint16]bool
+	connections map[uint16]int
+}
+
+// readSummary reads the sockets tables under the given procfs root.
+func readSummary(procfs string) (*summary, error) {
+	s := &summary{
+		listening:   map[uint16]bool{},
+		connections: map[uint16]int{},
+	}
+
+	tables := []struct {
+		name   string
+		counts *counts
+		tcp    bool
+	}{
+		{"tcp", &s.tcp4, true},
+		{"tcp6", &s.tcp6, true},
+		{"udp", &s.udp4, false},
+		{"udp6", &s.udp6, false},
+	}
+
+	for _, table := range tables {
+		table.counts.states = map[string]int{}
+		path := filepath.Join(procfs, "net", table.name)
+		if err := s.readTable(path, table.counts, table.tcp); err != nil {
+			// IPv6 may be disabled, the IPv6 tables are missing then.
+			if os.IsNotExist(err) && strings.HasSuffix(table.name, "6") {
+				continue
+			}
+			return nil, errors.Wrapf(err, "failed reading %s", path)
+		}
+	}
+
+	// Only count the connections established to listening ports.
+	for port := range s.connections {
+		if !s.listening[port] {
+			delete(s.connections, port)
+		}
+	}
+
+	return s, nil
+}
+
+// readTable reads a sockets table like /proc/net/tcp.
+func (s *summary) readTable(path string, c *counts, tcp bool) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	// Skip header.
+	scanner.Scan()
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 4 {
+			continue
+		}
+
+		c.total++
+		if !tcp {
+			continue
+		}
+
+		state, err := strconv.ParseUint(fields[3], 16, 8)
+		if err != nil {
+			return fmt.Errorf("invalid socket state '%s'", fields[3])
+		}
+		if name, found := tcpStates[state]; found {
+			c.states[name]++
+		}
+
+		port, err := localPort(fields[1])
+		if err != nil {
+			return err
+		}
+		switch state {
+		case tcpListen:
+			s.listening[port] = true
+		case tcpEstablished:
+			s.connections[port]++
+		}
+	}
+	return scanner.Err()
+}
+
+// localPort returns the port of an address like 0100007F:0016.
+func localPort(address string) (uint16, error) {
+	i := strings.LastIndex(address, ":")
+	if i < 0 {
+		return 0, fmt.Errorf("invalid socket address '%s'", address)
+	}
+	port, err := strconv.ParseUint(address[i+1:], 16, 16)
+	if err != nil {
+		return 0, fmt.Errorf("invalid socket address '%s'", address)
+	}
+	return uint16(port), nil
+}
+
+// listeningPorts returns the sorted listening TCP ports.
+func (s *summary) listeningPorts() []uint16 {
+	ports := make([]uint16, 0, len(s.listening))
+	for port := range s.listening {
+		ports = append(ports, port)
+	}
+	sort.Slice(ports, func(i, j int) bool { return ports[i] < ports[j] })
+	return ports
+}
+
+func (s *summary) toMapStr() common.MapStr {
+	tcp := func(c counts) common.MapStr {
+		m := common.MapStr{"count": c.total}
+		for _, name := range tcpStates {
+			m[name] = c.states[name]
+		}
+		return m
+	}
+
+	all := counts{
+		total:  s.tcp4.total + s.tcp6.total,
+		states: map[string]int{},
+	}
+	for _, name := range tcpStates {
+		all.states[name] = s.tcp4.states[name] + s.tcp6.states[name]
+	}
+
+	return common.MapStr{
+		"tcp": common.MapStr{
+			"all":  tcp(all),
+			"ipv4": tcp(s.tcp4),
+			"ipv6": tcp(s.tcp6),
+		},
+		"udp": common.MapStr{
+			"all":  common.MapStr{"count": s.udp4.total + s.udp6.total},
+			"ipv4": common.MapStr{"count": s.udp4.total},
+			"ipv6": common.MapStr{"count": s.udp6.total},
+		},
+	}
+}
@@ -2,6 +2,7 @@ package system
 
 import (
 	"flag"
+	"path/filepath"
 	"sync"
 
 	"github.com/elastic/beats/metricbeat/mb"
@@ -23,6 +24,7 @@ func init() {
 type Module struct {
 	mb.BaseModule
 	HostFS string // Mountpoint of the host's filesystem for use in monitoring inside a container.
+	ProcFS string // Mountpoint of the proc filesystem read by the conntrack and socket_summary metricsets.
 }
 
 func NewModule(base mb.BaseModule) (mb.Module, error) {
@@ -31,5 +33,14 @@ func NewModule(base mb.BaseModule) (mb.Module, error) {
 		initModule()
 	})
 
-	return &Module{BaseModule: base, HostFS: *HostFS}, nil
+	config := struct {
+		ProcFS string `config:"procfs"`
+	}{
+		ProcFS: filepath.Join(*HostFS, "/proc"),
+	}
+	if err := base.UnpackConfig(&config); err != nil {
+		return nil, err
+	}
+
+	return &Module{BaseModule: base, HostFS: *HostFS, ProcFS: config.ProcFS}, nil
 }
@@ -0,0 +1,60 @@
+import os
+import metricbeat
+import unittest
+from nose.plugins.attrib import attr
+
+ETCD_FIELDS = metricbeat.COMMON_FIELDS + ["etcd"]
+
+
+class EtcdTest(metricbeat.BaseTest):
+
+    COMPOSE_SERVICES = ['etcd']
+
+    @unittest.skipUnless(metricbeat.INTEGRATION_TESTS, "integration test")
+    @attr('integration')
+    def test_self(self):
+        """
+        etcd self metricset outputs an event.
+        """
+        self.check_metricset("self")
+
+    @unittest.skipUnless(metricbeat.INTEGRATION_TESTS, "integration test")
+    @attr('integration')
+    def test_store(self):
+        """
+        etcd store metricset outputs an event.
+        """
+        self.check_metricset("store")
+
+    @unittest.skipUnless(metricbeat.INTEGRATION_TESTS, "integration test")
+    @attr('integration')
+    def test_metrics(self):
+        """
+        etcd metrics metricset outputs an event.
+        """
+        self.check_metricset("metrics")
+
+    def check_metricset(self, metricset):
+        self.render_config_template(modules=[{
+            "name": "etcd",
+            "metricsets": [metricset],
+            "hosts": self.get_hosts(),
+            "period": "5s"
+        }])
+        proc = self.start_beat()
+        self.wait_until(lambda: self.output_lines() > 0)
+        proc.check_kill_and_wait()
+        self.assert_no_logged_warnings()
+
+        output = self.read_output_json()
+        self.assertEqual(len(output), 1)
+        evt = output[0]
+
+        self.assertItemsEqual(self.de_dot(ETCD_FIELDS), evt.keys())
+        self.assertIn(metricset, evt["etcd"])
+
+        self.assert_fields_are_documented(evt)
+
+    def get_hosts(self):
+        return [os.getenv('ETCD_HOST', 'localhost') + ':' +
+                os.getenv('ETCD_PORT', '2379')]